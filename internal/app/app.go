@@ -1,7 +1,6 @@
 package app
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -10,6 +9,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -39,7 +39,7 @@ type App struct {
 
 	IsInteractiveTerminal func() bool
 	RunConfigWizard       ConfigWizardRunner
-	NewNotifySender       func(backend string) (notifySender, error)
+	NewNotifySender       func(backend string, cfg domain.NotifyConfig) (notifySender, error)
 
 	repoMetadataMu      sync.Mutex
 	observeRepoHook     func(cfg domain.ConfigFile, repo discoveredRepo, allowPush bool) (domain.MachineRepoRecord, error)
@@ -59,6 +59,8 @@ type InitOptions struct {
 type ScanOptions struct {
 	IncludeCatalogs []string
 	AllowPush       bool
+	// Full ignores the scan cache and observes every repo.
+	Full bool
 }
 
 type SyncOptions struct {
@@ -67,9 +69,24 @@ type SyncOptions struct {
 	Notify          bool
 	NotifyBackend   string
 	DryRun          bool
+	// JSON prints the dry-run plan as JSON instead of a table.
+	JSON bool
+}
+
+type StatusOptions struct {
+	IncludeCatalogs []string
+	JSON            bool
+	WithMetadata    bool
+	AllMachines     bool
+}
+
+type DoctorOptions struct {
+	IncludeCatalogs []string
+	JSON            bool
 }
 
 type SchedulerInstallOptions struct {
+	Backend       string
 	NotifyBackend string
 }
 
@@ -83,6 +100,8 @@ type FixOptions struct {
 	ReturnToOriginalBranchAndSync bool
 	SyncStrategy                  FixSyncStrategy
 	NoRefresh                     bool
+	All                           bool
+	DryRun                        bool
 }
 
 type CloneOptions struct {
@@ -104,6 +123,19 @@ type RepoMoveOptions struct {
 	NoHooks       bool
 }
 
+type RepoPrimaryOptions struct {
+	Selector string
+	// MachineID defaults to the current machine.
+	MachineID string
+	Clear     bool
+}
+
+type RepoBranchOptions struct {
+	Selector string
+	Pin      string
+	Follow   bool
+}
+
 type LinkOptions struct {
 	Selector string
 	As       string
@@ -116,6 +148,16 @@ type InfoOptions struct {
 	Selector string
 }
 
+type StateMigrateOptions struct {
+	DryRun bool
+}
+
+type StateRestoreOptions struct {
+	File string
+	// Backup is a backup ID or "latest"; empty lists the backups.
+	Backup string
+}
+
 type scanRefreshMode int
 
 const (
@@ -155,9 +197,10 @@ func New(paths state.Paths, stdout io.Writer, stderr io.Writer) *App {
 		IsInteractiveTerminal: defaultIsInteractiveTerminal,
 		RunConfigWizard:       runConfigWizardInteractive,
 	}
-	a.NewNotifySender = func(backend string) (notifySender, error) {
-		return newNotifySender(backend, a.Stdout, a.RunCommand)
+	a.NewNotifySender = func(backend string, cfg domain.NotifyConfig) (notifySender, error) {
+		return newNotifySender(backend, cfg, a.Stdout, a.RunCommand)
 	}
+	a.Paths.Now = func() time.Time { return a.Now() }
 	return a
 }
 
@@ -167,6 +210,9 @@ func (a *App) SetVerbose(verbose bool) {
 	a.Verbose = verbose
 }
 
+// logFunc receives a log line in the same form as App.logf.
+type logFunc func(format string, args ...any)
+
 func (a *App) logf(format string, args ...any) {
 	line := fmt.Sprintf(format, args...)
 
@@ -210,8 +256,8 @@ func (a *App) loadContext() (domain.ConfigFile, domain.MachineFile, error) {
 	if err != nil {
 		return domain.ConfigFile{}, domain.MachineFile{}, err
 	}
-	if cfg.StateTransport.Mode != "external" {
-		return domain.ConfigFile{}, domain.MachineFile{}, fmt.Errorf("unsupported state_transport.mode %q in v1", cfg.StateTransport.Mode)
+	if err := validateStateTransport(cfg.StateTransport); err != nil {
+		return domain.ConfigFile{}, domain.MachineFile{}, err
 	}
 
 	hostname, err := a.Hostname()
@@ -304,7 +350,7 @@ func (a *App) RunInit(opts InitOptions) error {
 		return errors.New("github.owner is required; run 'bb config' and set github.owner")
 	}
 
-	expectedOrigin, err := a.expectedOrigin(owner, projectName, remoteProtocol, cfg.GitHub.PreferredRemoteURLTemplate)
+	expectedOrigin, err := a.expectedOrigin(defaultForgeHost(cfg), owner, projectName, remoteProtocol, cfg.GitHub.PreferredRemoteURLTemplate)
 	if err != nil {
 		return err
 	}
@@ -325,6 +371,7 @@ func (a *App) RunInit(opts InitOptions) error {
 	} else {
 		a.logf("init: creating remote repository for %s/%s", owner, projectName)
 		createdOrigin, err := a.createRemoteRepo(
+			cfg,
 			owner,
 			projectName,
 			visibility,
@@ -342,7 +389,7 @@ func (a *App) RunInit(opts InitOptions) error {
 		origin = createdOrigin
 	}
 
-	repoMeta, created, err := a.ensureRepoMetadata(cfg, repoKey, projectName, origin, visibility, targetCatalog.Name)
+	repoMeta, created, err := a.ensureRepoMetadata(cfg, repoKey, projectName, origin, visibility, targetCatalog.Name, a.logf)
 	if err != nil {
 		return err
 	}
@@ -404,7 +451,7 @@ func (a *App) resolveInitTarget(machine domain.MachineFile, targetCatalog domain
 		}
 		repoKey, normalizedRelativePath, repoName, ok := domain.DeriveRepoKeyFromRelative(targetCatalog, project)
 		if !ok {
-			return "", "", "", fmt.Errorf("project path must match catalog layout depth %d", domain.EffectiveRepoPathDepth(targetCatalog))
+			return "", "", "", fmt.Errorf("project path must match catalog layout %s", domain.EffectiveCatalogLayout(targetCatalog))
 		}
 		return filepath.Join(targetCatalog.Root, filepath.FromSlash(normalizedRelativePath)), repoName, repoKey, nil
 	}
@@ -423,8 +470,16 @@ func (a *App) resolveInitTarget(machine domain.MachineFile, targetCatalog domain
 			continue
 		}
 		parts := splitPath(rel)
-		depth := domain.EffectiveRepoPathDepth(c)
-		if len(parts) < depth {
+		layout := domain.EffectiveCatalogLayout(c)
+		depth := min(len(parts), layout.MaxDepth)
+		// Within a depth range, an existing repo above cwd is the project.
+		for d := layout.MinDepth; d < depth; d++ {
+			if isGitDir(filepath.Join(append([]string{c.Root}, parts[:d]...)...)) {
+				depth = d
+				break
+			}
+		}
+		if depth < layout.MinDepth {
 			continue
 		}
 		relativePath := filepath.Join(parts[:depth]...)
@@ -471,11 +526,11 @@ func splitPath(p string) []string {
 	return out
 }
 
-func (a *App) expectedOrigin(owner, repo, protocol string, template string) (string, error) {
+func (a *App) expectedOrigin(host, owner, repo, protocol string, template string) (string, error) {
 	if fakeRoot := strings.TrimSpace(os.Getenv("BB_TEST_REMOTE_ROOT")); fakeRoot != "" {
 		return filepath.Join(fakeRoot, owner, repo+".git"), nil
 	}
-	return githubRemoteURL(owner, repo, protocol, template)
+	return forgeRemoteURL(host, owner, repo, protocol, template)
 }
 
 func originsMatchNormalized(observedOrigin string, expectedOrigin string) (bool, error) {
@@ -498,7 +553,9 @@ func (a *App) runCommandAttached(dir string, name string, args ...string) error
 	return run(dir, name, args...)
 }
 
-func (a *App) createRemoteRepo(owner, repo string, visibility domain.Visibility, protocol string, template string, repoPath string) (string, error) {
+// createRemoteRepo creates owner/repo on the default forge host and returns
+// its remote URL.
+func (a *App) createRemoteRepo(cfg domain.ConfigFile, owner, repo string, visibility domain.Visibility, protocol string, template string, repoPath string) (string, error) {
 	if fakeRoot := strings.TrimSpace(os.Getenv("BB_TEST_REMOTE_ROOT")); fakeRoot != "" {
 		remotePath := filepath.Join(fakeRoot, owner, repo+".git")
 		a.logf("init: using test remote backend at %s", remotePath)
@@ -512,53 +569,20 @@ func (a *App) createRemoteRepo(owner, repo string, visibility domain.Visibility,
 		}
 		return remotePath, nil
 	}
-	if err := a.ensureGitHubCLIReady(); err != nil {
-		return "", err
-	}
-
-	name := fmt.Sprintf("%s/%s", owner, repo)
-	visibilityFlag := "--private"
-	if visibility == domain.VisibilityPublic {
-		visibilityFlag = "--public"
-	}
-	args := []string{"repo", "create", name, visibilityFlag}
-	a.logf("init: running gh %s", strings.Join(args, " "))
-	if err := a.runCommandAttached(repoPath, "gh", args...); err != nil {
-		return "", fmt.Errorf("gh repo create failed: %w", err)
-	}
-
-	return githubRemoteURL(owner, repo, protocol, template)
-}
-
-func sourceRepoForFork(originURL string) (sourceOwner string, repoName string, err error) {
-	identity, err := domain.NormalizeOriginIdentity(originURL)
+	host := defaultForgeHost(cfg)
+	forge, err := a.forgeForHost(cfg, host)
 	if err != nil {
-		return "", "", fmt.Errorf("normalize origin: %w", err)
-	}
-
-	host, path, ok := strings.Cut(identity, "/")
-	if !ok || strings.TrimSpace(path) == "" {
-		return "", "", fmt.Errorf("invalid origin identity %q", identity)
-	}
-	host = strings.TrimSpace(host)
-	if strings.HasSuffix(host, ".github.com") {
-		host = "github.com"
-	}
-	if host != "github.com" {
-		return "", "", fmt.Errorf("unsupported origin host %q for fork", host)
-	}
-
-	segments := strings.Split(path, "/")
-	if len(segments) != 2 {
-		return "", "", fmt.Errorf("invalid github origin path %q", path)
+		return "", err
 	}
-	if strings.TrimSpace(segments[0]) == "" || strings.TrimSpace(segments[1]) == "" {
-		return "", "", fmt.Errorf("invalid github origin path %q", path)
+	if err := forge.CreateRepo(owner, repo, visibility, repoPath); err != nil {
+		return "", err
 	}
-	return strings.TrimSpace(segments[0]), strings.TrimSpace(segments[1]), nil
+	return forgeRemoteURL(host, owner, repo, protocol, template)
 }
 
-func (a *App) ensureForkRemoteRepo(originURL string, forkOwner string, protocol string, template string, repoPath string) (string, error) {
+// ensureForkRemoteRepo forks the origin's repository into forkOwner on the
+// origin's forge and returns the fork's remote URL.
+func (a *App) ensureForkRemoteRepo(cfg domain.ConfigFile, originURL string, forkOwner string, protocol string, repoPath string) (string, error) {
 	forkOwner = strings.TrimSpace(forkOwner)
 	if forkOwner == "" {
 		return "", errors.New("github.owner is required for fork")
@@ -585,31 +609,21 @@ func (a *App) ensureForkRemoteRepo(originURL string, forkOwner string, protocol
 		}
 		return remotePath, nil
 	}
-	if err := a.ensureGitHubCLIReady(); err != nil {
-		return "", err
+	host, sourceOwner, repoName, ok := forgeRepoForOrigin(originURL)
+	if !ok {
+		return "", fmt.Errorf("cannot derive source repository from origin %q", originURL)
 	}
-
-	sourceOwner, repoName, err := sourceRepoForFork(originURL)
+	forge, err := a.forgeForHost(cfg, host)
 	if err != nil {
 		return "", err
 	}
-	source := fmt.Sprintf("%s/%s", sourceOwner, repoName)
-	args := []string{"repo", "fork", source, "--remote=false", "--clone=false"}
-	a.logf("fork: running gh %s", strings.Join(args, " "))
-	cmd := exec.Command("gh", args...)
-	cmd.Dir = repoPath
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		lower := strings.ToLower(string(out))
-		if !strings.Contains(lower, "already exists") {
-			return "", fmt.Errorf("gh repo fork failed: %w: %s", err, string(out))
-		}
+	if err := forge.Fork(sourceOwner, repoName, forkOwner, repoPath); err != nil {
+		return "", err
 	}
-
-	return githubRemoteURL(forkOwner, repoName, protocol, template)
+	return forgeRemoteURL(host, forkOwner, repoName, protocol, remoteURLTemplateForHost(cfg, host))
 }
 
-func (a *App) ensureRepoMetadata(cfg domain.ConfigFile, repoKey, name, origin string, visibility domain.Visibility, preferredCatalog string) (domain.RepoMetadataFile, bool, error) {
+func (a *App) ensureRepoMetadata(cfg domain.ConfigFile, repoKey, name, origin string, visibility domain.Visibility, preferredCatalog string, logf logFunc) (domain.RepoMetadataFile, bool, error) {
 	meta, err := state.LoadRepoMetadata(a.Paths, repoKey)
 	created := false
 	loaded := domain.RepoMetadataFile{}
@@ -669,7 +683,7 @@ func (a *App) ensureRepoMetadata(cfg domain.ConfigFile, repoKey, name, origin st
 	if err := state.SaveRepoMetadata(a.Paths, normalized); err != nil {
 		return domain.RepoMetadataFile{}, false, err
 	}
-	a.logf("state: wrote repo metadata %s", state.RepoMetaPath(a.Paths, repoKey))
+	logf("state: wrote repo metadata %s", state.RepoMetaPath(a.Paths, repoKey))
 	return normalized, created, nil
 }
 
@@ -710,7 +724,7 @@ func normalizePreviousRepoKeys(currentRepoKey string, keys []string) []string {
 	return out
 }
 
-func (a *App) loadRepoMetadataWithPushAccess(repoPath string, repoKey string, originURL string, shouldProbe bool) (domain.RepoMetadataFile, bool, error) {
+func (a *App) loadRepoMetadataWithPushAccess(cfg domain.ConfigFile, repoPath string, repoKey string, originURL string, shouldProbe bool, logf logFunc) (domain.RepoMetadataFile, bool, error) {
 	if strings.TrimSpace(repoKey) == "" {
 		return domain.RepoMetadataFile{}, false, nil
 	}
@@ -733,7 +747,7 @@ func (a *App) loadRepoMetadataWithPushAccess(repoPath string, repoKey string, or
 	shouldProbeUnknown := domain.NormalizePushAccess(normalized.PushAccess) == domain.PushAccessUnknown
 	if shouldProbe || shouldProbeUnknown {
 		var probeChanged bool
-		updated, probeChanged, err = a.probeAndUpdateRepoPushAccess(repoPath, originURL, normalized, false)
+		updated, probeChanged, err = a.probeAndUpdateRepoPushAccess(cfg, repoPath, originURL, normalized, false, logf)
 		if err != nil {
 			return domain.RepoMetadataFile{}, false, err
 		}
@@ -747,7 +761,7 @@ func (a *App) loadRepoMetadataWithPushAccess(repoPath string, repoKey string, or
 	return updated, true, nil
 }
 
-func (a *App) probeAndUpdateRepoPushAccess(repoPath string, originURL string, meta domain.RepoMetadataFile, force bool) (domain.RepoMetadataFile, bool, error) {
+func (a *App) probeAndUpdateRepoPushAccess(cfg domain.ConfigFile, repoPath string, originURL string, meta domain.RepoMetadataFile, force bool, logf logFunc) (domain.RepoMetadataFile, bool, error) {
 	original := meta
 	meta = normalizedRepoMetadata(meta)
 
@@ -777,7 +791,7 @@ func (a *App) probeAndUpdateRepoPushAccess(repoPath string, originURL string, me
 		return meta, !repoMetadataEqual(meta, original), nil
 	}
 
-	if !shouldProbePushAccessForOrigin(originURL) {
+	if !shouldProbePushAccessForOrigin(cfg, originURL) {
 		meta.PushAccess = domain.PushAccessUnknown
 		meta.PushAccessCheckedRemote = strings.TrimSpace(remote)
 		meta.PushAccessCheckedAt = a.Now()
@@ -787,7 +801,7 @@ func (a *App) probeAndUpdateRepoPushAccess(repoPath string, originURL string, me
 		return meta, !repoMetadataEqual(meta, original), nil
 	}
 
-	if access, ok := a.probePushAccessViaGitHubCLI(originURL); ok {
+	if access, ok := a.probePushAccessViaForge(cfg, originURL); ok {
 		meta.PushAccess = domain.NormalizePushAccess(access)
 		meta.PushAccessCheckedRemote = strings.TrimSpace(remote)
 		meta.PushAccessCheckedAt = a.Now()
@@ -800,7 +814,7 @@ func (a *App) probeAndUpdateRepoPushAccess(repoPath string, originURL string, me
 	pushAccess, probedRemote, probeErr := a.Git.ProbePushAccess(repoPath, meta.PreferredRemote)
 	if probeErr != nil {
 		if a.isVerbose() {
-			a.logf("scan: push-access probe failed for %s: %v", repoPath, probeErr)
+			logf("scan: push-access probe failed for %s: %v", repoPath, probeErr)
 		}
 		pushAccess = domain.PushAccessUnknown
 		if strings.TrimSpace(probedRemote) == "" {
@@ -820,65 +834,53 @@ func (a *App) probeAndUpdateRepoPushAccess(repoPath string, originURL string, me
 	return meta, !repoMetadataEqual(meta, original), nil
 }
 
-func (a *App) probePushAccessViaGitHubCLI(originURL string) (domain.PushAccess, bool) {
-	owner, repo, ok := githubSourceRepoForOrigin(originURL)
+// probePushAccessViaForge asks the origin's forge API for push access. ok is
+// false when the forge cannot answer, so callers fall back to a git probe.
+func (a *App) probePushAccessViaForge(cfg domain.ConfigFile, originURL string) (domain.PushAccess, bool) {
+	host, owner, repo, ok := forgeRepoForOrigin(originURL)
 	if !ok {
 		return domain.PushAccessUnknown, false
 	}
-
-	lookPath := a.LookPath
-	if lookPath == nil {
-		lookPath = exec.LookPath
-	}
-	if _, err := lookPath("gh"); err != nil {
-		return domain.PushAccessUnknown, false
-	}
-
-	runCommand := a.RunCommand
-	if runCommand == nil {
-		runCommand = defaultRunCommand
-	}
-	out, err := runCommand("gh", "repo", "view", fmt.Sprintf("%s/%s", owner, repo), "--json", "viewerPermission")
+	forge, err := a.forgeForHost(cfg, host)
 	if err != nil {
-		if a.isVerbose() {
-			a.logf("scan: github push-access probe failed for %s/%s: %v", owner, repo, err)
-		}
 		return domain.PushAccessUnknown, false
 	}
-
-	access, ok := parseGitHubViewerPermissionPushAccess(out)
-	if !ok {
+	access, err := forge.PushAccess(owner, repo)
+	if err != nil {
 		if a.isVerbose() {
-			a.logf("scan: github push-access probe returned unknown viewer permission for %s/%s", owner, repo)
+			a.logf("scan: %s push-access probe failed for %s/%s: %v", forge.Kind(), owner, repo, err)
 		}
 		return domain.PushAccessUnknown, false
 	}
 	return access, true
 }
 
-func parseGitHubViewerPermissionPushAccess(raw string) (domain.PushAccess, bool) {
-	payload := struct {
-		ViewerPermission string `json:"viewerPermission"`
-	}{}
-	if err := json.Unmarshal([]byte(raw), &payload); err != nil {
-		return domain.PushAccessUnknown, false
+// lookupVisibilityViaForge asks the origin's forge whether the repository is
+// public or private. ok is false when the forge cannot answer.
+func (a *App) lookupVisibilityViaForge(cfg domain.ConfigFile, originURL string) (domain.Visibility, bool) {
+	host, owner, repo, ok := forgeRepoForOrigin(originURL)
+	if !ok {
+		return domain.VisibilityUnknown, false
 	}
-
-	switch strings.ToUpper(strings.TrimSpace(payload.ViewerPermission)) {
-	case "ADMIN", "MAINTAIN", "WRITE":
-		return domain.PushAccessReadWrite, true
-	case "TRIAGE", "READ", "NONE":
-		return domain.PushAccessReadOnly, true
-	default:
-		return domain.PushAccessUnknown, false
+	forge, err := a.forgeForHost(cfg, host)
+	if err != nil {
+		return domain.VisibilityUnknown, false
+	}
+	visibility, err := forge.Visibility(owner, repo)
+	if err != nil || visibility == domain.VisibilityUnknown {
+		if err != nil && a.isVerbose() {
+			a.logf("repo access refresh: %s visibility lookup failed for %s/%s: %v", forge.Kind(), owner, repo, err)
+		}
+		return domain.VisibilityUnknown, false
 	}
+	return visibility, true
 }
 
 func pushAccessAllowsAutoPush(access domain.PushAccess) bool {
 	return domain.NormalizePushAccess(access) != domain.PushAccessReadOnly
 }
 
-func shouldProbePushAccessForOrigin(originURL string) bool {
+func shouldProbePushAccessForOrigin(cfg domain.ConfigFile, originURL string) bool {
 	originURL = strings.TrimSpace(originURL)
 	if originURL == "" {
 		return false
@@ -895,13 +897,17 @@ func shouldProbePushAccessForOrigin(originURL string) bool {
 	if host == "file" {
 		return true
 	}
-	_, _, ok = githubSourceRepoForOrigin(originURL)
+	host, _, _, ok = forgeRepoForOrigin(originURL)
+	if !ok {
+		return false
+	}
+	_, ok = forgeConfigForHost(cfg, host)
 	return ok
 }
 
 func githubSourceRepoForOrigin(originURL string) (owner string, repo string, ok bool) {
-	owner, repo, err := sourceRepoForFork(originURL)
-	if err != nil {
+	host, owner, repo, ok := forgeRepoForOrigin(originURL)
+	if !ok || host != githubHost {
 		return "", "", false
 	}
 	return owner, repo, true
@@ -933,12 +939,20 @@ func (a *App) scanAndPublish(cfg domain.ConfigFile, machine *domain.MachineFile,
 		prev[repoRecordIdentityKey(rec)] = rec
 	}
 
+	cache := state.LoadScanCache(a.Paths)
+	if opts.Full || cache.Context != a.scanCacheContext(cfg, opts.AllowPush) {
+		cache.Repos = map[string]domain.ScanCacheEntry{}
+	}
+
 	type observedResult struct {
-		Index  int
-		Record domain.MachineRepoRecord
-		Err    error
+		Index       int
+		Record      domain.MachineRepoRecord
+		Fingerprint string
+		Cached      bool
+		Err         error
 	}
 
+	observationTime := a.Now()
 	records := make([]domain.MachineRepoRecord, len(discovered))
 	unsyncable := false
 	workerCount := scanWorkerCount(len(discovered))
@@ -948,6 +962,10 @@ func (a *App) scanAndPublish(cfg domain.ConfigFile, machine *domain.MachineFile,
 		go func() {
 			for idx := range jobs {
 				repo := discovered[idx]
+				if rec, ok := cachedScanRecord(cache, prev, repo, gitx.Fingerprint(repo.Path), observationTime); ok {
+					results <- observedResult{Index: idx, Record: rec, Cached: true}
+					continue
+				}
 				a.logf("scan: observing repo at %s", repo.Path)
 				rec, observeErr := a.observeRepoForScan(cfg, repo, opts.AllowPush)
 				if observeErr != nil {
@@ -958,8 +976,9 @@ func (a *App) scanAndPublish(cfg domain.ConfigFile, machine *domain.MachineFile,
 					continue
 				}
 				results <- observedResult{
-					Index:  idx,
-					Record: rec,
+					Index:       idx,
+					Record:      rec,
+					Fingerprint: gitx.Fingerprint(repo.Path),
 				}
 			}
 		}()
@@ -971,7 +990,9 @@ func (a *App) scanAndPublish(cfg domain.ConfigFile, machine *domain.MachineFile,
 		close(jobs)
 	}()
 
-	observationTime := a.Now()
+	nextCache := domain.ScanCacheFile{Repos: map[string]domain.ScanCacheEntry{}}
+	transitionedToSyncable := map[string]bool{}
+	cachedCount := 0
 	var firstErr error
 	for i := 0; i < len(discovered); i++ {
 		result := <-results
@@ -981,7 +1002,18 @@ func (a *App) scanAndPublish(cfg domain.ConfigFile, machine *domain.MachineFile,
 			}
 			continue
 		}
-		old := prev[repoRecordIdentityKey(result.Record)]
+		path := discovered[result.Index].Path
+		if result.Cached {
+			cachedCount++
+			nextCache.Repos[path] = cache.Repos[path]
+		} else if result.Fingerprint != "" {
+			nextCache.Repos[path] = domain.ScanCacheEntry{Fingerprint: result.Fingerprint, ObservedAt: observationTime}
+		}
+		key := repoRecordIdentityKey(result.Record)
+		old, hadRecord := prev[key]
+		if hadRecord && !old.Syncable && result.Record.Syncable {
+			transitionedToSyncable[key] = true
+		}
 		result.Record = domain.UpdateObservedAt(old, result.Record, observationTime)
 		if !result.Record.Syncable {
 			unsyncable = true
@@ -991,6 +1023,9 @@ func (a *App) scanAndPublish(cfg domain.ConfigFile, machine *domain.MachineFile,
 	if firstErr != nil {
 		return false, firstErr
 	}
+	if cachedCount > 0 {
+		a.logf("scan: %d repo(s) unchanged since last scan, reused cached observations", cachedCount)
+	}
 
 	sort.Slice(records, func(i, j int) bool {
 		if repoRecordSortKey(records[i]) == repoRecordSortKey(records[j]) {
@@ -1008,6 +1043,12 @@ func (a *App) scanAndPublish(cfg domain.ConfigFile, machine *domain.MachineFile,
 		return false, err
 	}
 	a.logf("state: wrote machine file %s with %d repo record(s)", a.Paths.MachinePath(machine.MachineID), len(machine.Repos))
+	a.pruneNotifyCacheForRecovered(records, transitionedToSyncable)
+
+	nextCache.Context = a.scanCacheContext(cfg, opts.AllowPush)
+	if err := state.SaveScanCache(a.Paths, nextCache); err != nil {
+		a.logf("warning: failed to write scan cache: %v", err)
+	}
 	return unsyncable, nil
 }
 
@@ -1065,8 +1106,20 @@ func catalogNames(catalogs []domain.Catalog) []string {
 	return out
 }
 
+// discoverRepos walks the catalogs for repositories. A linked worktree is
+// left to the repository that owns it when that main worktree is itself in
+// one of the catalogs. Worktrees of bare repositories and of repositories
+// outside the catalogs are published instead, once per shared git directory,
+// so they do not drop out of view; the other worktrees of the same repository
+// are observed with the published one.
 func discoverRepos(catalogs []domain.Catalog) ([]discoveredRepo, error) {
 	out := []discoveredRepo{}
+	type linkedWorktree struct {
+		repo      discoveredRepo
+		main      string
+		commonDir string
+	}
+	linked := []linkedWorktree{}
 	for _, c := range catalogs {
 		if strings.TrimSpace(c.Root) == "" {
 			continue
@@ -1074,6 +1127,8 @@ func discoverRepos(catalogs []domain.Catalog) ([]discoveredRepo, error) {
 		if _, err := os.Stat(c.Root); errors.Is(err, os.ErrNotExist) {
 			continue
 		}
+		excludes := domain.CatalogExcludeRules(c)
+		maxDepth := domain.EffectiveCatalogLayout(c).MaxDepth
 		err := filepath.WalkDir(c.Root, func(path string, d os.DirEntry, err error) error {
 			if err != nil {
 				return err
@@ -1084,24 +1139,57 @@ func discoverRepos(catalogs []domain.Catalog) ([]discoveredRepo, error) {
 			if d.Name() == ".git" {
 				return filepath.SkipDir
 			}
+			relativePath, err := filepath.Rel(c.Root, path)
+			if err != nil {
+				return err
+			}
+			relativePath = filepath.ToSlash(relativePath)
+			if relativePath != "." && excludes.Matches(relativePath) {
+				return filepath.SkipDir
+			}
 			if isGitDir(path) {
 				repoKey, _, name, ok := domain.DeriveRepoKey(c, path)
-				if ok {
-					out = append(out, discoveredRepo{
-						Catalog: c,
-						Path:    path,
-						Name:    name,
-						RepoKey: repoKey,
-					})
+				if !ok {
+					return filepath.SkipDir
+				}
+				repo := discoveredRepo{
+					Catalog: c,
+					Path:    path,
+					Name:    name,
+					RepoKey: repoKey,
+				}
+				if main, isLinked := gitx.MainWorktree(path); isLinked {
+					linked = append(linked, linkedWorktree{repo: repo, main: main, commonDir: gitx.CommonGitDir(path)})
+				} else {
+					out = append(out, repo)
 				}
 				return filepath.SkipDir
 			}
+			if relativePath != "." && strings.Count(relativePath, "/")+1 >= maxDepth {
+				// No repo of the layout lives deeper.
+				return filepath.SkipDir
+			}
+			addIgnoreFileRules(&excludes, c.Root, relativePath)
 			return nil
 		})
 		if err != nil {
 			return nil, err
 		}
 	}
+	published := map[string]bool{}
+	for _, repo := range out {
+		published[filepath.Clean(repo.Path)] = true
+	}
+	for _, worktree := range linked {
+		if worktree.main != "" && published[filepath.Clean(worktree.main)] {
+			continue
+		}
+		if published[worktree.commonDir] {
+			continue
+		}
+		published[worktree.commonDir] = true
+		out = append(out, worktree.repo)
+	}
 	return out, nil
 }
 
@@ -1111,6 +1199,12 @@ func isGitDir(path string) bool {
 }
 
 func (a *App) observeRepo(cfg domain.ConfigFile, repo discoveredRepo, allowPush bool) (domain.MachineRepoRecord, error) {
+	return a.observeRepoLogged(cfg, repo, allowPush, a.logf)
+}
+
+// observeRepoLogged is observeRepo with its log lines sent to logf, so callers
+// running observations concurrently can keep their logs in order.
+func (a *App) observeRepoLogged(cfg domain.ConfigFile, repo discoveredRepo, allowPush bool, logf logFunc) (domain.MachineRepoRecord, error) {
 	origin, err := a.Git.RepoOrigin(repo.Path)
 	if err != nil {
 		return domain.MachineRepoRecord{}, err
@@ -1130,27 +1224,25 @@ func (a *App) observeRepo(cfg domain.ConfigFile, repo discoveredRepo, allowPush
 	}
 	if origin != "" && repoKey != "" && movedToRepoKey == "" {
 		a.repoMetadataMu.Lock()
-		_, _, err := a.ensureRepoMetadata(cfg, repoKey, repo.Name, origin, domain.VisibilityUnknown, repo.Catalog.Name)
+		_, _, err := a.ensureRepoMetadata(cfg, repoKey, repo.Name, origin, domain.VisibilityUnknown, repo.Catalog.Name, logf)
 		a.repoMetadataMu.Unlock()
 		if err != nil {
 			return domain.MachineRepoRecord{}, err
 		}
 	}
 
-	branch, _ := a.Git.CurrentBranch(repo.Path)
-	head, _ := a.Git.HeadSHA(repo.Path)
-	upstream, _ := a.Git.Upstream(repo.Path)
-	remoteHead, _ := a.Git.RemoteHeadSHA(repo.Path)
-	ahead, behind, diverged, _ := a.Git.AheadBehind(repo.Path)
-	dirtyTracked, dirtyUntracked, _ := a.Git.Dirty(repo.Path)
-	op := a.Git.Operation(repo.Path)
+	obs, _ := a.Git.Observe(repo.Path)
+	branch, head, upstream, remoteHead := obs.Branch, obs.HeadSHA, obs.Upstream, obs.RemoteHeadSHA
+	ahead, behind, diverged := obs.Ahead, obs.Behind, obs.Diverged
+	dirtyTracked, dirtyUntracked := obs.DirtyTracked, obs.DirtyUntracked
+	op := obs.Operation
 
 	autoPush := domain.AutoPushModeDisabled
 	preferredRemote := ""
 	pushAccess := domain.PushAccessUnknown
 	if lookupRepoKey != "" {
 		shouldProbePushAccess := ahead > 0
-		if meta, hasMeta, err := a.loadRepoMetadataWithPushAccess(repo.Path, lookupRepoKey, origin, shouldProbePushAccess); err != nil {
+		if meta, hasMeta, err := a.loadRepoMetadataWithPushAccess(cfg, repo.Path, lookupRepoKey, origin, shouldProbePushAccess, logf); err != nil {
 			return domain.MachineRepoRecord{}, err
 		} else if hasMeta {
 			autoPush = meta.AutoPush
@@ -1161,7 +1253,7 @@ func (a *App) observeRepo(cfg domain.ConfigFile, repo discoveredRepo, allowPush
 	if !pushAccessAllowsAutoPush(pushAccess) {
 		autoPush = domain.AutoPushModeDisabled
 	}
-	defaultBranch, _ := a.Git.DefaultBranch(repo.Path, preferredRemote)
+	defaultBranch := obs.DefaultBranch(preferredRemote)
 	autoPushAllowed := effectiveAutoPushForObservedBranch(autoPush, branch, defaultBranch)
 
 	syncable, reasons := domain.EvaluateSyncability(domain.ObservedRepoState{
@@ -1198,6 +1290,7 @@ func (a *App) observeRepo(cfg domain.ConfigFile, repo discoveredRepo, allowPush
 		OperationInProgress: op,
 		Syncable:            syncable,
 		UnsyncableReasons:   reasons,
+		Worktrees:           a.observeWorktrees(repo.Path, logf),
 	}
 	if expectedOriginURL, isGitHubOrigin, expectedErr := preferredGitHubRemoteURLForOrigin(cfg.GitHub, origin); expectedErr != nil {
 		return domain.MachineRepoRecord{}, expectedErr
@@ -1214,10 +1307,35 @@ func (a *App) observeRepo(cfg domain.ConfigFile, repo discoveredRepo, allowPush
 		}
 	}
 	rec.StateHash = domain.ComputeStateHash(rec)
-	a.logf("scan: repo=%s branch=%s syncable=%t ahead=%d behind=%d", repo.Path, rec.Branch, rec.Syncable, rec.Ahead, rec.Behind)
+	logf("scan: repo=%s branch=%s syncable=%t ahead=%d behind=%d", repo.Path, rec.Branch, rec.Syncable, rec.Ahead, rec.Behind)
 	return rec, nil
 }
 
+// observeWorktrees reads the branch and dirtiness of every linked worktree of
+// the repository at path. A worktree whose status cannot be read is still
+// recorded, with what its git directory alone tells.
+func (a *App) observeWorktrees(path string, logf logFunc) []domain.WorktreeRecord {
+	var out []domain.WorktreeRecord
+	for _, worktree := range gitx.LinkedWorktrees(path) {
+		if filepath.Clean(worktree) == filepath.Clean(path) {
+			// A worktree published in place of a bare or uncataloged
+			// repository does not list itself.
+			continue
+		}
+		obs, _ := a.Git.Observe(worktree)
+		out = append(out, domain.WorktreeRecord{
+			Path:                worktree,
+			Branch:              obs.Branch,
+			HeadSHA:             obs.HeadSHA,
+			HasDirtyTracked:     obs.DirtyTracked,
+			HasUntracked:        obs.DirtyUntracked,
+			OperationInProgress: obs.Operation,
+		})
+		logf("scan: worktree=%s repo=%s branch=%s dirty_tracked=%t untracked=%t", worktree, path, obs.Branch, obs.DirtyTracked, obs.DirtyUntracked)
+	}
+	return out
+}
+
 func (a *App) resolveMovedRepoKey(repoKey string) (string, bool, error) {
 	repoKey = strings.TrimSpace(repoKey)
 	if repoKey == "" {
@@ -1269,10 +1387,16 @@ func scanFreshnessWindow(cfg domain.ConfigFile) time.Duration {
 	return time.Duration(cfg.Sync.ScanFreshnessSeconds) * time.Second
 }
 
-func shouldRefreshScanSnapshot(machine domain.MachineFile, selected []domain.Catalog, now time.Time, window time.Duration) bool {
+// shouldRefreshScanSnapshot reports whether the machine snapshot is too old or
+// does not cover the selected catalogs, or whether the scan cache saw a repo
+// move on disk since the snapshot was taken.
+func shouldRefreshScanSnapshot(machine domain.MachineFile, selected []domain.Catalog, now time.Time, window time.Duration, reposChanged bool) bool {
 	if window <= 0 {
 		return true
 	}
+	if reposChanged {
+		return true
+	}
 	if machine.LastScanAt.IsZero() {
 		return true
 	}
@@ -1323,11 +1447,16 @@ func (a *App) refreshMachineSnapshotLocked(cfg domain.ConfigFile, machine *domai
 		return err
 	}
 	window := scanFreshnessWindow(cfg)
-	if !shouldRefreshScanSnapshot(*machine, selected, a.Now(), window) {
+	reposChanged := window > 0 && a.scanCacheReportsChanges(cfg, *machine, selected)
+	if !shouldRefreshScanSnapshot(*machine, selected, a.Now(), window, reposChanged) {
 		a.logf("scan: snapshot is fresh (<= %s), skipping refresh", window)
 		return nil
 	}
-	a.logf("scan: snapshot is stale, refreshing")
+	if reposChanged {
+		a.logf("scan: repos changed on disk since last scan, refreshing")
+	} else {
+		a.logf("scan: snapshot is stale, refreshing")
+	}
 	_, err = a.scanAndPublish(cfg, machine, ScanOptions{IncludeCatalogs: includeCatalogs, AllowPush: false})
 	return err
 }
@@ -1360,14 +1489,17 @@ func (a *App) RunScan(opts ScanOptions) (int, error) {
 	return 0, nil
 }
 
-func (a *App) RunStatus(jsonOut bool, include []string) (int, error) {
+func (a *App) RunStatus(opts StatusOptions) (int, error) {
 	a.logf("status: loading state")
 	_, machine, err := a.loadContext()
 	if err != nil {
 		return 2, err
 	}
+	if opts.AllMachines {
+		return a.runStatusAllMachines(machine, opts)
+	}
 
-	selected, err := domain.SelectCatalogs(machine, include)
+	selected, err := domain.SelectCatalogs(machine, opts.IncludeCatalogs)
 	if err != nil {
 		return 2, err
 	}
@@ -1375,39 +1507,29 @@ func (a *App) RunStatus(jsonOut bool, include []string) (int, error) {
 	for _, c := range selected {
 		allowed[c.Name] = struct{}{}
 	}
+	repos := make([]domain.MachineRepoRecord, 0, len(machine.Repos))
+	for _, r := range machine.Repos {
+		if _, ok := allowed[r.Catalog]; ok {
+			repos = append(repos, r)
+		}
+	}
 
-	if jsonOut {
-		fmt.Fprintln(a.Stdout, "{")
-		fmt.Fprintf(a.Stdout, "  \"machine_id\": %q,\n", machine.MachineID)
-		fmt.Fprintln(a.Stdout, "  \"repos\": [")
-		first := true
-		for _, r := range machine.Repos {
-			if _, ok := allowed[r.Catalog]; !ok {
-				continue
-			}
-			if !first {
-				fmt.Fprintln(a.Stdout, ",")
-			}
-			first = false
-			fmt.Fprintf(a.Stdout, "    {\"repo_key\":%q,\"catalog\":%q,\"path\":%q,\"branch\":%q,\"syncable\":%t}", r.RepoKey, r.Catalog, r.Path, r.Branch, r.Syncable)
+	if opts.JSON {
+		if err := a.writeStatusJSON(machine, repos, opts.WithMetadata); err != nil {
+			return 2, err
 		}
-		fmt.Fprintln(a.Stdout)
-		fmt.Fprintln(a.Stdout, "  ]")
-		fmt.Fprintln(a.Stdout, "}")
 		return 0, nil
 	}
 
-	for _, r := range machine.Repos {
-		if _, ok := allowed[r.Catalog]; !ok {
-			continue
-		}
+	for _, r := range repos {
 		fmt.Fprintf(a.Stdout, "%s %s %s syncable=%t\n", r.Name, r.Branch, r.Path, r.Syncable)
 	}
-	a.logf("status: reported %d repo(s)", len(machine.Repos))
+	a.logf("status: reported %d repo(s)", len(repos))
 	return 0, nil
 }
 
-func (a *App) RunDoctor(include []string) (int, error) {
+func (a *App) RunDoctor(opts DoctorOptions) (int, error) {
+	include := opts.IncludeCatalogs
 	a.logf("doctor: acquiring global lock")
 	lock, err := state.AcquireLock(a.Paths)
 	if err != nil {
@@ -1418,6 +1540,9 @@ func (a *App) RunDoctor(include []string) (int, error) {
 		a.logf("doctor: released global lock")
 	}()
 
+	if err := a.resolveStateConflictCopiesLocked(); err != nil {
+		return 2, err
+	}
 	a.logf("doctor: loading state")
 	cfg, machine, err := a.loadContext()
 	if err != nil {
@@ -1434,6 +1559,9 @@ func (a *App) RunDoctor(include []string) (int, error) {
 	for _, c := range selected {
 		allowed[c.Name] = struct{}{}
 	}
+	if opts.JSON {
+		return a.runDoctorJSON(cfg, machine, allowed)
+	}
 	unsyncable := false
 	for _, r := range machine.Repos {
 		if _, ok := allowed[r.Catalog]; !ok {
@@ -1445,11 +1573,24 @@ func (a *App) RunDoctor(include []string) (int, error) {
 		}
 	}
 
+	if err := a.reportStateConflictResolutions(); err != nil {
+		return 2, err
+	}
 	warningCount, err := a.reportNotifyDeliveryFailures()
 	if err != nil {
 		return 2, err
 	}
+	fallbackCount, err := a.reportStateBackupFallbacks()
+	if err != nil {
+		return 2, err
+	}
+	warningCount += fallbackCount
 	warningCount += a.reportGitHubCLIWarnings(cfg, machine.Repos, allowed)
+	excludedCount, err := a.reportExcludedTrackedRepos(machine, allowed)
+	if err != nil {
+		return 2, err
+	}
+	warningCount += excludedCount
 	if warningCount > 0 {
 		a.logf("doctor: found %d warning(s)", warningCount)
 	}
@@ -1461,7 +1602,7 @@ func (a *App) RunDoctor(include []string) (int, error) {
 	return 0, nil
 }
 
-func (a *App) RunCatalogAdd(name, root string) (int, error) {
+func (a *App) RunCatalogAdd(name, root, layout string) (int, error) {
 	a.logf("catalog add: acquiring global lock")
 	lock, err := state.AcquireLock(a.Paths)
 	if err != nil {
@@ -1481,7 +1622,11 @@ func (a *App) RunCatalogAdd(name, root string) (int, error) {
 			return 2, fmt.Errorf("catalog %q already exists", name)
 		}
 	}
-	machine.Catalogs = append(machine.Catalogs, domain.Catalog{Name: name, Root: root, RepoPathDepth: domain.DefaultRepoPathDepth})
+	catalog := domain.Catalog{Name: name, Root: root, RepoPathDepth: domain.DefaultRepoPathDepth, Layout: strings.TrimSpace(layout)}
+	if err := domain.ValidateCatalogLayout(catalog); err != nil {
+		return 2, fmt.Errorf("catalog %q %w", name, err)
+	}
+	machine.Catalogs = append(machine.Catalogs, catalog)
 	if machine.DefaultCatalog == "" {
 		machine.DefaultCatalog = name
 	}
@@ -1575,6 +1720,9 @@ func (a *App) RunCatalogList() (int, error) {
 		if c.Name == machine.DefaultCatalog {
 			mark = " (default)"
 		}
+		if strings.TrimSpace(c.Layout) != "" {
+			mark = fmt.Sprintf(" [layout %s]", domain.EffectiveCatalogLayout(c)) + mark
+		}
 		fmt.Fprintf(a.Stdout, "%s\t%s%s\n", c.Name, c.Root, mark)
 	}
 	a.logf("catalog list: reported %d catalog(s)", len(machine.Catalogs))
@@ -1653,6 +1801,106 @@ func (a *App) RunRepoPreferredRemote(repoSelector string, preferredRemote string
 	return 0, nil
 }
 
+// RunRepoPrimary sets or clears the machine whose observation wins reconcile
+// for a repo.
+func (a *App) RunRepoPrimary(opts RepoPrimaryOptions) (int, error) {
+	a.logf("repo primary: acquiring global lock")
+	lock, err := state.AcquireLock(a.Paths)
+	if err != nil {
+		return 2, err
+	}
+	defer func() {
+		_ = lock.Release()
+		a.logf("repo primary: released global lock")
+	}()
+
+	machineID := strings.TrimSpace(opts.MachineID)
+	if opts.Clear && machineID != "" {
+		return 2, errors.New("--machine and --clear are mutually exclusive")
+	}
+	repos, err := state.LoadAllRepoMetadata(a.Paths)
+	if err != nil {
+		return 2, err
+	}
+	idx, err := selectRepoMetadataIndex(repos, opts.Selector)
+	if err != nil {
+		return 2, err
+	}
+	if idx == -1 {
+		return 2, fmt.Errorf("repo %q not found", opts.Selector)
+	}
+	if !opts.Clear {
+		_, machine, err := a.loadContext()
+		if err != nil {
+			return 2, err
+		}
+		if machineID == "" {
+			machineID = machine.MachineID
+		} else if machineID != machine.MachineID {
+			machines, err := state.LoadAllMachineFiles(a.Paths)
+			if err != nil {
+				return 2, err
+			}
+			if !slices.ContainsFunc(machines, func(m domain.MachineFile) bool { return m.MachineID == machineID }) {
+				return 2, fmt.Errorf("machine %q is not known; it needs a machine file before it can be primary", machineID)
+			}
+		}
+	}
+	repos[idx].PrimaryMachineID = machineID
+	if err := state.SaveRepoMetadata(a.Paths, repos[idx]); err != nil {
+		return 2, err
+	}
+	a.logf("repo primary: set primary_machine_id=%q for %s", machineID, repos[idx].RepoKey)
+	return 0, nil
+}
+
+// RunRepoBranch pins a repo to a branch, turning off branch following, or
+// turns branch following back on.
+func (a *App) RunRepoBranch(opts RepoBranchOptions) (int, error) {
+	a.logf("repo branch: acquiring global lock")
+	lock, err := state.AcquireLock(a.Paths)
+	if err != nil {
+		return 2, err
+	}
+	defer func() {
+		_ = lock.Release()
+		a.logf("repo branch: released global lock")
+	}()
+
+	pin := strings.TrimSpace(opts.Pin)
+	if (pin == "") == !opts.Follow {
+		return 2, errors.New("exactly one of --pin or --follow is required")
+	}
+	if pin != "" {
+		if _, err := a.Git.RunGit("", "check-ref-format", "--branch", pin); err != nil {
+			return 2, fmt.Errorf("invalid branch name %q", pin)
+		}
+	}
+	repos, err := state.LoadAllRepoMetadata(a.Paths)
+	if err != nil {
+		return 2, err
+	}
+	idx, err := selectRepoMetadataIndex(repos, opts.Selector)
+	if err != nil {
+		return 2, err
+	}
+	if idx == -1 {
+		return 2, fmt.Errorf("repo %q not found", opts.Selector)
+	}
+	repos[idx].BranchFollowEnabled = opts.Follow
+	repos[idx].PinnedBranch = pin
+	if err := state.SaveRepoMetadata(a.Paths, repos[idx]); err != nil {
+		return 2, err
+	}
+	a.logf(
+		"repo branch: set branch_follow_enabled=%t pinned_branch=%q for %s",
+		repos[idx].BranchFollowEnabled,
+		repos[idx].PinnedBranch,
+		repos[idx].RepoKey,
+	)
+	return 0, nil
+}
+
 func (a *App) RunRepoPushAccessSet(repoSelector string, pushAccessRaw string) (int, error) {
 	a.logf("repo access set: acquiring global lock")
 	lock, err := state.AcquireLock(a.Paths)
@@ -1718,7 +1966,7 @@ func (a *App) RunRepoPushAccessRefresh(repoSelector string) (int, error) {
 		return 2, fmt.Errorf("repo %q not found", repoSelector)
 	}
 
-	_, machine, err := a.loadContext()
+	cfg, machine, err := a.loadContext()
 	if err != nil {
 		return 2, err
 	}
@@ -1730,13 +1978,20 @@ func (a *App) RunRepoPushAccessRefresh(repoSelector string) (int, error) {
 	}
 
 	repo.PushAccessManualOverride = false
-	updated, changed, err := a.probeAndUpdateRepoPushAccess(repoPath, repo.OriginURL, repo, true)
+	updated, changed, err := a.probeAndUpdateRepoPushAccess(cfg, repoPath, repo.OriginURL, repo, true, a.logf)
 	if err != nil {
 		return 2, err
 	}
 	if updated.PushAccess == domain.PushAccessReadOnly {
 		updated.AutoPush = domain.AutoPushModeDisabled
 	}
+	if updated.Visibility == "" || updated.Visibility == domain.VisibilityUnknown {
+		if visibility, ok := a.lookupVisibilityViaForge(cfg, repo.OriginURL); ok {
+			updated.Visibility = visibility
+			changed = true
+			a.logf("repo access refresh: set visibility=%q for %s", visibility, updated.RepoKey)
+		}
+	}
 	if changed {
 		if err := state.SaveRepoMetadata(a.Paths, updated); err != nil {
 			return 2, err
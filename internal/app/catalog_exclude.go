@@ -0,0 +1,110 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"bb-project/internal/domain"
+	"bb-project/internal/state"
+)
+
+// excludedTrackedRepo is a registered repo whose path in a local catalog is
+// excluded from discovery.
+type excludedTrackedRepo struct {
+	RepoKey string
+	Catalog string
+	Path    string
+}
+
+// catalogExcludes reports whether the catalog-relative path is excluded by
+// the catalog's exclude patterns or by a .bbignore in the catalog root or one
+// of the path's parent directories, as discoverRepos would decide.
+func catalogExcludes(c domain.Catalog, relativePath string) bool {
+	excludes := domain.CatalogExcludeRules(c)
+	parts := strings.Split(filepath.ToSlash(relativePath), "/")
+	for i := range parts {
+		addIgnoreFileRules(&excludes, c.Root, strings.Join(parts[:i], "/"))
+	}
+	return excludes.Matches(relativePath)
+}
+
+// addIgnoreFileRules adds the patterns of the .bbignore in the
+// catalog-relative directory relDir, if there is one.
+func addIgnoreFileRules(excludes *domain.ExcludeRules, root string, relDir string) {
+	if relDir == "." {
+		relDir = ""
+	}
+	raw, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(relDir), domain.IgnoreFileName))
+	if err != nil {
+		return
+	}
+	excludes.Add(relDir, domain.ParseIgnoreFile(string(raw))...)
+}
+
+// excludedTrackedRepos lists repos of the allowed catalogs that are still
+// registered, in shared metadata or this machine's records, although
+// discovery now excludes their path.
+func (a *App) excludedTrackedRepos(machine domain.MachineFile, allowed map[string]struct{}) ([]excludedTrackedRepo, error) {
+	metas, err := state.LoadAllRepoMetadata(a.Paths)
+	if err != nil {
+		return nil, err
+	}
+	moveIndex, err := buildRepoMoveIndex(metas)
+	if err != nil {
+		return nil, err
+	}
+	repoKeys := map[string]struct{}{}
+	for _, meta := range metas {
+		if _, historical := moveIndex[strings.TrimSpace(meta.RepoKey)]; !historical {
+			repoKeys[strings.TrimSpace(meta.RepoKey)] = struct{}{}
+		}
+	}
+	for _, rec := range machine.Repos {
+		repoKeys[strings.TrimSpace(rec.RepoKey)] = struct{}{}
+	}
+
+	out := []excludedTrackedRepo{}
+	for repoKey := range repoKeys {
+		catalogName, relativePath, _, err := domain.ParseRepoKey(repoKey)
+		if err != nil {
+			continue
+		}
+		if _, ok := allowed[catalogName]; !ok {
+			continue
+		}
+		catalog, ok := domain.FindCatalog(machine, catalogName)
+		if !ok || !catalogExcludes(catalog, relativePath) {
+			continue
+		}
+		out = append(out, excludedTrackedRepo{
+			RepoKey: repoKey,
+			Catalog: catalogName,
+			Path:    filepath.Join(catalog.Root, filepath.FromSlash(relativePath)),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].RepoKey < out[j].RepoKey })
+	return out, nil
+}
+
+func excludedTrackedRepoMessage(repo excludedTrackedRepo) string {
+	return fmt.Sprintf("%s is excluded but tracked: %s matches the exclude rules of catalog %s", repo.RepoKey, repo.Path, repo.Catalog)
+}
+
+const excludedTrackedRepoRemediation = "stop tracking the repo by deleting its file in the shared state repos/ directory, or remove the matching exclude pattern or .bbignore entry"
+
+func (a *App) reportExcludedTrackedRepos(machine domain.MachineFile, allowed map[string]struct{}) (int, error) {
+	repos, err := a.excludedTrackedRepos(machine, allowed)
+	if err != nil {
+		return 0, err
+	}
+	for _, repo := range repos {
+		fmt.Fprintf(a.Stdout, "warning: %s\n", excludedTrackedRepoMessage(repo))
+	}
+	if len(repos) > 0 {
+		fmt.Fprintf(a.Stdout, "warning: %s\n", excludedTrackedRepoRemediation)
+	}
+	return len(repos), nil
+}
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"bb-project/internal/domain"
+)
+
+func TestDiscoverReposHonorsCatalogExcludesAndIgnoreFiles(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	for _, rel := range []string{"you/api", "scratch-1", "experiments/demo", "you/web", "you/vendored", "you/keep"} {
+		if err := os.MkdirAll(filepath.Join(root, filepath.FromSlash(rel), ".git"), 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", rel, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, ".bbignore"), []byte("# throwaway work\nexperiments\n"), 0o644); err != nil {
+		t.Fatalf("write root .bbignore: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "you", ".bbignore"), []byte("vendored\n"), 0o644); err != nil {
+		t.Fatalf("write nested .bbignore: %v", err)
+	}
+	catalog := domain.Catalog{Name: "software", Root: root, RepoPathDepth: 2, Exclude: []string{"scratch-*"}}
+
+	discovered, err := discoverRepos([]domain.Catalog{catalog})
+	if err != nil {
+		t.Fatalf("discoverRepos failed: %v", err)
+	}
+	got := []string{}
+	for _, repo := range discovered {
+		rel, _ := filepath.Rel(root, repo.Path)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	if want := []string{"you/api", "you/keep", "you/web"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("discovered = %v, want %v", got, want)
+	}
+
+	for rel, want := range map[string]bool{
+		"you/api":          false,
+		"scratch-1":        true,
+		"experiments/demo": true,
+		"you/vendored":     true,
+		"you/web":          false,
+	} {
+		if got := catalogExcludes(catalog, rel); got != want {
+			t.Errorf("catalogExcludes(%q) = %t, want %t", rel, got, want)
+		}
+	}
+}
@@ -21,6 +21,7 @@ type cloneOutcome struct {
 
 type cloneRepoSpec struct {
 	CloneURL string
+	Host     string
 	Owner    string
 	RepoName string
 }
@@ -133,38 +134,20 @@ func resolveCloneCatalog(machine domain.MachineFile, cfg domain.ConfigFile, expl
 }
 
 func resolveCloneTarget(catalog domain.Catalog, spec cloneRepoSpec, as string) (repoKey string, relativePath string, repoName string, err error) {
-	trimmedAs := strings.TrimSpace(as)
-	if trimmedAs != "" {
-		repoKey, relativePath, repoName, ok := domain.DeriveRepoKeyFromRelative(catalog, trimmedAs)
+	layout := domain.EffectiveCatalogLayout(catalog)
+	relative := strings.TrimSpace(as)
+	if relative == "" {
+		var ok bool
+		relative, ok = layout.Expand(spec.Host, spec.Owner, spec.RepoName)
 		if !ok {
-			return "", "", "", fmt.Errorf("clone target path must match catalog layout depth %d", domain.EffectiveRepoPathDepth(catalog))
+			return "", "", "", fmt.Errorf("cannot derive a path for catalog layout %s from input; pass --as", layout)
 		}
-		return repoKey, relativePath, repoName, nil
 	}
-
-	switch domain.EffectiveRepoPathDepth(catalog) {
-	case 1:
-		if strings.TrimSpace(spec.RepoName) == "" {
-			return "", "", "", errors.New("cannot derive repository name from input; pass --as")
-		}
-		repoKey, relativePath, repoName, ok := domain.DeriveRepoKeyFromRelative(catalog, spec.RepoName)
-		if !ok {
-			return "", "", "", fmt.Errorf("clone target path must match catalog layout depth %d", domain.EffectiveRepoPathDepth(catalog))
-		}
-		return repoKey, relativePath, repoName, nil
-	case 2:
-		if strings.TrimSpace(spec.Owner) == "" || strings.TrimSpace(spec.RepoName) == "" {
-			return "", "", "", errors.New("cannot derive owner/repo path from input; pass --as")
-		}
-		relative := spec.Owner + "/" + spec.RepoName
-		repoKey, relativePath, repoName, ok := domain.DeriveRepoKeyFromRelative(catalog, relative)
-		if !ok {
-			return "", "", "", fmt.Errorf("clone target path must match catalog layout depth %d", domain.EffectiveRepoPathDepth(catalog))
-		}
-		return repoKey, relativePath, repoName, nil
-	default:
-		return "", "", "", fmt.Errorf("unsupported catalog layout depth %d", domain.EffectiveRepoPathDepth(catalog))
+	repoKey, relativePath, repoName, ok := domain.DeriveRepoKeyFromRelative(catalog, relative)
+	if !ok {
+		return "", "", "", fmt.Errorf("clone target path must match catalog layout %s", layout)
 	}
+	return repoKey, relativePath, repoName, nil
 }
 
 func resolveCloneTransportOptions(cfg domain.ConfigFile, catalog string, opts CloneOptions) (shallow bool, filter string, only []string) {
@@ -213,7 +196,8 @@ func parseCloneRepoSpec(cfg domain.ConfigFile, input string, getenv func(string)
 
 	if owner, repo, ok := parseGitHubShorthand(raw); ok {
 		return cloneRepoSpec{
-			CloneURL: resolveGitHubCloneURL(cfg, owner, repo, false, getenv),
+			CloneURL: resolveShorthandCloneURL(cfg, owner, repo, getenv),
+			Host:     defaultForgeHost(cfg),
 			Owner:    owner,
 			RepoName: repo,
 		}, nil
@@ -222,6 +206,7 @@ func parseCloneRepoSpec(cfg domain.ConfigFile, input string, getenv func(string)
 	if owner, repo, ok := parseGitHubHTTPRepoLink(raw); ok {
 		return cloneRepoSpec{
 			CloneURL: resolveGitHubCloneURL(cfg, owner, repo, true, getenv),
+			Host:     "github.com",
 			Owner:    owner,
 			RepoName: repo,
 		}, nil
@@ -231,9 +216,10 @@ func parseCloneRepoSpec(cfg domain.ConfigFile, input string, getenv func(string)
 	if err != nil {
 		return cloneRepoSpec{}, fmt.Errorf("invalid repo input %q", raw)
 	}
-	_, owner, repo := deriveIdentityOwnerRepo(identity)
+	host, owner, repo := deriveIdentityOwnerRepo(identity)
 	return cloneRepoSpec{
 		CloneURL: raw,
+		Host:     host,
 		Owner:    owner,
 		RepoName: repo,
 	}, nil
@@ -303,6 +289,25 @@ func resolveGitHubCloneURL(cfg domain.ConfigFile, owner string, repo string, for
 	return fmt.Sprintf("git@github.com:%s/%s.git", owner, repo)
 }
 
+// resolveShorthandCloneURL resolves an owner/repo shorthand on the default
+// forge host (github.host).
+func resolveShorthandCloneURL(cfg domain.ConfigFile, owner string, repo string, getenv func(string) string) string {
+	host := defaultForgeHost(cfg)
+	if host == githubHost {
+		return resolveGitHubCloneURL(cfg, owner, repo, false, getenv)
+	}
+	if getenv != nil {
+		if fakeRoot := strings.TrimSpace(getenv("BB_TEST_REMOTE_ROOT")); fakeRoot != "" {
+			return "file://" + filepath.Join(fakeRoot, owner, repo+".git")
+		}
+	}
+	url, err := forgeRemoteURL(host, owner, repo, cfg.GitHub.RemoteProtocol, cfg.GitHub.PreferredRemoteURLTemplate)
+	if err != nil {
+		return fmt.Sprintf("git@%s:%s/%s.git", host, owner, repo)
+	}
+	return url
+}
+
 func deriveIdentityOwnerRepo(identity string) (host string, owner string, repo string) {
 	host, path, ok := strings.Cut(identity, "/")
 	if !ok {
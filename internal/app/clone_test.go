@@ -311,3 +311,47 @@ func setupCloneTestRemote(t *testing.T, remoteRoot string, owner string, repo st
 	}
 	return remotePath
 }
+
+func TestResolveCloneTargetFollowsCatalogLayout(t *testing.T) {
+	t.Parallel()
+
+	spec := cloneRepoSpec{Host: "github.com", Owner: "you", RepoName: "api"}
+	tests := []struct {
+		name    string
+		catalog domain.Catalog
+		as      string
+		wantKey string
+		wantErr string
+	}{
+		{name: "depth 1", catalog: domain.Catalog{Name: "software", RepoPathDepth: 1}, wantKey: "software/api"},
+		{name: "depth 2", catalog: domain.Catalog{Name: "software", RepoPathDepth: 2}, wantKey: "software/you/api"},
+		{name: "host owner repo", catalog: domain.Catalog{Name: "src", Layout: "{host}/{owner}/{repo}"}, wantKey: "src/github.com/you/api"},
+		{name: "depth range", catalog: domain.Catalog{Name: "mixed", Layout: "depth:1-3"}, wantKey: "mixed/you/api"},
+		{name: "as within range", catalog: domain.Catalog{Name: "mixed", Layout: "depth:1-3"}, as: "scratch", wantKey: "mixed/scratch"},
+		{
+			name:    "as outside layout",
+			catalog: domain.Catalog{Name: "src", Layout: "{host}/{owner}/{repo}"},
+			as:      "you/api",
+			wantErr: "clone target path must match catalog layout {host}/{owner}/{repo}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			repoKey, _, _, err := resolveCloneTarget(tt.catalog, spec, tt.as)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("resolveCloneTarget() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || repoKey != tt.wantKey {
+				t.Fatalf("resolveCloneTarget() = %q, %v, want %q", repoKey, err, tt.wantKey)
+			}
+		})
+	}
+
+	if _, _, _, err := resolveCloneTarget(domain.Catalog{Name: "src", Layout: "{host}/{owner}/{repo}"}, cloneRepoSpec{RepoName: "api"}, ""); err == nil {
+		t.Fatal("expected an error when the input has no host or owner")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -78,7 +79,7 @@ func (a *App) RunConfig() error {
 		ConfigPath:        configPath,
 		MachinePath:       machinePath,
 		LumenAvailable:    a.isLumenAvailableForConfigWizard(),
-		GitHubCLIStatus:   a.detectGitHubCLIStatus(),
+		GitHubCLIStatus:   a.detectGitHubCLIStatus(cfg),
 		KnownCatalogRoots: knownCatalogRoots,
 	})
 	if err != nil {
@@ -228,13 +229,72 @@ func (a *App) loadConfigAndMachineForConfig() (domain.ConfigFile, domain.Machine
 	return cfg, machine, machineID, nil
 }
 
+func validateNotifyBackends(cfg domain.NotifyConfig) error {
+	seen := make(map[string]struct{}, len(cfg.Backends))
+	for i, backendCfg := range cfg.Backends {
+		name, err := normalizeNotifyBackend(backendCfg.Name)
+		if err != nil {
+			return fmt.Errorf("notify.backends[%d]: %w", i, err)
+		}
+		if _, ok := seen[name]; ok {
+			return fmt.Errorf("notify.backends[%d]: duplicate backend %q", i, name)
+		}
+		seen[name] = struct{}{}
+		if name == notifyBackendWebhook {
+			if err := validateNotifyWebhookURL(cfg.Webhook.URL); err != nil {
+				return fmt.Errorf("notify.backends[%d]: %w", i, err)
+			}
+		}
+		for _, reason := range append(append([]domain.UnsyncableReason{}, backendCfg.Reasons...), backendCfg.ExcludeReasons...) {
+			if !domain.IsKnownUnsyncableReason(reason) {
+				return fmt.Errorf("notify.backends[%d]: unknown unsyncable reason %q", i, reason)
+			}
+		}
+	}
+	return nil
+}
+
+func validateForges(cfg domain.ConfigFile) error {
+	hosts := make([]string, 0, len(cfg.Forges))
+	for host := range cfg.Forges {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		forge := cfg.Forges[host]
+		if strings.TrimSpace(host) == "" || strings.ContainsAny(host, "/@ ") {
+			return fmt.Errorf("forges keys must be host names, got %q", host)
+		}
+		switch strings.ToLower(strings.TrimSpace(forge.Type)) {
+		case domain.ForgeTypeGitHub, domain.ForgeTypeGitLab, domain.ForgeTypeGitea, domain.ForgeTypeForgejo:
+		default:
+			return fmt.Errorf("forges[%q].type must be github, gitlab, gitea or forgejo", host)
+		}
+		if apiURL := strings.TrimSpace(forge.APIURL); apiURL != "" {
+			parsed, err := url.Parse(apiURL)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				return fmt.Errorf("forges[%q].api_url must be an http or https URL", host)
+			}
+		}
+		if tokenFile := strings.TrimSpace(forge.TokenFile); tokenFile != "" && !filepath.IsAbs(tokenFile) {
+			return fmt.Errorf("forges[%q].token_file must be an absolute path", host)
+		}
+	}
+	if host := defaultForgeHost(cfg); host != githubHost {
+		if _, ok := forgeConfigForHost(cfg, host); !ok {
+			return fmt.Errorf("github.host %q needs a matching forges entry", host)
+		}
+	}
+	return nil
+}
+
 func validateConfigForSave(cfg domain.ConfigFile) error {
 	owner := strings.TrimSpace(cfg.GitHub.Owner)
 	if owner == "" {
 		return errors.New("github.owner is required")
 	}
-	if cfg.StateTransport.Mode != "external" {
-		return fmt.Errorf("state_transport.mode must be external")
+	if err := validateStateTransport(cfg.StateTransport); err != nil {
+		return err
 	}
 	if cfg.GitHub.DefaultVisibility != "private" && cfg.GitHub.DefaultVisibility != "public" {
 		return fmt.Errorf("github.default_visibility must be private or public")
@@ -245,9 +305,23 @@ func validateConfigForSave(cfg domain.ConfigFile) error {
 	if err := validateGitHubRemoteURLTemplate(cfg.GitHub.PreferredRemoteURLTemplate); err != nil {
 		return err
 	}
+	if err := validateForges(cfg); err != nil {
+		return err
+	}
 	if cfg.Notify.ThrottleMinutes < 0 {
 		return fmt.Errorf("notify.throttle_minutes must be >= 0")
 	}
+	if strings.TrimSpace(cfg.Notify.Webhook.URL) != "" {
+		if err := validateNotifyWebhookURL(cfg.Notify.Webhook.URL); err != nil {
+			return err
+		}
+	}
+	if cfg.Notify.Webhook.TimeoutSeconds < 0 {
+		return fmt.Errorf("notify.webhook.timeout_seconds must be >= 0")
+	}
+	if err := validateNotifyBackends(cfg.Notify); err != nil {
+		return err
+	}
 	if cfg.Scheduler.IntervalMinutes < 1 {
 		return fmt.Errorf("scheduler.interval_minutes must be >= 1")
 	}
@@ -296,7 +370,7 @@ func validateMachineForSave(machine domain.MachineFile) error {
 		if !filepath.IsAbs(root) {
 			return fmt.Errorf("catalog %q root must be an absolute path", name)
 		}
-		if err := domain.ValidateRepoPathDepth(c.RepoPathDepth); err != nil {
+		if err := domain.ValidateCatalogLayout(c); err != nil {
 			return fmt.Errorf("catalog %q %w", name, err)
 		}
 	}
@@ -44,6 +44,75 @@ func TestValidateConfigForSaveRequiresSchedulerInterval(t *testing.T) {
 	}
 }
 
+func TestValidateConfigForSaveValidatesNotifyBackends(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		backends []domain.NotifyBackendConfig
+		want     string
+	}{
+		{name: "unknown backend", backends: []domain.NotifyBackendConfig{{Name: "pager"}}, want: "invalid notify backend"},
+		{name: "duplicate backend", backends: []domain.NotifyBackendConfig{{Name: "stdout"}, {Name: "STDOUT"}}, want: "duplicate backend"},
+		{name: "unknown reason", backends: []domain.NotifyBackendConfig{{Name: "stdout", Reasons: []domain.UnsyncableReason{"dirty"}}}, want: "unknown unsyncable reason"},
+		{name: "webhook without url", backends: []domain.NotifyBackendConfig{{Name: "webhook"}}, want: "notify.webhook.url"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := state.DefaultConfig()
+			cfg.GitHub.Owner = "you"
+			cfg.Notify.Backends = tt.backends
+			err := validateConfigForSave(cfg)
+			if err == nil {
+				t.Fatalf("expected error containing %q", tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateConfigForSaveValidatesForges(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		host   string
+		forges map[string]domain.ForgeConfig
+		want   string
+	}{
+		{name: "unknown type", forges: map[string]domain.ForgeConfig{"git.example.com": {Type: "bitbucket"}}, want: "forges[\"git.example.com\"].type"},
+		{name: "url as key", forges: map[string]domain.ForgeConfig{"https://git.example.com/": {Type: "gitea"}}, want: "forges keys must be host names"},
+		{name: "invalid api url", forges: map[string]domain.ForgeConfig{"git.example.com": {Type: "gitea", APIURL: "git.example.com"}}, want: "api_url"},
+		{name: "default host without forge", host: "git.example.com", want: "github.host"},
+		{name: "relative token file", forges: map[string]domain.ForgeConfig{"github.com": {Type: "github", TokenFile: "token"}}, want: "token_file"},
+		{name: "valid", host: "git.example.com", forges: map[string]domain.ForgeConfig{"git.example.com": {Type: "forgejo", APIURL: "https://git.example.com"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := state.DefaultConfig()
+			cfg.GitHub.Owner = "you"
+			cfg.GitHub.Host = tt.host
+			cfg.Forges = tt.forges
+			err := validateConfigForSave(cfg)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
 func TestValidateConfigForSaveRejectsInvalidGitHubRemoteURLTemplate(t *testing.T) {
 	t.Parallel()
 
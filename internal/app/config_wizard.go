@@ -49,6 +49,7 @@ const (
 	automationFocusScheduler = iota
 	automationFocusNotifyEnabled
 	automationFocusNotifyDedupe
+	automationFocusNotifyRecovered
 	automationFocusNotifyThrottle
 	automationFocusCount
 )
@@ -478,8 +479,8 @@ func newConfigWizardModel(input ConfigWizardInput) *configWizardModel {
 	if m.machine.Catalogs == nil {
 		m.machine.Catalogs = []domain.Catalog{}
 	}
-	if m.config.StateTransport.Mode != "external" {
-		m.config.StateTransport.Mode = "external"
+	if m.config.StateTransport.Mode != domain.StateTransportGit {
+		m.config.StateTransport.Mode = domain.StateTransportExternal
 	}
 	if m.config.Scheduler.IntervalMinutes <= 0 {
 		m.config.Scheduler.IntervalMinutes = 60
@@ -875,6 +876,11 @@ func (m *configWizardModel) updateAutomation(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.recomputeDirty()
 				return m, nil
 			}
+			if m.automationFocus == automationFocusNotifyRecovered {
+				m.config.Notify.Recovered = !m.config.Notify.Recovered
+				m.recomputeDirty()
+				return m, nil
+			}
 		case "enter":
 			if !m.focusTabs {
 				if v, err := strconv.Atoi(strings.TrimSpace(m.schedulerInterval.Value())); err == nil {
@@ -1851,6 +1857,13 @@ func (m *configWizardModel) viewAutomation() string {
 		m.config.Notify.Dedupe,
 	))
 	b.WriteString("\n\n")
+	b.WriteString(renderToggleField(
+		!m.focusTabs && m.automationFocus == automationFocusNotifyRecovered,
+		"Notify on recovery",
+		"Sends a one-off notification when a previously notified repository is syncable again.",
+		m.config.Notify.Recovered,
+	))
+	b.WriteString("\n\n")
 	b.WriteString(renderFieldBlock(
 		!m.focusTabs && m.automationFocus == automationFocusNotifyThrottle,
 		"Notification throttle (minutes)",
@@ -2103,7 +2116,7 @@ func (m *configWizardModel) diffLines() []string {
 	if m.originalConfig.Scheduler != m.config.Scheduler {
 		out = append(out, "scheduler settings updated")
 	}
-	if m.originalConfig.Notify != m.config.Notify {
+	if !reflect.DeepEqual(m.originalConfig.Notify, m.config.Notify) {
 		out = append(out, "notify settings updated")
 	}
 	if m.originalConfig.Integrations != m.config.Integrations {
@@ -2133,6 +2146,9 @@ func githubCLIPrerequisiteMessage(status GitHubCLIStatus) string {
 	if !status.Checked {
 		return ""
 	}
+	if source := strings.TrimSpace(status.TokenSource); source != "" {
+		return hintStyle.Render("GitHub operations use the API token from " + source + "; gh is not required.")
+	}
 	if !status.Installed {
 		return warnStyle.Render("GitHub operations require gh. Install with `brew install gh`, then run `gh auth login`.")
 	}
@@ -2458,6 +2474,9 @@ func onOffLabel(v bool) string {
 }
 
 func repoPathDepthLabel(c domain.Catalog) string {
+	if strings.TrimSpace(c.Layout) != "" {
+		return domain.EffectiveCatalogLayout(c).String()
+	}
 	if domain.EffectiveRepoPathDepth(c) == 2 {
 		return "2-level"
 	}
@@ -2722,6 +2741,9 @@ func (m *configWizardModel) toggleRepoPathDepthFromSelection() error {
 	if idx < 0 || idx >= len(m.machine.Catalogs) {
 		return fmt.Errorf("select a catalog first")
 	}
+	if layout := strings.TrimSpace(m.machine.Catalogs[idx].Layout); layout != "" {
+		return fmt.Errorf("catalog %s uses layout %q; edit layout in the machine file", m.machine.Catalogs[idx].Name, layout)
+	}
 	current := domain.EffectiveRepoPathDepth(m.machine.Catalogs[idx])
 	if current == 2 {
 		m.machine.Catalogs[idx].RepoPathDepth = 1
@@ -24,7 +24,7 @@ func TestRunDoctorWarnsWhenGitHubCLIIsMissing(t *testing.T) {
 		return "/usr/bin/" + file, nil
 	}
 
-	code, err := a.RunDoctor(nil)
+	code, err := a.RunDoctor(DoctorOptions{})
 	if err != nil {
 		t.Fatalf("RunDoctor failed: %v", err)
 	}
@@ -64,7 +64,7 @@ func TestRunDoctorWarnsWhenGitHubCLIIsUnauthenticated(t *testing.T) {
 		return "not logged into any GitHub hosts", errors.New("exit status 1")
 	}
 
-	code, err := a.RunDoctor(nil)
+	code, err := a.RunDoctor(DoctorOptions{})
 	if err != nil {
 		t.Fatalf("RunDoctor failed: %v", err)
 	}
@@ -101,7 +101,7 @@ func TestRunDoctorSkipsGitHubCLIWarningWhenGitHubNotNeeded(t *testing.T) {
 		return "/usr/bin/" + file, nil
 	}
 
-	code, err := a.RunDoctor(nil)
+	code, err := a.RunDoctor(DoctorOptions{})
 	if err != nil {
 		t.Fatalf("RunDoctor failed: %v", err)
 	}
@@ -0,0 +1,247 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"bb-project/internal/domain"
+	"bb-project/internal/state"
+)
+
+// doctorJSONSchemaVersion versions `bb doctor --json`; see
+// docs/schema/bb-doctor.v1.schema.json.
+const doctorJSONSchemaVersion = 1
+
+const (
+	doctorFindingUnsyncableRepo        = "unsyncable_repo"
+	doctorFindingNotifyDeliveryFailure = "notify_delivery_failure"
+	doctorFindingGitHubCLI             = "github_cli"
+	doctorFindingStateConflict         = "state_conflict_resolved"
+	doctorFindingExcludedTrackedRepo   = "excluded_tracked_repo"
+	doctorFindingStateBackupFallback   = "state_backup_fallback"
+
+	doctorSeverityError   = "error"
+	doctorSeverityWarning = "warning"
+	doctorSeverityInfo    = "info"
+)
+
+type doctorJSONReport struct {
+	SchemaVersion int                 `json:"schema_version"`
+	MachineID     string              `json:"machine_id"`
+	OK            bool                `json:"ok"`
+	Findings      []doctorJSONFinding `json:"findings"`
+}
+
+// doctorJSONFinding is one problem doctor found. Repo fields are set for
+// repo-scoped findings; backend/error/failed_at only for notify failures;
+// target/conflict_copy/action/archived_to only for resolved state conflicts;
+// target/backup_id only for state files read from a backup.
+type doctorJSONFinding struct {
+	Kind         string                    `json:"kind"`
+	Severity     string                    `json:"severity"`
+	Message      string                    `json:"message"`
+	Remediation  string                    `json:"remediation"`
+	RepoKey      string                    `json:"repo_key,omitempty"`
+	Name         string                    `json:"name,omitempty"`
+	Path         string                    `json:"path,omitempty"`
+	Catalog      string                    `json:"catalog,omitempty"`
+	Reasons      []domain.UnsyncableReason `json:"reasons,omitempty"`
+	Backend      string                    `json:"backend,omitempty"`
+	Error        string                    `json:"error,omitempty"`
+	FailedAt     *time.Time                `json:"failed_at,omitempty"`
+	Target       string                    `json:"target,omitempty"`
+	ConflictCopy string                    `json:"conflict_copy,omitempty"`
+	Action       string                    `json:"action,omitempty"`
+	ArchivedTo   string                    `json:"archived_to,omitempty"`
+	BackupID     string                    `json:"backup_id,omitempty"`
+	FixActions   []string                  `json:"fix_actions"`
+	FixCommands  [][]string                `json:"fix_commands"`
+}
+
+func (a *App) runDoctorJSON(cfg domain.ConfigFile, machine domain.MachineFile, allowed map[string]struct{}) (int, error) {
+	metas, err := state.LoadAllRepoMetadata(a.Paths)
+	if err != nil {
+		return 2, err
+	}
+	metaByRepoKey := repoMetadataByKey(metas)
+
+	report := doctorJSONReport{
+		SchemaVersion: doctorJSONSchemaVersion,
+		MachineID:     machine.MachineID,
+		Findings:      []doctorJSONFinding{},
+	}
+
+	unsyncable := false
+	recordsByKey := make(map[string]domain.MachineRepoRecord, len(machine.Repos))
+	for _, rec := range machine.Repos {
+		if rec.RepoKey != "" {
+			recordsByKey[rec.RepoKey] = rec
+		}
+		if _, ok := allowed[rec.Catalog]; !ok || rec.Syncable {
+			continue
+		}
+		unsyncable = true
+		finding := doctorJSONFinding{
+			Kind:        doctorFindingUnsyncableRepo,
+			Severity:    doctorSeverityError,
+			Message:     fmt.Sprintf("%s is unsyncable: %s", rec.Name, joinUnsyncableReasons(rec.UnsyncableReasons)),
+			Remediation: "run one of the listed `bb fix` actions, or `bb fix` to review interactively",
+			RepoKey:     rec.RepoKey,
+			Name:        rec.Name,
+			Path:        rec.Path,
+			Catalog:     rec.Catalog,
+			Reasons:     append([]domain.UnsyncableReason{}, rec.UnsyncableReasons...),
+		}
+		finding.FixActions, finding.FixCommands = a.doctorFixSuggestions(rec, metaByRepoKey[rec.RepoKey])
+		report.Findings = append(report.Findings, finding)
+	}
+
+	warnings, err := a.loadNotifyDeliveryWarnings()
+	if err != nil {
+		return 2, err
+	}
+	for _, warning := range warnings {
+		finding := doctorJSONFinding{
+			Kind:        doctorFindingNotifyDeliveryFailure,
+			Severity:    doctorSeverityWarning,
+			Message:     fmt.Sprintf("notification delivery failed for %s via %s", warning.RepoLabel, strings.TrimSpace(warning.Failure.Backend)),
+			Remediation: "check the notify backend configuration; the failure clears after the next successful delivery",
+			RepoKey:     strings.TrimSpace(warning.Failure.RepoKey),
+			Name:        strings.TrimSpace(warning.Failure.RepoName),
+			Path:        strings.TrimSpace(warning.Failure.RepoPath),
+			Backend:     strings.TrimSpace(warning.Failure.Backend),
+			Error:       strings.TrimSpace(warning.Failure.Error),
+			FailedAt:    optionalTime(warning.Failure.FailedAt),
+			FixActions:  []string{},
+			FixCommands: [][]string{},
+		}
+		if rec, ok := recordsByKey[finding.RepoKey]; ok && !rec.Syncable {
+			finding.FixActions, finding.FixCommands = a.doctorFixSuggestions(rec, metaByRepoKey[rec.RepoKey])
+		}
+		report.Findings = append(report.Findings, finding)
+	}
+
+	resolutions, err := a.recentStateConflictResolutions()
+	if err != nil {
+		return 2, err
+	}
+	for _, r := range resolutions {
+		report.Findings = append(report.Findings, doctorJSONFinding{
+			Kind:         doctorFindingStateConflict,
+			Severity:     doctorSeverityInfo,
+			Message:      stateConflictResolutionMessage(r),
+			Remediation:  "none required; inspect the archived copy if the merged result looks wrong",
+			Target:       r.Target,
+			ConflictCopy: r.ConflictCopy,
+			Action:       r.Action,
+			ArchivedTo:   r.ArchivedTo,
+			FixActions:   []string{},
+			FixCommands:  [][]string{},
+		})
+	}
+
+	fallbacks, err := state.BackupFallbacks(a.Paths)
+	if err != nil {
+		return 2, err
+	}
+	for _, fallback := range fallbacks {
+		report.Findings = append(report.Findings, doctorJSONFinding{
+			Kind:        doctorFindingStateBackupFallback,
+			Severity:    doctorSeverityWarning,
+			Message:     a.stateBackupFallbackMessage(fallback),
+			Remediation: a.stateBackupFallbackRemediation(fallback),
+			Target:      a.stateFileLabel(fallback.Path),
+			BackupID:    fallback.Backup.ID,
+			FixActions:  []string{},
+			FixCommands: [][]string{},
+		})
+	}
+
+	if warning, ok := a.githubCLIWarning(cfg, machine.Repos, allowed); ok {
+		report.Findings = append(report.Findings, doctorJSONFinding{
+			Kind:        doctorFindingGitHubCLI,
+			Severity:    doctorSeverityWarning,
+			Message:     warning.Message,
+			Remediation: warning.Remediation,
+			Error:       warning.Detail,
+			FixActions:  []string{},
+			FixCommands: [][]string{},
+		})
+	}
+
+	excluded, err := a.excludedTrackedRepos(machine, allowed)
+	if err != nil {
+		return 2, err
+	}
+	for _, repo := range excluded {
+		report.Findings = append(report.Findings, doctorJSONFinding{
+			Kind:        doctorFindingExcludedTrackedRepo,
+			Severity:    doctorSeverityWarning,
+			Message:     excludedTrackedRepoMessage(repo),
+			Remediation: excludedTrackedRepoRemediation,
+			RepoKey:     repo.RepoKey,
+			Path:        repo.Path,
+			Catalog:     repo.Catalog,
+			FixActions:  []string{},
+			FixCommands: [][]string{},
+		})
+	}
+
+	report.OK = true
+	for _, finding := range report.Findings {
+		if finding.Severity != doctorSeverityInfo {
+			report.OK = false
+			break
+		}
+	}
+	encoded, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return 2, fmt.Errorf("encode doctor json: %w", err)
+	}
+	if _, err := fmt.Fprintln(a.Stdout, string(encoded)); err != nil {
+		return 2, err
+	}
+	a.logf("doctor: reported %d finding(s)", len(report.Findings))
+	if unsyncable {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// doctorFixSuggestions returns the actions `bb fix` would offer for rec in a
+// non-interactive run, together with ready-to-run argv for each.
+func (a *App) doctorFixSuggestions(rec domain.MachineRepoRecord, meta *domain.RepoMetadataFile) ([]string, [][]string) {
+	rec, feasibility := a.enrichFixSyncFeasibility(rec)
+	risk, err := collectFixRiskSnapshot(rec.Path, a.Git)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		a.logf("doctor: risk scan failed for %s: %v", rec.Path, err)
+	}
+	actions := eligibleFixActions(rec, meta, fixEligibilityContext{
+		Interactive:     false,
+		Risk:            risk,
+		SyncStrategy:    normalizeFixSyncStrategy(""),
+		SyncFeasibility: feasibility,
+	})
+
+	target := rec.RepoKey
+	if target == "" {
+		target = rec.Path
+	}
+	commands := make([][]string, 0, len(actions))
+	for _, action := range actions {
+		commands = append(commands, []string{"bb", "fix", target, action})
+	}
+	return nonNilStrings(actions), commands
+}
+
+func joinUnsyncableReasons(reasons []domain.UnsyncableReason) string {
+	parts := make([]string, 0, len(reasons))
+	for _, reason := range reasons {
+		parts = append(parts, string(reason))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,285 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"bb-project/internal/domain"
+	"bb-project/internal/state"
+)
+
+func TestRunDoctorJSONReportsFindingsWithFixActions(t *testing.T) {
+	home := t.TempDir()
+	paths := state.NewPaths(home)
+	now := time.Date(2026, 2, 15, 12, 0, 0, 0, time.UTC)
+	t.Setenv("BB_MACHINE_ID", "machine-a")
+
+	cfg := state.DefaultConfig()
+	cfg.Sync.ScanFreshnessSeconds = 300
+	if err := state.SaveConfig(paths, cfg); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	machine := state.BootstrapMachine("machine-a", "host-a", now)
+	machine.LastScanAt = now
+	machine.Catalogs = []domain.Catalog{{Name: "software", Root: filepath.Join(home, "software")}}
+	machine.DefaultCatalog = "software"
+	machine.LastScanCatalogs = []string{"software"}
+	machine.Repos = []domain.MachineRepoRecord{
+		{
+			RepoKey:           "software/api",
+			Name:              "api",
+			Catalog:           "software",
+			Path:              filepath.Join(home, "software", "api"),
+			OriginURL:         "git@example.com:you/api.git",
+			Branch:            "main",
+			Upstream:          "origin/main",
+			Ahead:             1,
+			UnsyncableReasons: []domain.UnsyncableReason{domain.ReasonPushPolicyBlocked},
+			ObservedAt:        now,
+		},
+		{RepoKey: "software/ok", Name: "ok", Catalog: "software", Syncable: true},
+	}
+	if err := state.SaveMachine(paths, machine); err != nil {
+		t.Fatalf("save machine: %v", err)
+	}
+	if err := state.SaveRepoMetadata(paths, domain.RepoMetadataFile{
+		Version:    1,
+		RepoKey:    "software/api",
+		Name:       "api",
+		AutoPush:   domain.AutoPushModeDisabled,
+		PushAccess: domain.PushAccessReadWrite,
+	}); err != nil {
+		t.Fatalf("save repo metadata: %v", err)
+	}
+	if err := state.SaveNotifyCache(paths, domain.NotifyCacheFile{
+		Version:  1,
+		LastSent: map[string]domain.NotifyCacheEntry{},
+		DeliveryFailures: map[string]domain.NotifyDeliveryFailure{
+			"stdout|repo_key:software/api": {
+				Backend:  notifyBackendStdout,
+				RepoKey:  "software/api",
+				Error:    "mock failure",
+				FailedAt: now,
+			},
+		},
+	}); err != nil {
+		t.Fatalf("save notify cache: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	a := New(paths, &stdout, &bytes.Buffer{})
+	a.Now = func() time.Time { return now }
+	a.Hostname = func() (string, error) { return "host-a", nil }
+
+	code, err := a.RunDoctor(DoctorOptions{JSON: true})
+	if err != nil {
+		t.Fatalf("RunDoctor failed: %v", err)
+	}
+	if code != 1 {
+		t.Fatalf("exit code = %d, want 1", code)
+	}
+
+	var report doctorJSONReport
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("decode doctor json: %v\n%s", err, stdout.String())
+	}
+	if report.SchemaVersion != doctorJSONSchemaVersion || report.MachineID != "machine-a" || report.OK {
+		t.Fatalf("unexpected report header: %#v", report)
+	}
+	if len(report.Findings) != 2 {
+		t.Fatalf("findings = %d, want 2:\n%s", len(report.Findings), stdout.String())
+	}
+
+	repo := report.Findings[0]
+	if repo.Kind != doctorFindingUnsyncableRepo || repo.Severity != doctorSeverityError || repo.RepoKey != "software/api" {
+		t.Fatalf("unexpected repo finding: %#v", repo)
+	}
+	if !reflect.DeepEqual(repo.Reasons, []domain.UnsyncableReason{domain.ReasonPushPolicyBlocked}) {
+		t.Fatalf("reasons = %v", repo.Reasons)
+	}
+	if !reflect.DeepEqual(repo.FixActions, []string{FixActionPush, FixActionEnableAutoPush}) {
+		t.Fatalf("fix_actions = %v, want [%s %s]", repo.FixActions, FixActionPush, FixActionEnableAutoPush)
+	}
+	if !reflect.DeepEqual(repo.FixCommands, [][]string{
+		{"bb", "fix", "software/api", FixActionPush},
+		{"bb", "fix", "software/api", FixActionEnableAutoPush},
+	}) {
+		t.Fatalf("fix_commands = %v", repo.FixCommands)
+	}
+
+	notify := report.Findings[1]
+	if notify.Kind != doctorFindingNotifyDeliveryFailure || notify.Backend != notifyBackendStdout || notify.Error != "mock failure" {
+		t.Fatalf("unexpected notify finding: %#v", notify)
+	}
+	if notify.FailedAt == nil || !notify.FailedAt.Equal(now) {
+		t.Fatalf("failed_at = %v, want %v", notify.FailedAt, now)
+	}
+	if !reflect.DeepEqual(notify.FixActions, repo.FixActions) {
+		t.Fatalf("notify fix_actions = %v, want %v", notify.FixActions, repo.FixActions)
+	}
+}
+
+func TestRunDoctorJSONReportsGitHubCLIWarning(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 16, 12, 0, 0, 0, time.UTC)
+	a, stdout := newDoctorGitHubPrereqTestApp(t, now, "you", nil)
+	a.LookPath = func(file string) (string, error) {
+		if file == "gh" {
+			return "", errors.New("not found")
+		}
+		return "/usr/bin/" + file, nil
+	}
+
+	code, err := a.RunDoctor(DoctorOptions{JSON: true})
+	if err != nil {
+		t.Fatalf("RunDoctor failed: %v", err)
+	}
+	if code != 0 {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+	var report doctorJSONReport
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("decode doctor json: %v\n%s", err, stdout.String())
+	}
+	if report.OK || len(report.Findings) != 1 {
+		t.Fatalf("unexpected report: %#v", report)
+	}
+	finding := report.Findings[0]
+	if finding.Kind != doctorFindingGitHubCLI || finding.Severity != doctorSeverityWarning {
+		t.Fatalf("unexpected finding: %#v", finding)
+	}
+	if finding.Remediation == "" || finding.FixActions == nil || len(finding.FixActions) != 0 {
+		t.Fatalf("unexpected remediation/fix actions: %#v", finding)
+	}
+}
+
+func TestDoctorJSONSchemaMatchesStructs(t *testing.T) {
+	t.Parallel()
+
+	assertJSONSchemaMatchesStructs(t, "bb-doctor.v1.schema.json", map[string]reflect.Type{
+		"":        reflect.TypeOf(doctorJSONReport{}),
+		"finding": reflect.TypeOf(doctorJSONFinding{}),
+	})
+}
+
+func TestRunDoctorJSONReportsExcludedTrackedRepos(t *testing.T) {
+	home := t.TempDir()
+	paths := state.NewPaths(home)
+	now := time.Date(2026, 2, 15, 12, 0, 0, 0, time.UTC)
+	t.Setenv("BB_MACHINE_ID", "machine-a")
+
+	cfg := state.DefaultConfig()
+	cfg.Sync.ScanFreshnessSeconds = 300
+	if err := state.SaveConfig(paths, cfg); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	softwareRoot := filepath.Join(home, "software")
+	machine := state.BootstrapMachine("machine-a", "host-a", now)
+	machine.LastScanAt = now
+	machine.Catalogs = []domain.Catalog{{Name: "software", Root: softwareRoot, Exclude: []string{"scratch"}}}
+	machine.DefaultCatalog = "software"
+	machine.LastScanCatalogs = []string{"software"}
+	if err := state.SaveMachine(paths, machine); err != nil {
+		t.Fatalf("save machine: %v", err)
+	}
+	for _, repoKey := range []string{"software/api", "software/scratch"} {
+		if err := state.SaveRepoMetadata(paths, domain.RepoMetadataFile{Version: 1, RepoKey: repoKey, Name: filepath.Base(repoKey)}); err != nil {
+			t.Fatalf("save repo metadata: %v", err)
+		}
+	}
+
+	var stdout bytes.Buffer
+	a := New(paths, &stdout, &bytes.Buffer{})
+	a.Now = func() time.Time { return now }
+	a.Hostname = func() (string, error) { return "host-a", nil }
+
+	code, err := a.RunDoctor(DoctorOptions{JSON: true})
+	if err != nil {
+		t.Fatalf("RunDoctor failed: %v", err)
+	}
+	if code != 0 {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+	var report doctorJSONReport
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("decode doctor json: %v\n%s", err, stdout.String())
+	}
+	if report.OK || len(report.Findings) != 1 {
+		t.Fatalf("unexpected report: %s", stdout.String())
+	}
+	finding := report.Findings[0]
+	if finding.Kind != doctorFindingExcludedTrackedRepo || finding.Severity != doctorSeverityWarning ||
+		finding.RepoKey != "software/scratch" || finding.Path != filepath.Join(softwareRoot, "scratch") {
+		t.Fatalf("unexpected finding: %#v", finding)
+	}
+
+	stdout.Reset()
+	if code, err := a.RunDoctor(DoctorOptions{}); err != nil || code != 0 {
+		t.Fatalf("RunDoctor text = %d, %v", code, err)
+	}
+	if !bytes.Contains(stdout.Bytes(), []byte("software/scratch is excluded but tracked")) {
+		t.Fatalf("doctor output missing excluded repo:\n%s", stdout.String())
+	}
+}
+
+func TestRunDoctorReportsStateFilesReadFromBackup(t *testing.T) {
+	home := t.TempDir()
+	paths := state.NewPaths(home)
+	now := time.Date(2026, 2, 15, 12, 0, 0, 0, time.UTC)
+	t.Setenv("BB_MACHINE_ID", "machine-a")
+
+	cfg := state.DefaultConfig()
+	cfg.Sync.ScanFreshnessSeconds = 300
+	if err := state.SaveConfig(paths, cfg); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	machine := state.BootstrapMachine("machine-a", "host-a", now)
+	machine.LastScanAt = now
+	if err := state.SaveMachine(paths, machine); err != nil {
+		t.Fatalf("save machine: %v", err)
+	}
+	for _, name := range []string{"api", "api-renamed"} {
+		if err := state.SaveRepoMetadata(paths, domain.RepoMetadataFile{Version: 1, RepoKey: "software/api", Name: name}); err != nil {
+			t.Fatalf("save repo metadata: %v", err)
+		}
+	}
+	if err := os.WriteFile(state.RepoMetaPath(paths, "software/api"), []byte("repo_key: [\n"), 0o644); err != nil {
+		t.Fatalf("corrupt repo metadata: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	a := New(paths, &stdout, &bytes.Buffer{})
+	a.Now = func() time.Time { return now }
+	a.Hostname = func() (string, error) { return "host-a", nil }
+
+	if code, err := a.RunDoctor(DoctorOptions{JSON: true}); err != nil || code != 0 {
+		t.Fatalf("RunDoctor = %d, %v", code, err)
+	}
+	var report doctorJSONReport
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("decode doctor json: %v\n%s", err, stdout.String())
+	}
+	if report.OK || len(report.Findings) != 1 {
+		t.Fatalf("unexpected report: %s", stdout.String())
+	}
+	finding := report.Findings[0]
+	if finding.Kind != doctorFindingStateBackupFallback || finding.Target != "repos/software__api.yaml" || finding.BackupID == "" {
+		t.Fatalf("unexpected finding: %#v", finding)
+	}
+
+	stdout.Reset()
+	if code, err := a.RunDoctor(DoctorOptions{}); err != nil || code != 0 {
+		t.Fatalf("RunDoctor text = %d, %v", code, err)
+	}
+	if !bytes.Contains(stdout.Bytes(), []byte("warning: repos/software__api.yaml does not parse; bb is reading backup")) {
+		t.Fatalf("doctor output missing backup fallback:\n%s", stdout.String())
+	}
+}
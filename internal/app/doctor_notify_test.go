@@ -49,7 +49,7 @@ func TestRunDoctorReportsNotifyDeliveryWarning(t *testing.T) {
 	a.Now = func() time.Time { return now }
 	a.Hostname = func() (string, error) { return "host-a", nil }
 
-	code, err := a.RunDoctor(nil)
+	code, err := a.RunDoctor(DoctorOptions{})
 	if err != nil {
 		t.Fatalf("RunDoctor failed: %v", err)
 	}
@@ -99,6 +99,9 @@ func (e *fixIneligibleError) Unwrap() error {
 }
 
 func (a *App) runFix(opts FixOptions) (int, error) {
+	if opts.All {
+		return a.runFixAll(opts)
+	}
 	if strings.TrimSpace(opts.Project) == "" && strings.TrimSpace(opts.Action) == "" {
 		if opts.AIMessage {
 			return 2, errors.New("--ai-message requires an explicit action")
@@ -250,6 +253,9 @@ func (a *App) renderFixStatus(rec domain.MachineRepoRecord, actions []string) {
 		sort.Strings(parts)
 		fmt.Fprintf(a.Stdout, "reasons: %s\n", strings.Join(parts, ", "))
 	}
+	for _, wt := range rec.Worktrees {
+		fmt.Fprintf(a.Stdout, "worktree: %s\n", describeWorktree(wt))
+	}
 	if len(actions) == 0 {
 		fmt.Fprintln(a.Stdout, "actions: none")
 		return
@@ -257,6 +263,15 @@ func (a *App) renderFixStatus(rec domain.MachineRepoRecord, actions []string) {
 	fmt.Fprintf(a.Stdout, "actions: %s\n", strings.Join(actions, ", "))
 }
 
+// describeWorktree summarizes a linked worktree on one line for fix and info.
+func describeWorktree(wt domain.WorktreeRecord) string {
+	out := fmt.Sprintf("%s branch=%s dirty_tracked=%t untracked=%t", wt.Path, valueOrDash(wt.Branch), wt.HasDirtyTracked, wt.HasUntracked)
+	if wt.OperationInProgress != "" && wt.OperationInProgress != domain.OperationNone {
+		out += fmt.Sprintf(" operation=%s", wt.OperationInProgress)
+	}
+	return out
+}
+
 func containsAction(actions []string, action string) bool {
 	for _, candidate := range actions {
 		if candidate == action {
@@ -607,7 +622,7 @@ func (a *App) loadFixRepos(includeCatalogs []string, refreshMode scanRefreshMode
 		return out[i].Record.Path < out[j].Record.Path
 	})
 
-	pushAccessUpdated, err := a.refreshUnknownPushAccessForFixReposLocked(out)
+	pushAccessUpdated, err := a.refreshUnknownPushAccessForFixReposLocked(cfg, out)
 	if err != nil {
 		return nil, err
 	}
@@ -696,7 +711,7 @@ func (a *App) loadFixTargetRepoLocked(includeCatalogs []string, refreshMode scan
 	}
 	target.Risk = risk
 
-	pushAccessUpdated, err := a.refreshUnknownPushAccessForFixReposLocked([]fixRepoState{target})
+	pushAccessUpdated, err := a.refreshUnknownPushAccessForFixReposLocked(cfg, []fixRepoState{target})
 	if err != nil {
 		return fixRepoState{}, err
 	}
@@ -712,7 +727,7 @@ func (a *App) loadFixTargetRepoLocked(includeCatalogs []string, refreshMode scan
 	return target, nil
 }
 
-func (a *App) refreshUnknownPushAccessForFixRepos(repos []fixRepoState) (bool, error) {
+func (a *App) refreshUnknownPushAccessForFixRepos(cfg domain.ConfigFile, repos []fixRepoState) (bool, error) {
 	a.logf("fix: acquiring global lock for unknown push-access refresh")
 	lock, err := state.AcquireLock(a.Paths)
 	if err != nil {
@@ -722,10 +737,10 @@ func (a *App) refreshUnknownPushAccessForFixRepos(repos []fixRepoState) (bool, e
 		_ = lock.Release()
 		a.logf("fix: released global lock for unknown push-access refresh")
 	}()
-	return a.refreshUnknownPushAccessForFixReposLocked(repos)
+	return a.refreshUnknownPushAccessForFixReposLocked(cfg, repos)
 }
 
-func (a *App) refreshUnknownPushAccessForFixReposLocked(repos []fixRepoState) (bool, error) {
+func (a *App) refreshUnknownPushAccessForFixReposLocked(cfg domain.ConfigFile, repos []fixRepoState) (bool, error) {
 	type probeTarget struct {
 		repoKey   string
 		repoPath  string
@@ -782,7 +797,7 @@ func (a *App) refreshUnknownPushAccessForFixReposLocked(repos []fixRepoState) (b
 			continue
 		}
 
-		updated, probeChanged, err := a.probeAndUpdateRepoPushAccess(target.repoPath, target.originURL, meta, true)
+		updated, probeChanged, err := a.probeAndUpdateRepoPushAccess(cfg, target.repoPath, target.originURL, meta, true, a.logf)
 		if err != nil {
 			return false, err
 		}
@@ -1938,13 +1953,18 @@ func (a *App) executeFixAction(
 		if err != nil {
 			return err
 		}
-		winner, ok := selectWinnerForRepo(allMachines, target.Record.RepoKey)
+		// The clone does not exist yet, so there are no local objects to
+		// compare heads against.
+		selection, ok := selectWinnerForRepo(allMachines, target.Record.RepoKey, domain.WinnerPolicy{
+			PrimaryMachineID: target.Meta.PrimaryMachineID,
+		})
 		if !ok {
 			return &fixIneligibleError{
 				Action: action,
 				Reason: "clone is blocked: no syncable winner is available for this repository yet",
 			}
 		}
+		branch := reconcileBranch(*target.Meta, selection.Winner, "")
 
 		pathConflictReason, err := validateTargetPath(a.Git, path, target.Meta.OriginURL, preferredRemote)
 		if err != nil {
@@ -2008,9 +2028,9 @@ func (a *App) executeFixAction(
 		if err := runStep("clone-checkout-branch", fixActionPlanEntry{
 			ID:      "clone-checkout-branch",
 			Command: true,
-			Summary: fmt.Sprintf("git checkout %s", winner.Record.Branch),
+			Summary: fmt.Sprintf("git checkout %s", branch),
 		}, func() error {
-			return a.Git.EnsureBranchWithPreferredRemote(path, winner.Record.Branch, preferredRemote)
+			return a.Git.EnsureBranchWithPreferredRemote(path, branch, preferredRemote)
 		}); err != nil {
 			return err
 		}
@@ -2074,6 +2094,9 @@ func (a *App) buildFixActionPlanContext(cfg domain.ConfigFile, target fixRepoSta
 		GitHubOwner:                        owner,
 		RemoteProtocol:                     strings.TrimSpace(cfg.GitHub.RemoteProtocol),
 		GitHubRemoteURLTemplate:            strings.TrimSpace(cfg.GitHub.PreferredRemoteURLTemplate),
+		ForgeHost:                          defaultForgeHost(cfg),
+		CreateForgeKind:                    forgeKindForHost(cfg, defaultForgeHost(cfg)),
+		OriginForgeKind:                    originForgeKind(cfg, target.Record.OriginURL),
 		ForkRemoteExists:                   forkRemoteExists,
 		RepoName:                           strings.TrimSpace(target.Record.Name),
 		ExpectedRepoKey:                    strings.TrimSpace(target.Record.ExpectedRepoKey),
@@ -2181,14 +2204,14 @@ func (a *App) forkAndRetargetFromFix(
 	if err := runStep("fork-gh-fork", fixActionPlanEntry{
 		ID:      "fork-gh-fork",
 		Command: true,
-		Summary: fmt.Sprintf("gh repo fork %s --remote=false --clone=false", forkSource),
+		Summary: plannedForkSummary(originForgeKind(cfg, originURL), forkSource),
 	}, func() error {
 		var err error
 		forkURL, err = a.ensureForkRemoteRepo(
+			cfg,
 			originURL,
 			owner,
 			cfg.GitHub.RemoteProtocol,
-			cfg.GitHub.PreferredRemoteURLTemplate,
 			repoPath,
 		)
 		return err
@@ -2295,7 +2318,7 @@ func (a *App) forkAndRetargetFromFix(
 			return err
 		}
 
-		updated, _, err := a.probeAndUpdateRepoPushAccess(repoPath, forkURL, meta, true)
+		updated, _, err := a.probeAndUpdateRepoPushAccess(cfg, repoPath, forkURL, meta, true, a.logf)
 		if err != nil {
 			return err
 		}
@@ -2345,6 +2368,7 @@ func (a *App) createProjectFromFix(
 
 	visibility := resolveCreateProjectVisibility(cfg, opts.CreateProjectVisibility)
 	expectedOrigin, err := a.expectedOrigin(
+		defaultForgeHost(cfg),
 		owner,
 		projectName,
 		cfg.GitHub.RemoteProtocol,
@@ -2362,7 +2386,7 @@ func (a *App) createProjectFromFix(
 		markSkipped("create-gh-repo", fixActionPlanEntry{
 			ID:      "create-gh-repo",
 			Command: true,
-			Summary: fmt.Sprintf("gh repo create %s/%s %s", owner, projectName, plannedVisibilityFlag(visibility)),
+			Summary: plannedCreateRepoSummary(forgeKindForHost(cfg, defaultForgeHost(cfg)), owner, projectName, visibility),
 		})
 		if err := runStep("create-validate-origin", fixActionPlanEntry{
 			ID:      "create-validate-origin",
@@ -2385,10 +2409,11 @@ func (a *App) createProjectFromFix(
 		if err := runStep("create-gh-repo", fixActionPlanEntry{
 			ID:      "create-gh-repo",
 			Command: true,
-			Summary: fmt.Sprintf("gh repo create %s/%s %s", owner, projectName, plannedVisibilityFlag(visibility)),
+			Summary: plannedCreateRepoSummary(forgeKindForHost(cfg, defaultForgeHost(cfg)), owner, projectName, visibility),
 		}, func() error {
 			var err error
 			createdOrigin, err = a.createRemoteRepo(
+				cfg,
 				owner,
 				projectName,
 				visibility,
@@ -2405,7 +2430,7 @@ func (a *App) createProjectFromFix(
 			Command: true,
 			Summary: fmt.Sprintf(
 				"git remote add origin %s",
-				plannedOriginURL(owner, projectName, cfg.GitHub.RemoteProtocol, cfg.GitHub.PreferredRemoteURLTemplate),
+				plannedOriginURL(defaultForgeHost(cfg), owner, projectName, cfg.GitHub.RemoteProtocol, cfg.GitHub.PreferredRemoteURLTemplate),
 			),
 		}, func() error {
 			if err := a.Git.AddOrigin(target.Record.Path, createdOrigin); err != nil {
@@ -2428,7 +2453,7 @@ func (a *App) createProjectFromFix(
 		Summary: "Write/update repo metadata (origin URL, visibility, default auto-push policy).",
 	}, func() error {
 		var err error
-		meta, _, err = a.ensureRepoMetadata(cfg, target.Record.RepoKey, projectName, origin, visibility, target.Record.Catalog)
+		meta, _, err = a.ensureRepoMetadata(cfg, target.Record.RepoKey, projectName, origin, visibility, target.Record.Catalog, a.logf)
 		return err
 	}); err != nil {
 		return err
@@ -27,6 +27,9 @@ type fixActionPlanContext struct {
 	GitHubOwner                        string
 	RemoteProtocol                     string
 	GitHubRemoteURLTemplate            string
+	ForgeHost                          string // host new projects are created on; "" means github.com
+	CreateForgeKind                    string // forge type of ForgeHost; "" means github
+	OriginForgeKind                    string // forge type of the origin's host; "" means github
 	ForkRemoteExists                   bool
 	RepoName                           string
 	ExpectedRepoKey                    string
@@ -511,10 +514,10 @@ func planFixActionCreateProject(ctx fixActionPlanContext) []fixActionPlanEntry {
 	entries = append(entries, fixActionPlanEntry{
 		ID:      "create-gh-repo",
 		Command: true,
-		Summary: fmt.Sprintf("gh repo create %s/%s %s", owner, projectName, plannedVisibilityFlag(ctx.CreateProjectVisibility)),
+		Summary: plannedCreateRepoSummary(ctx.CreateForgeKind, owner, projectName, ctx.CreateProjectVisibility),
 	})
 	if strings.TrimSpace(ctx.OriginURL) == "" {
-		originURL := plannedOriginURL(ctx.GitHubOwner, projectName, ctx.RemoteProtocol, ctx.GitHubRemoteURLTemplate)
+		originURL := plannedOriginURL(ctx.ForgeHost, ctx.GitHubOwner, projectName, ctx.RemoteProtocol, ctx.GitHubRemoteURLTemplate)
 		if strings.TrimSpace(originURL) == "" {
 			return append(entries, fixActionPlanEntry{
 				ID:      "create-add-origin",
@@ -591,11 +594,11 @@ func planFixActionForkAndRetarget(ctx fixActionPlanContext) []fixActionPlanEntry
 			{
 				ID:      "fork-source-invalid",
 				Command: false,
-				Summary: "Cannot derive GitHub source repository from origin URL.",
+				Summary: "Cannot derive source repository from origin URL.",
 			},
 		}
 	}
-	forkURL := plannedForkURL(ctx.OriginURL, ctx.GitHubOwner, ctx.RemoteProtocol, ctx.GitHubRemoteURLTemplate)
+	forkURL := plannedForkURL(ctx.OriginURL, ctx.GitHubOwner, ctx.RemoteProtocol, ctx.GitHubRemoteURLTemplate, ctx.ForgeHost)
 	if forkURL == "" {
 		return []fixActionPlanEntry{
 			{
@@ -613,7 +616,7 @@ func planFixActionForkAndRetarget(ctx fixActionPlanContext) []fixActionPlanEntry
 		{
 			ID:      "fork-gh-fork",
 			Command: true,
-			Summary: fmt.Sprintf("gh repo fork %s --remote=false --clone=false", source),
+			Summary: plannedForkSummary(ctx.OriginForgeKind, source),
 		},
 		{
 			ID:      "fork-set-remote",
@@ -764,13 +767,13 @@ func plannedProjectName(name string, repoName string) string {
 	return "repo"
 }
 
-func plannedOriginURL(owner string, projectName string, protocol string, template string) string {
+func plannedOriginURL(host string, owner string, projectName string, protocol string, template string) string {
 	owner = strings.TrimSpace(owner)
 	projectName = strings.TrimSpace(projectName)
 	if owner == "" || projectName == "" {
 		return ""
 	}
-	url, err := githubRemoteURL(owner, projectName, protocol, template)
+	url, err := forgeRemoteURL(plannedForgeHost(host), owner, projectName, protocol, template)
 	if err != nil {
 		return ""
 	}
@@ -791,24 +794,61 @@ func plannedVisibilityFlag(visibility domain.Visibility) string {
 	return "--private"
 }
 
+// plannedCreateRepoSummary describes creating owner/projectName on a forge;
+// GitHub shows the gh command that runs.
+func plannedCreateRepoSummary(kind string, owner string, projectName string, visibility domain.Visibility) string {
+	if kind == "" || kind == domain.ForgeTypeGitHub {
+		return fmt.Sprintf("gh repo create %s/%s %s", owner, projectName, plannedVisibilityFlag(visibility))
+	}
+	return fmt.Sprintf("Create %s repository %s/%s (%s) through the %s API.", kind, owner, projectName, plannedVisibility(visibility), kind)
+}
+
+// plannedForkSummary describes forking source on a forge; GitHub shows the gh
+// command that runs.
+func plannedForkSummary(kind string, source string) string {
+	if kind == "" || kind == domain.ForgeTypeGitHub {
+		return fmt.Sprintf("gh repo fork %s --remote=false --clone=false", source)
+	}
+	return fmt.Sprintf("Fork %s through the %s API (an existing fork is reused).", source, kind)
+}
+
+func plannedVisibility(visibility domain.Visibility) string {
+	if visibility == domain.VisibilityPublic {
+		return "public"
+	}
+	return "private"
+}
+
+func plannedForgeHost(host string) string {
+	if trimmed := strings.TrimSpace(host); trimmed != "" {
+		return trimmed
+	}
+	return githubHost
+}
+
 func plannedForkSource(originURL string) string {
-	sourceOwner, repoName, err := sourceRepoForFork(originURL)
-	if err != nil {
+	_, sourceOwner, repoName, ok := forgeRepoForOrigin(originURL)
+	if !ok {
 		return ""
 	}
 	return fmt.Sprintf("%s/%s", sourceOwner, repoName)
 }
 
-func plannedForkURL(originURL string, forkOwner string, protocol string, template string) string {
+// plannedForkURL is the fork's remote URL on the origin's host. template
+// only applies when that host is defaultHost.
+func plannedForkURL(originURL string, forkOwner string, protocol string, template string, defaultHost string) string {
 	forkOwner = strings.TrimSpace(forkOwner)
 	if forkOwner == "" {
 		return ""
 	}
-	_, repoName, err := sourceRepoForFork(originURL)
-	if err != nil {
+	host, _, repoName, ok := forgeRepoForOrigin(originURL)
+	if !ok {
 		return ""
 	}
-	url, err := githubRemoteURL(forkOwner, repoName, protocol, template)
+	if host != plannedForgeHost(defaultHost) {
+		template = ""
+	}
+	url, err := forgeRemoteURL(host, forkOwner, repoName, protocol, template)
 	if err != nil {
 		return ""
 	}
@@ -816,8 +856,8 @@ func plannedForkURL(originURL string, forkOwner string, protocol string, templat
 }
 
 func plannedPreferredOriginForExisting(originURL string, protocol string, template string) string {
-	owner, repo, err := sourceRepoForFork(originURL)
-	if err != nil {
+	owner, repo, ok := githubSourceRepoForOrigin(originURL)
+	if !ok {
 		return ""
 	}
 	url, err := githubRemoteURL(owner, repo, protocol, template)
@@ -265,6 +265,28 @@ func TestFixActionPlanForkAndRetargetWritesMetadataBeforeForcePush(t *testing.T)
 	}
 }
 
+func TestFixActionPlanForkAndRetargetUsesOriginForge(t *testing.T) {
+	t.Parallel()
+
+	plan := fixActionPlanFor(FixActionForkAndRetarget, fixActionPlanContext{
+		Branch:                  "main",
+		GitHubOwner:             "you",
+		RemoteProtocol:          "https",
+		GitHubRemoteURLTemplate: "git@${org}.github.com:${org}/${repo}.git",
+		OriginURL:               "git@git.example.com:team/demo.git",
+		OriginForgeKind:         domain.ForgeTypeGitea,
+	})
+
+	forkCmd := plan[planEntryIndex(plan, "fork-gh-fork")].Summary
+	if forkCmd != "Fork team/demo through the gitea API (an existing fork is reused)." {
+		t.Fatalf("unexpected fork summary = %q", forkCmd)
+	}
+	setRemoteCmd := plan[planEntryIndex(plan, "fork-set-remote")].Summary
+	if setRemoteCmd != "git remote add you https://git.example.com/you/demo.git" {
+		t.Fatalf("unexpected set-remote summary = %q", setRemoteCmd)
+	}
+}
+
 func TestFixActionPlanForkAndRetargetRendersConcreteForkCommands(t *testing.T) {
 	t.Parallel()
 
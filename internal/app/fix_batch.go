@@ -0,0 +1,178 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"bb-project/internal/gitx"
+	"bb-project/internal/state"
+)
+
+type fixBatchOutcome string
+
+const (
+	fixBatchPlanned fixBatchOutcome = "planned"
+	fixBatchApplied fixBatchOutcome = "applied"
+	fixBatchSkipped fixBatchOutcome = "skipped"
+	fixBatchFailed  fixBatchOutcome = "failed"
+)
+
+type fixBatchResult struct {
+	Repo    fixRepoState
+	Outcome fixBatchOutcome
+	Detail  string
+}
+
+// runFixAll applies one action to every repo in the selected catalogs where
+// eligibleFixActions offers it. Every eligible repo's execution plan is
+// printed before anything runs; --dry-run stops after the preview.
+func (a *App) runFixAll(opts FixOptions) (int, error) {
+	action := strings.TrimSpace(opts.Action)
+	if action == "" {
+		return 2, errors.New("--all requires --action")
+	}
+	if _, ok := fixActionSpecFor(action); !ok {
+		return 2, fmt.Errorf("unknown fix action %q", action)
+	}
+	if action == FixActionIgnore {
+		return 2, errors.New("ignore action is interactive-only; use `bb fix`")
+	}
+	if opts.AIMessage && !isCommitProducingFixAction(action) {
+		return 2, errors.New("--ai-message is only supported for stage-commit-push, publish-new-branch, and checkpoint-then-sync")
+	}
+	if opts.AIMessage && opts.DryRun {
+		return 2, errors.New("--ai-message and --dry-run are mutually exclusive")
+	}
+
+	refreshMode := scanRefreshIfStale
+	if opts.NoRefresh {
+		refreshMode = scanRefreshNever
+	}
+	repos, err := a.loadFixRepos(opts.IncludeCatalogs, refreshMode)
+	if err != nil {
+		return 2, err
+	}
+	cfg, err := state.LoadConfig(a.Paths)
+	if err != nil {
+		return 2, err
+	}
+
+	allowed := make(map[string]struct{}, len(opts.IncludeCatalogs))
+	for _, name := range opts.IncludeCatalogs {
+		allowed[strings.TrimSpace(name)] = struct{}{}
+	}
+	strategy := normalizeFixSyncStrategy(opts.SyncStrategy)
+	applyOpts := fixApplyOptions{
+		Interactive:                   false,
+		CommitMessage:                 opts.CommitMessage,
+		ForkBranchRenameTo:            opts.PublishBranch,
+		ReturnToOriginalBranchAndSync: opts.ReturnToOriginalBranchAndSync,
+		SyncStrategy:                  strategy,
+	}
+
+	results := make([]fixBatchResult, 0, len(repos))
+	planned := 0
+	for _, repo := range repos {
+		if len(allowed) > 0 {
+			if _, ok := allowed[repo.Record.Catalog]; !ok {
+				continue
+			}
+		}
+		eligibility := fixEligibilityContext{
+			Interactive:     false,
+			Risk:            repo.Risk,
+			SyncStrategy:    strategy,
+			SyncFeasibility: repo.SyncFeasibility,
+		}
+		if !containsAction(eligibleFixActions(repo.Record, repo.Meta, eligibility), action) {
+			detail := ineligibleFixReason(action, repo.Record, eligibility)
+			if detail == "" {
+				detail = "action not eligible"
+			}
+			results = append(results, fixBatchResult{Repo: repo, Outcome: fixBatchSkipped, Detail: detail})
+			continue
+		}
+
+		planned++
+		fmt.Fprintf(a.Stdout, "plan: %s %s (%s)\n", action, repo.Record.Name, repo.Record.Path)
+		for _, entry := range fixActionExecutionPlanFor(action, a.buildFixActionPlanContext(cfg, repo, applyOpts)) {
+			if entry.Command {
+				fmt.Fprintf(a.Stdout, "  $ %s\n", entry.Summary)
+				continue
+			}
+			fmt.Fprintf(a.Stdout, "  - %s\n", entry.Summary)
+		}
+		results = append(results, fixBatchResult{Repo: repo, Outcome: fixBatchPlanned})
+	}
+	if planned == 0 {
+		fmt.Fprintf(a.Stdout, "no repositories eligible for %s\n", action)
+	}
+
+	if !opts.DryRun {
+		originalGitRunner := a.Git
+		a.Git = a.Git.WithIOMode(gitx.GitIOModeAttached)
+		defer func() {
+			a.Git = originalGitRunner
+		}()
+
+		for i := range results {
+			if results[i].Outcome != fixBatchPlanned {
+				continue
+			}
+			results[i].Outcome, results[i].Detail = a.applyFixAllRepo(opts, results[i].Repo, action, applyOpts)
+		}
+	}
+
+	return a.reportFixAllResults(action, results, opts.DryRun), nil
+}
+
+func (a *App) applyFixAllRepo(opts FixOptions, repo fixRepoState, action string, applyOpts fixApplyOptions) (fixBatchOutcome, string) {
+	if opts.AIMessage {
+		message, err := a.generateLumenCommitMessage(repo.Record.Path)
+		if err != nil {
+			return fixBatchFailed, err.Error()
+		}
+		applyOpts.CommitMessage = message
+	}
+
+	updated, err := a.applyFixAction(opts.IncludeCatalogs, repo.Record.Path, action, applyOpts)
+	if errors.Is(err, errFixActionNotEligible) {
+		detail := "action no longer eligible"
+		var ineligibleErr *fixIneligibleError
+		if errors.As(err, &ineligibleErr) && strings.TrimSpace(ineligibleErr.Reason) != "" {
+			detail = ineligibleErr.Reason
+		}
+		return fixBatchSkipped, detail
+	}
+	if err != nil {
+		return fixBatchFailed, err.Error()
+	}
+	if !updated.Record.Syncable {
+		return fixBatchApplied, fmt.Sprintf("still unsyncable: %s", joinUnsyncableReasons(updated.Record.UnsyncableReasons))
+	}
+	return fixBatchApplied, ""
+}
+
+// reportFixAllResults prints one line per repo plus totals and returns 1 when
+// any repo failed.
+func (a *App) reportFixAllResults(action string, results []fixBatchResult, dryRun bool) int {
+	counts := map[fixBatchOutcome]int{}
+	for _, result := range results {
+		counts[result.Outcome]++
+		line := fmt.Sprintf("%s %s", result.Outcome, result.Repo.Record.Name)
+		if result.Detail != "" {
+			line += ": " + result.Detail
+		}
+		fmt.Fprintln(a.Stdout, line)
+	}
+	if dryRun {
+		fmt.Fprintf(a.Stdout, "dry run: %s would apply to %d repo(s), skipped=%d\n", action, counts[fixBatchPlanned], counts[fixBatchSkipped])
+		return 0
+	}
+	fmt.Fprintf(a.Stdout, "summary: %s applied=%d skipped=%d failed=%d\n", action, counts[fixBatchApplied], counts[fixBatchSkipped], counts[fixBatchFailed])
+	if counts[fixBatchFailed] > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,131 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"bb-project/internal/domain"
+	"bb-project/internal/state"
+)
+
+func newFixBatchTestApp(t *testing.T) (*App, state.Paths, *bytes.Buffer) {
+	t.Helper()
+
+	now := time.Date(2026, time.February, 17, 9, 0, 0, 0, time.UTC)
+	app, paths := newFixApplyTestApp(t, now)
+	var stdout bytes.Buffer
+	app.Stdout = &stdout
+	if err := state.SaveConfig(paths, state.DefaultConfig()); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	catalogRoot := filepath.Join(paths.Home, "catalog")
+	records := make([]domain.MachineRepoRecord, 0, 3)
+	for _, name := range []string{"api", "docs", "web"} {
+		repoPath := filepath.Join(catalogRoot, name)
+		if err := os.MkdirAll(repoPath, 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", repoPath, err)
+		}
+		records = append(records, fixApplyTestRepoRecord("software/"+name, name, "software", repoPath, "https://github.com/you/"+name+".git"))
+	}
+	writeFixApplyTestMachine(t, paths, now, catalogRoot, records...)
+	// docs has no metadata, so enable-auto-push is not offered for it.
+	writeFixApplyTestMetadata(t, paths, "software/api", "api", records[0].OriginURL)
+	writeFixApplyTestMetadata(t, paths, "software/web", "web", records[2].OriginURL)
+
+	app.observeRepoHook = func(_ domain.ConfigFile, repo discoveredRepo, _ bool) (domain.MachineRepoRecord, error) {
+		for _, rec := range records {
+			if filepath.Clean(rec.Path) == filepath.Clean(repo.Path) {
+				if rec.Name == "web" {
+					return domain.MachineRepoRecord{}, errors.New("observe failed")
+				}
+				return rec, nil
+			}
+		}
+		return domain.MachineRepoRecord{}, errors.New("unexpected repo")
+	}
+	return app, paths, &stdout
+}
+
+func TestRunFixAllDryRunPrintsPlanWithoutApplying(t *testing.T) {
+	t.Parallel()
+
+	app, paths, stdout := newFixBatchTestApp(t)
+
+	code, err := app.runFix(FixOptions{All: true, Action: FixActionEnableAutoPush, DryRun: true})
+	if err != nil {
+		t.Fatalf("runFix failed: %v", err)
+	}
+	if code != 0 {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+
+	out := stdout.String()
+	for _, want := range []string{
+		"plan: enable-auto-push api",
+		"plan: enable-auto-push web",
+		"  - Revalidate repository status and syncability state.",
+		"planned api",
+		"skipped docs: ",
+		"dry run: enable-auto-push would apply to 2 repo(s), skipped=1",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+	meta, err := state.LoadRepoMetadata(paths, "software/api")
+	if err != nil {
+		t.Fatalf("load metadata: %v", err)
+	}
+	if meta.AutoPush != domain.AutoPushModeDisabled {
+		t.Fatalf("dry run changed auto_push to %q", meta.AutoPush)
+	}
+}
+
+func TestRunFixAllReportsPerRepoOutcomesAndFailsOnError(t *testing.T) {
+	t.Parallel()
+
+	app, paths, stdout := newFixBatchTestApp(t)
+
+	code, err := app.runFix(FixOptions{All: true, Action: FixActionEnableAutoPush})
+	if err != nil {
+		t.Fatalf("runFix failed: %v", err)
+	}
+	if code != 1 {
+		t.Fatalf("exit code = %d, want 1 when a repo fails", code)
+	}
+
+	out := stdout.String()
+	for _, want := range []string{
+		"applied api",
+		"skipped docs: ",
+		"failed web: observe failed",
+		"summary: enable-auto-push applied=1 skipped=1 failed=1",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+	meta, err := state.LoadRepoMetadata(paths, "software/api")
+	if err != nil {
+		t.Fatalf("load metadata: %v", err)
+	}
+	if meta.AutoPush != domain.AutoPushModeIncludeDefaultBranch {
+		t.Fatalf("auto_push = %q, want %q", meta.AutoPush, domain.AutoPushModeIncludeDefaultBranch)
+	}
+}
+
+func TestRunFixAllRejectsUnknownAction(t *testing.T) {
+	t.Parallel()
+
+	app, _, _ := newFixBatchTestApp(t)
+	code, err := app.runFix(FixOptions{All: true, Action: "nope"})
+	if code != 2 || err == nil || !strings.Contains(err.Error(), `unknown fix action "nope"`) {
+		t.Fatalf("runFix = (%d, %v), want unknown action usage error", code, err)
+	}
+}
@@ -0,0 +1,367 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"bb-project/internal/domain"
+)
+
+const (
+	githubHost = "github.com"
+
+	defaultForgeAPITimeout = 30 * time.Second
+
+	// forgeAPIMaxAttempts bounds how often a rate-limited request is sent.
+	forgeAPIMaxAttempts = 3
+	// forgeAPIMaxRateLimitWait is the longest bb sleeps for a rate limit to
+	// reset; longer waits fail the request instead.
+	forgeAPIMaxRateLimitWait = 60 * time.Second
+	// forgeAPISecondaryRateLimitWait is used when a secondary (abuse) limit
+	// response carries no Retry-After header.
+	forgeAPISecondaryRateLimitWait = 60 * time.Second
+)
+
+// Forge is the hosting API bb uses to create, fork and inspect repositories
+// on one git host. Owners may contain slashes where the forge has nested
+// namespaces (GitLab subgroups).
+type Forge interface {
+	// Kind returns the forge type, one of the domain.ForgeType* values.
+	Kind() string
+	// CreateRepo creates owner/repo. repoPath is the local repository it is
+	// created for.
+	CreateRepo(owner string, repo string, visibility domain.Visibility, repoPath string) error
+	// Fork forks owner/repo into forkOwner's namespace. An existing fork is
+	// not an error.
+	Fork(owner string, repo string, forkOwner string, repoPath string) error
+	// PushAccess reports whether the authenticated user can push to
+	// owner/repo.
+	PushAccess(owner string, repo string) (domain.PushAccess, error)
+	// Visibility reports whether owner/repo is public or private. Internal
+	// repositories count as private.
+	Visibility(owner string, repo string) (domain.Visibility, error)
+}
+
+// normalizeForgeHost lower-cases host, drops any port and maps GitHub SSH
+// host aliases such as you.github.com to github.com.
+func normalizeForgeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	host, _, _ = strings.Cut(host, ":")
+	if strings.HasSuffix(host, "."+githubHost) {
+		return githubHost
+	}
+	return host
+}
+
+// defaultForgeHost returns the host new repositories are created on.
+func defaultForgeHost(cfg domain.ConfigFile) string {
+	if host := normalizeForgeHost(cfg.GitHub.Host); host != "" {
+		return host
+	}
+	return githubHost
+}
+
+// forgeConfigForHost returns the forge settings for host. github.com is a
+// GitHub forge unless the config says otherwise.
+func forgeConfigForHost(cfg domain.ConfigFile, host string) (domain.ForgeConfig, bool) {
+	host = normalizeForgeHost(host)
+	for key, forge := range cfg.Forges {
+		if normalizeForgeHost(key) == host {
+			return forge, true
+		}
+	}
+	if host == githubHost {
+		return domain.ForgeConfig{Type: domain.ForgeTypeGitHub}, true
+	}
+	return domain.ForgeConfig{}, false
+}
+
+func (a *App) forgeForHost(cfg domain.ConfigFile, host string) (Forge, error) {
+	host = normalizeForgeHost(host)
+	forgeCfg, ok := forgeConfigForHost(cfg, host)
+	if !ok {
+		return nil, fmt.Errorf("no forge configured for host %q; add it under forges in the config", host)
+	}
+	getenv := os.Getenv
+	if a.Getenv != nil {
+		getenv = a.Getenv
+	}
+	switch strings.ToLower(strings.TrimSpace(forgeCfg.Type)) {
+	case domain.ForgeTypeGitHub:
+		api := newForgeAPIClient(githubAPIRoot(host, forgeCfg), forgeCfg, githubTokenEnv, getenv)
+		if api.token == "" {
+			return githubCLIForge{app: a, cfg: cfg, host: host}, nil
+		}
+		return newGitHubAPIForge(api), nil
+	case domain.ForgeTypeGitLab:
+		api := newForgeAPIClient(forgeAPIRoot(host, forgeCfg, "/api/v4"), forgeCfg, "GITLAB_TOKEN", getenv)
+		api.authHeader = "PRIVATE-TOKEN"
+		return gitlabForge{api: api}, nil
+	case domain.ForgeTypeGitea, domain.ForgeTypeForgejo:
+		api := newForgeAPIClient(forgeAPIRoot(host, forgeCfg, "/api/v1"), forgeCfg, "GITEA_TOKEN", getenv)
+		api.authScheme = "token "
+		return giteaForge{api: api}, nil
+	default:
+		return nil, fmt.Errorf("forges[%q]: unsupported type %q", host, forgeCfg.Type)
+	}
+}
+
+// forgeKindForHost returns the configured forge type for host, or "" when
+// no forge is configured for it.
+func forgeKindForHost(cfg domain.ConfigFile, host string) string {
+	forgeCfg, ok := forgeConfigForHost(cfg, host)
+	if !ok {
+		return ""
+	}
+	kind := strings.ToLower(strings.TrimSpace(forgeCfg.Type))
+	if kind == domain.ForgeTypeForgejo {
+		return domain.ForgeTypeGitea
+	}
+	return kind
+}
+
+// originForgeKind returns the forge type for the origin's host, or "".
+func originForgeKind(cfg domain.ConfigFile, originURL string) string {
+	host, _, _, ok := forgeRepoForOrigin(originURL)
+	if !ok {
+		return ""
+	}
+	return forgeKindForHost(cfg, host)
+}
+
+// forgeRepoForOrigin splits an origin URL into its forge host, owner and
+// repository name. GitHub origins must be exactly owner/repo.
+func forgeRepoForOrigin(originURL string) (host string, owner string, repo string, ok bool) {
+	identity, err := domain.NormalizeOriginIdentity(originURL)
+	if err != nil {
+		return "", "", "", false
+	}
+	host, path, found := strings.Cut(identity, "/")
+	if !found {
+		return "", "", "", false
+	}
+	host = normalizeForgeHost(host)
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) < 2 || (host == githubHost && len(segments) != 2) {
+		return "", "", "", false
+	}
+	for _, segment := range segments {
+		if strings.TrimSpace(segment) == "" {
+			return "", "", "", false
+		}
+	}
+	return host, strings.Join(segments[:len(segments)-1], "/"), segments[len(segments)-1], true
+}
+
+// remoteURLTemplateForHost returns github.preferred_remote_url_template for
+// the default forge host; other hosts use the plain protocol URL.
+func remoteURLTemplateForHost(cfg domain.ConfigFile, host string) string {
+	if normalizeForgeHost(host) != defaultForgeHost(cfg) {
+		return ""
+	}
+	return cfg.GitHub.PreferredRemoteURLTemplate
+}
+
+// forgeAPIRoot returns api_url, or https://<host>, joined with apiPrefix.
+func forgeAPIRoot(host string, cfg domain.ForgeConfig, apiPrefix string) string {
+	baseURL := strings.TrimRight(strings.TrimSpace(cfg.APIURL), "/")
+	if baseURL == "" {
+		baseURL = "https://" + host
+	}
+	return baseURL + apiPrefix
+}
+
+// forgeToken resolves the API token from tokenEnv (or defaultTokenEnv) and
+// then token_file. source names where the token came from and is empty when
+// none was found.
+func forgeToken(cfg domain.ForgeConfig, defaultTokenEnv string, getenv func(string) string) (token string, source string) {
+	tokenEnv := strings.TrimSpace(cfg.TokenEnv)
+	if tokenEnv == "" {
+		tokenEnv = defaultTokenEnv
+	}
+	if token := strings.TrimSpace(getenv(tokenEnv)); token != "" {
+		return token, tokenEnv
+	}
+	tokenFile := strings.TrimSpace(cfg.TokenFile)
+	if tokenFile == "" {
+		return "", ""
+	}
+	raw, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return "", ""
+	}
+	if token := strings.TrimSpace(string(raw)); token != "" {
+		return token, tokenFile
+	}
+	return "", ""
+}
+
+// forgeAPIClient sends JSON requests to a forge REST API. The token is sent
+// as authHeader: authScheme+token; headers are added to every request.
+type forgeAPIClient struct {
+	baseURL    string
+	tokenEnv   string
+	tokenFile  string
+	token      string
+	authHeader string
+	authScheme string
+	headers    map[string]string
+	client     *http.Client
+	sleep      func(time.Duration)
+	now        func() time.Time
+}
+
+func newForgeAPIClient(apiRoot string, cfg domain.ForgeConfig, defaultTokenEnv string, getenv func(string) string) forgeAPIClient {
+	tokenEnv := strings.TrimSpace(cfg.TokenEnv)
+	if tokenEnv == "" {
+		tokenEnv = defaultTokenEnv
+	}
+	token, _ := forgeToken(cfg, defaultTokenEnv, getenv)
+	return forgeAPIClient{
+		baseURL:    apiRoot,
+		tokenEnv:   tokenEnv,
+		tokenFile:  strings.TrimSpace(cfg.TokenFile),
+		token:      token,
+		authHeader: "Authorization",
+		client:     &http.Client{Timeout: defaultForgeAPITimeout},
+		sleep:      time.Sleep,
+		now:        time.Now,
+	}
+}
+
+// forgeAPIError is a non-2xx response from a forge API.
+type forgeAPIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+func (e *forgeAPIError) Error() string {
+	msg := fmt.Sprintf("%s %s: status %d", e.Method, e.Path, e.StatusCode)
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+	return msg
+}
+
+func forgeAPIStatus(err error) int {
+	var apiErr *forgeAPIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// do sends body (when non-nil) as JSON and decodes a JSON response into out
+// (when non-nil). path is relative to the API root and must be escaped.
+// Rate-limited requests are retried while the limit resets within
+// forgeAPIMaxRateLimitWait.
+func (c forgeAPIClient) do(method string, path string, body any, out any) error {
+	if c.token == "" {
+		if c.tokenFile != "" {
+			return fmt.Errorf("forge API token missing: set %s or write it to %s", c.tokenEnv, c.tokenFile)
+		}
+		return fmt.Errorf("forge API token missing: set %s", c.tokenEnv)
+	}
+	var encoded []byte
+	if body != nil {
+		var err error
+		encoded, err = json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encode %s %s: %w", method, path, err)
+		}
+	}
+	for attempt := 1; ; attempt++ {
+		wait, err := c.send(method, path, encoded, out)
+		if wait < 0 {
+			return err
+		}
+		if attempt >= forgeAPIMaxAttempts || wait > forgeAPIMaxRateLimitWait {
+			return fmt.Errorf("%w (rate limited; retry in %s)", err, wait.Round(time.Second))
+		}
+		c.sleep(wait)
+	}
+}
+
+// send makes one request. wait is how long to back off before retrying a
+// rate-limited response and is negative when err is final.
+func (c forgeAPIClient) send(method string, path string, encoded []byte, out any) (time.Duration, error) {
+	var reader io.Reader
+	if encoded != nil {
+		reader = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return -1, err
+	}
+	if encoded != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "bb-project")
+	for key, value := range c.headers {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set(c.authHeader, c.authScheme+c.token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return -1, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		apiErr := &forgeAPIError{Method: method, Path: path, StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(snippet))}
+		if wait, limited := c.rateLimitWait(resp, apiErr.Body); limited {
+			return wait, apiErr
+		}
+		return -1, apiErr
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return -1, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return -1, fmt.Errorf("decode %s %s: %w", method, path, err)
+	}
+	return -1, nil
+}
+
+// rateLimitWait reports whether resp is a primary or secondary rate-limit
+// response and how long to wait before retrying it.
+func (c forgeAPIClient) rateLimitWait(resp *http.Response, body string) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(strings.TrimSpace(resp.Header.Get("Retry-After"))); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if strings.TrimSpace(resp.Header.Get("X-RateLimit-Remaining")) == "0" {
+		if reset, err := strconv.ParseInt(strings.TrimSpace(resp.Header.Get("X-RateLimit-Reset")), 10, 64); err == nil {
+			return max(time.Unix(reset, 0).Sub(c.now()), time.Second), true
+		}
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || strings.Contains(strings.ToLower(body), "secondary rate limit") {
+		return forgeAPISecondaryRateLimitWait, true
+	}
+	return 0, false
+}
+
+// forgeRepoPath escapes each segment of owner/repo for use in a URL path.
+func forgeRepoPath(owner string, repo string) string {
+	segments := strings.Split(owner+"/"+repo, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"bb-project/internal/domain"
+)
+
+// giteaForge drives Gitea and Forgejo through the v1 REST API.
+type giteaForge struct {
+	api forgeAPIClient
+}
+
+func (f giteaForge) Kind() string {
+	return domain.ForgeTypeGitea
+}
+
+func (f giteaForge) currentUser() (string, error) {
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := f.api.do(http.MethodGet, "/user", nil, &user); err != nil {
+		return "", err
+	}
+	return user.Login, nil
+}
+
+func (f giteaForge) CreateRepo(owner string, repo string, visibility domain.Visibility, _ string) error {
+	login, err := f.currentUser()
+	if err != nil {
+		return err
+	}
+	path := "/user/repos"
+	if !strings.EqualFold(owner, login) {
+		path = "/orgs/" + url.PathEscape(owner) + "/repos"
+	}
+	body := map[string]any{
+		"name":    repo,
+		"private": visibility != domain.VisibilityPublic,
+	}
+	return f.api.do(http.MethodPost, path, body, nil)
+}
+
+func (f giteaForge) Fork(owner string, repo string, forkOwner string, _ string) error {
+	login, err := f.currentUser()
+	if err != nil {
+		return err
+	}
+	body := map[string]any{}
+	if !strings.EqualFold(forkOwner, login) {
+		body["organization"] = forkOwner
+	}
+	err = f.api.do(http.MethodPost, "/repos/"+forgeRepoPath(owner, repo)+"/forks", body, nil)
+	if forgeAPIStatus(err) == http.StatusConflict {
+		return nil
+	}
+	return err
+}
+
+func (f giteaForge) PushAccess(owner string, repo string) (domain.PushAccess, error) {
+	var payload struct {
+		Permissions *struct {
+			Admin bool `json:"admin"`
+			Push  bool `json:"push"`
+		} `json:"permissions"`
+	}
+	if err := f.api.do(http.MethodGet, "/repos/"+forgeRepoPath(owner, repo), nil, &payload); err != nil {
+		return domain.PushAccessUnknown, err
+	}
+	if payload.Permissions == nil {
+		return domain.PushAccessUnknown, errors.New("gitea reported no repository permissions")
+	}
+	if payload.Permissions.Admin || payload.Permissions.Push {
+		return domain.PushAccessReadWrite, nil
+	}
+	return domain.PushAccessReadOnly, nil
+}
+
+func (f giteaForge) Visibility(owner string, repo string) (domain.Visibility, error) {
+	var payload struct {
+		Private bool `json:"private"`
+	}
+	if err := f.api.do(http.MethodGet, "/repos/"+forgeRepoPath(owner, repo), nil, &payload); err != nil {
+		return domain.VisibilityUnknown, err
+	}
+	if payload.Private {
+		return domain.VisibilityPrivate, nil
+	}
+	return domain.VisibilityPublic, nil
+}
@@ -0,0 +1,142 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"bb-project/internal/domain"
+)
+
+// githubCLIForge drives GitHub and GitHub Enterprise through the gh CLI.
+type githubCLIForge struct {
+	app  *App
+	cfg  domain.ConfigFile
+	host string
+}
+
+func (f githubCLIForge) Kind() string {
+	return domain.ForgeTypeGitHub
+}
+
+// repoArg names owner/repo for gh, prefixed with the host for GitHub
+// Enterprise.
+func (f githubCLIForge) repoArg(owner string, repo string) string {
+	name := fmt.Sprintf("%s/%s", owner, repo)
+	if f.host != "" && f.host != githubHost {
+		return f.host + "/" + name
+	}
+	return name
+}
+
+func (f githubCLIForge) CreateRepo(owner string, repo string, visibility domain.Visibility, repoPath string) error {
+	a := f.app
+	if err := a.ensureGitHubCLIReady(f.cfg); err != nil {
+		return err
+	}
+	visibilityFlag := "--private"
+	if visibility == domain.VisibilityPublic {
+		visibilityFlag = "--public"
+	}
+	args := []string{"repo", "create", f.repoArg(owner, repo), visibilityFlag}
+	a.logf("init: running gh %s", strings.Join(args, " "))
+	if err := a.runCommandAttached(repoPath, "gh", args...); err != nil {
+		return fmt.Errorf("gh repo create failed: %w", err)
+	}
+	return nil
+}
+
+func (f githubCLIForge) Fork(owner string, repo string, _ string, repoPath string) error {
+	a := f.app
+	if err := a.ensureGitHubCLIReady(f.cfg); err != nil {
+		return err
+	}
+	args := []string{"repo", "fork", f.repoArg(owner, repo), "--remote=false", "--clone=false"}
+	a.logf("fork: running gh %s", strings.Join(args, " "))
+	cmd := exec.Command("gh", args...)
+	cmd.Dir = repoPath
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		lower := strings.ToLower(string(out))
+		if !strings.Contains(lower, "already exists") {
+			return fmt.Errorf("gh repo fork failed: %w: %s", err, string(out))
+		}
+	}
+	return nil
+}
+
+func (f githubCLIForge) PushAccess(owner string, repo string) (domain.PushAccess, error) {
+	a := f.app
+	lookPath := a.LookPath
+	if lookPath == nil {
+		lookPath = exec.LookPath
+	}
+	if _, err := lookPath("gh"); err != nil {
+		return domain.PushAccessUnknown, errors.New("gh is not on PATH")
+	}
+
+	runCommand := a.RunCommand
+	if runCommand == nil {
+		runCommand = defaultRunCommand
+	}
+	out, err := runCommand("gh", "repo", "view", f.repoArg(owner, repo), "--json", "viewerPermission")
+	if err != nil {
+		return domain.PushAccessUnknown, err
+	}
+	access, ok := parseGitHubViewerPermissionPushAccess(out)
+	if !ok {
+		return domain.PushAccessUnknown, errors.New("unknown viewer permission")
+	}
+	return access, nil
+}
+
+func (f githubCLIForge) Visibility(owner string, repo string) (domain.Visibility, error) {
+	a := f.app
+	lookPath := a.LookPath
+	if lookPath == nil {
+		lookPath = exec.LookPath
+	}
+	if _, err := lookPath("gh"); err != nil {
+		return domain.VisibilityUnknown, errors.New("gh is not on PATH")
+	}
+
+	runCommand := a.RunCommand
+	if runCommand == nil {
+		runCommand = defaultRunCommand
+	}
+	out, err := runCommand("gh", "repo", "view", f.repoArg(owner, repo), "--json", "visibility")
+	if err != nil {
+		return domain.VisibilityUnknown, err
+	}
+	payload := struct {
+		Visibility string `json:"visibility"`
+	}{}
+	if err := json.Unmarshal([]byte(out), &payload); err != nil {
+		return domain.VisibilityUnknown, err
+	}
+	visibility := githubVisibility(payload.Visibility)
+	if visibility == domain.VisibilityUnknown {
+		return visibility, fmt.Errorf("unknown visibility %q", payload.Visibility)
+	}
+	return visibility, nil
+}
+
+func parseGitHubViewerPermissionPushAccess(raw string) (domain.PushAccess, bool) {
+	payload := struct {
+		ViewerPermission string `json:"viewerPermission"`
+	}{}
+	if err := json.Unmarshal([]byte(raw), &payload); err != nil {
+		return domain.PushAccessUnknown, false
+	}
+
+	switch strings.ToUpper(strings.TrimSpace(payload.ViewerPermission)) {
+	case "ADMIN", "MAINTAIN", "WRITE":
+		return domain.PushAccessReadWrite, true
+	case "TRIAGE", "READ", "NONE":
+		return domain.PushAccessReadOnly, true
+	default:
+		return domain.PushAccessUnknown, false
+	}
+}
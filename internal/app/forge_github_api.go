@@ -0,0 +1,145 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"bb-project/internal/domain"
+)
+
+const (
+	githubTokenEnv   = "GITHUB_TOKEN"
+	githubAPIURL     = "https://api.github.com"
+	githubAPIVersion = "2022-11-28"
+)
+
+// githubAPIRoot returns the REST root for a GitHub host: api_url when set,
+// api.github.com for github.com and https://<host>/api/v3 for GitHub
+// Enterprise Server.
+func githubAPIRoot(host string, cfg domain.ForgeConfig) string {
+	if apiURL := strings.TrimRight(strings.TrimSpace(cfg.APIURL), "/"); apiURL != "" {
+		return apiURL
+	}
+	if host == githubHost {
+		return githubAPIURL
+	}
+	return "https://" + host + "/api/v3"
+}
+
+// githubAPIForge drives GitHub and GitHub Enterprise through the REST API
+// without gh. It is used whenever an API token is available.
+type githubAPIForge struct {
+	api forgeAPIClient
+}
+
+func newGitHubAPIForge(api forgeAPIClient) githubAPIForge {
+	api.authScheme = "Bearer "
+	api.headers = map[string]string{
+		"Accept":               "application/vnd.github+json",
+		"X-GitHub-Api-Version": githubAPIVersion,
+	}
+	return githubAPIForge{api: api}
+}
+
+func (f githubAPIForge) Kind() string {
+	return domain.ForgeTypeGitHub
+}
+
+func (f githubAPIForge) currentUser() (string, error) {
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := f.api.do(http.MethodGet, "/user", nil, &user); err != nil {
+		return "", err
+	}
+	return user.Login, nil
+}
+
+func (f githubAPIForge) CreateRepo(owner string, repo string, visibility domain.Visibility, _ string) error {
+	login, err := f.currentUser()
+	if err != nil {
+		return err
+	}
+	path := "/user/repos"
+	if !strings.EqualFold(owner, login) {
+		path = "/orgs/" + url.PathEscape(owner) + "/repos"
+	}
+	body := map[string]any{
+		"name":    repo,
+		"private": visibility != domain.VisibilityPublic,
+	}
+	return f.api.do(http.MethodPost, path, body, nil)
+}
+
+// Fork forks owner/repo. GitHub returns the existing fork when one already
+// exists, so no conflict handling is needed.
+func (f githubAPIForge) Fork(owner string, repo string, forkOwner string, _ string) error {
+	login, err := f.currentUser()
+	if err != nil {
+		return err
+	}
+	body := map[string]any{}
+	if !strings.EqualFold(forkOwner, login) {
+		body["organization"] = forkOwner
+	}
+	return f.api.do(http.MethodPost, "/repos/"+forgeRepoPath(owner, repo)+"/forks", body, nil)
+}
+
+type githubAPIRepo struct {
+	Private     bool   `json:"private"`
+	Visibility  string `json:"visibility"`
+	Permissions *struct {
+		Admin    bool `json:"admin"`
+		Maintain bool `json:"maintain"`
+		Push     bool `json:"push"`
+	} `json:"permissions"`
+}
+
+func (f githubAPIForge) repo(owner string, repo string) (githubAPIRepo, error) {
+	var payload githubAPIRepo
+	err := f.api.do(http.MethodGet, "/repos/"+forgeRepoPath(owner, repo), nil, &payload)
+	return payload, err
+}
+
+func (f githubAPIForge) PushAccess(owner string, repo string) (domain.PushAccess, error) {
+	payload, err := f.repo(owner, repo)
+	if err != nil {
+		return domain.PushAccessUnknown, err
+	}
+	if payload.Permissions == nil {
+		return domain.PushAccessUnknown, errors.New("github reported no repository permissions")
+	}
+	if payload.Permissions.Admin || payload.Permissions.Maintain || payload.Permissions.Push {
+		return domain.PushAccessReadWrite, nil
+	}
+	return domain.PushAccessReadOnly, nil
+}
+
+func (f githubAPIForge) Visibility(owner string, repo string) (domain.Visibility, error) {
+	payload, err := f.repo(owner, repo)
+	if err != nil {
+		return domain.VisibilityUnknown, err
+	}
+	if visibility := strings.TrimSpace(payload.Visibility); visibility != "" {
+		return githubVisibility(visibility), nil
+	}
+	if payload.Private {
+		return domain.VisibilityPrivate, nil
+	}
+	return domain.VisibilityPublic, nil
+}
+
+// githubVisibility maps GitHub's public/private/internal visibility, in
+// either case, to a domain visibility.
+func githubVisibility(raw string) domain.Visibility {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "public":
+		return domain.VisibilityPublic
+	case "private", "internal":
+		return domain.VisibilityPrivate
+	default:
+		return domain.VisibilityUnknown
+	}
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"bb-project/internal/domain"
+)
+
+// gitlabDeveloperAccess is the lowest GitLab access level allowed to push.
+const gitlabDeveloperAccess = 30
+
+// gitlabForge drives GitLab through its v4 REST API.
+type gitlabForge struct {
+	api forgeAPIClient
+}
+
+func (f gitlabForge) Kind() string {
+	return domain.ForgeTypeGitLab
+}
+
+// projectPath is the URL-encoded full path GitLab accepts as a project ID.
+func (f gitlabForge) projectPath(owner string, repo string) string {
+	return url.PathEscape(owner + "/" + repo)
+}
+
+func (f gitlabForge) CreateRepo(owner string, repo string, visibility domain.Visibility, _ string) error {
+	var user struct {
+		Username string `json:"username"`
+	}
+	if err := f.api.do(http.MethodGet, "/user", nil, &user); err != nil {
+		return err
+	}
+	body := map[string]any{
+		"name":       repo,
+		"path":       repo,
+		"visibility": gitlabVisibility(visibility),
+	}
+	if !strings.EqualFold(owner, user.Username) {
+		var namespace struct {
+			ID int64 `json:"id"`
+		}
+		if err := f.api.do(http.MethodGet, "/namespaces/"+url.PathEscape(owner), nil, &namespace); err != nil {
+			return err
+		}
+		body["namespace_id"] = namespace.ID
+	}
+	return f.api.do(http.MethodPost, "/projects", body, nil)
+}
+
+func (f gitlabForge) Fork(owner string, repo string, forkOwner string, _ string) error {
+	body := map[string]any{"namespace_path": forkOwner}
+	err := f.api.do(http.MethodPost, "/projects/"+f.projectPath(owner, repo)+"/fork", body, nil)
+	if forgeAPIStatus(err) == http.StatusConflict || (err != nil && strings.Contains(err.Error(), "has already been taken")) {
+		return nil
+	}
+	return err
+}
+
+func (f gitlabForge) PushAccess(owner string, repo string) (domain.PushAccess, error) {
+	type access struct {
+		AccessLevel int `json:"access_level"`
+	}
+	var project struct {
+		Permissions struct {
+			ProjectAccess *access `json:"project_access"`
+			GroupAccess   *access `json:"group_access"`
+		} `json:"permissions"`
+	}
+	if err := f.api.do(http.MethodGet, "/projects/"+f.projectPath(owner, repo), nil, &project); err != nil {
+		return domain.PushAccessUnknown, err
+	}
+	// Both levels are null when the token's user is not a member of the
+	// project or its group; a project it can read is then read-only.
+	level := 0
+	for _, granted := range []*access{project.Permissions.ProjectAccess, project.Permissions.GroupAccess} {
+		if granted != nil {
+			level = max(level, granted.AccessLevel)
+		}
+	}
+	if level >= gitlabDeveloperAccess {
+		return domain.PushAccessReadWrite, nil
+	}
+	return domain.PushAccessReadOnly, nil
+}
+
+func (f gitlabForge) Visibility(owner string, repo string) (domain.Visibility, error) {
+	var project struct {
+		Visibility string `json:"visibility"`
+	}
+	if err := f.api.do(http.MethodGet, "/projects/"+f.projectPath(owner, repo), nil, &project); err != nil {
+		return domain.VisibilityUnknown, err
+	}
+	switch project.Visibility {
+	case "public":
+		return domain.VisibilityPublic, nil
+	case "private", "internal":
+		return domain.VisibilityPrivate, nil
+	default:
+		return domain.VisibilityUnknown, fmt.Errorf("unknown gitlab visibility %q", project.Visibility)
+	}
+}
+
+func gitlabVisibility(visibility domain.Visibility) string {
+	if visibility == domain.VisibilityPublic {
+		return "public"
+	}
+	return "private"
+}
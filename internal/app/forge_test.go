@@ -0,0 +1,522 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"bb-project/internal/domain"
+	"bb-project/internal/state"
+)
+
+type forgeAPIRequest struct {
+	Method string
+	Path   string
+	Auth   string
+	Body   map[string]any
+}
+
+// newFakeForgeAPI serves canned JSON responses keyed by "METHOD escaped-path"
+// and records every request. Unknown routes answer 404.
+func newFakeForgeAPI(t *testing.T, authHeader string, routes map[string]func() (int, string)) (*httptest.Server, func() []forgeAPIRequest) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var requests []forgeAPIRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := forgeAPIRequest{Method: r.Method, Path: r.URL.EscapedPath(), Auth: r.Header.Get(authHeader)}
+		if raw, _ := io.ReadAll(r.Body); len(raw) > 0 {
+			if err := json.Unmarshal(raw, &req.Body); err != nil {
+				t.Errorf("decode request body: %v", err)
+			}
+		}
+		mu.Lock()
+		requests = append(requests, req)
+		mu.Unlock()
+
+		route, ok := routes[r.Method+" "+r.URL.EscapedPath()]
+		if !ok {
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+			return
+		}
+		status, body := route()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []forgeAPIRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]forgeAPIRequest(nil), requests...)
+	}
+}
+
+func reply(status int, body string) func() (int, string) {
+	return func() (int, string) { return status, body }
+}
+
+func newForgeTestApp(t *testing.T, env map[string]string) *App {
+	t.Helper()
+	a := New(state.NewPaths(t.TempDir()), &bytes.Buffer{}, &bytes.Buffer{})
+	a.Getenv = func(key string) string { return env[key] }
+	return a
+}
+
+func TestForgeForHostSelectsConfiguredForge(t *testing.T) {
+	t.Parallel()
+
+	a := newForgeTestApp(t, nil)
+	cfg := domain.ConfigFile{Forges: map[string]domain.ForgeConfig{
+		"gitlab.example.com": {Type: domain.ForgeTypeGitLab},
+		"git.example.com":    {Type: domain.ForgeTypeForgejo},
+	}}
+	tests := []struct {
+		host string
+		want string
+	}{
+		{host: "github.com", want: domain.ForgeTypeGitHub},
+		{host: "you.github.com", want: domain.ForgeTypeGitHub},
+		{host: "GitLab.example.com", want: domain.ForgeTypeGitLab},
+		{host: "git.example.com:2222", want: domain.ForgeTypeGitea},
+	}
+	for _, tt := range tests {
+		forge, err := a.forgeForHost(cfg, tt.host)
+		if err != nil {
+			t.Fatalf("forgeForHost(%q) error: %v", tt.host, err)
+		}
+		if forge.Kind() != tt.want {
+			t.Fatalf("forgeForHost(%q).Kind() = %q, want %q", tt.host, forge.Kind(), tt.want)
+		}
+	}
+
+	if _, err := a.forgeForHost(cfg, "gitflic.ru"); err == nil || !strings.Contains(err.Error(), "no forge configured") {
+		t.Fatalf("expected missing forge error, got %v", err)
+	}
+}
+
+func TestForgeRepoForOrigin(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		origin                string
+		wantHost, owner, repo string
+		wantOK                bool
+	}{
+		{origin: "git@you.github.com:acme/demo.git", wantHost: "github.com", owner: "acme", repo: "demo", wantOK: true},
+		{origin: "https://github.com/acme/team/demo.git", wantOK: false},
+		{origin: "https://gitlab.example.com/group/sub/demo.git", wantHost: "gitlab.example.com", owner: "group/sub", repo: "demo", wantOK: true},
+		{origin: "ssh://git@git.example.com/team/demo.git", wantHost: "git.example.com", owner: "team", repo: "demo", wantOK: true},
+		{origin: "https://git.example.com/demo.git", wantOK: false},
+	}
+	for _, tt := range tests {
+		host, owner, repo, ok := forgeRepoForOrigin(tt.origin)
+		if ok != tt.wantOK || host != tt.wantHost || owner != tt.owner || repo != tt.repo {
+			t.Fatalf("forgeRepoForOrigin(%q) = (%q, %q, %q, %v), want (%q, %q, %q, %v)",
+				tt.origin, host, owner, repo, ok, tt.wantHost, tt.owner, tt.repo, tt.wantOK)
+		}
+	}
+}
+
+func TestGitLabForgeCreateRepoInGroupNamespace(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newFakeForgeAPI(t, "PRIVATE-TOKEN", map[string]func() (int, string){
+		"GET /api/v4/user":                  reply(http.StatusOK, `{"username":"you"}`),
+		"GET /api/v4/namespaces/team%2Fsub": reply(http.StatusOK, `{"id":42}`),
+		"POST /api/v4/projects":             reply(http.StatusCreated, `{"id":7}`),
+	})
+	a := newForgeTestApp(t, map[string]string{"GITLAB_TOKEN": "secret"})
+	cfg := domain.ConfigFile{Forges: map[string]domain.ForgeConfig{
+		"gitlab.example.com": {Type: domain.ForgeTypeGitLab, APIURL: server.URL},
+	}}
+	forge, err := a.forgeForHost(cfg, "gitlab.example.com")
+	if err != nil {
+		t.Fatalf("forgeForHost error: %v", err)
+	}
+
+	if err := forge.CreateRepo("team/sub", "demo", domain.VisibilityPublic, t.TempDir()); err != nil {
+		t.Fatalf("CreateRepo error: %v", err)
+	}
+
+	got := requests()
+	if len(got) != 3 {
+		t.Fatalf("request count = %d, want 3 (%+v)", len(got), got)
+	}
+	create := got[2]
+	if create.Auth != "secret" {
+		t.Fatalf("PRIVATE-TOKEN = %q, want %q", create.Auth, "secret")
+	}
+	if create.Body["path"] != "demo" || create.Body["visibility"] != "public" || create.Body["namespace_id"] != float64(42) {
+		t.Fatalf("create body = %#v", create.Body)
+	}
+}
+
+func TestGitLabForgeForkAndPushAccess(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newFakeForgeAPI(t, "PRIVATE-TOKEN", map[string]func() (int, string){
+		"POST /api/v4/projects/group%2Fdemo/fork": reply(http.StatusConflict, `{"message":{"name":["has already been taken"]}}`),
+		"GET /api/v4/projects/group%2Fdemo":       reply(http.StatusOK, `{"permissions":{"project_access":null,"group_access":{"access_level":20}}}`),
+		"GET /api/v4/projects/you%2Fdemo":         reply(http.StatusOK, `{"permissions":{"project_access":{"access_level":40},"group_access":null}}`),
+		"GET /api/v4/projects/other%2Fdemo":       reply(http.StatusOK, `{"permissions":{"project_access":null,"group_access":null}}`),
+	})
+	a := newForgeTestApp(t, map[string]string{"LAB_TOKEN": "secret"})
+	cfg := domain.ConfigFile{Forges: map[string]domain.ForgeConfig{
+		"gitlab.example.com": {Type: domain.ForgeTypeGitLab, APIURL: server.URL + "/", TokenEnv: "LAB_TOKEN"},
+	}}
+	forge, err := a.forgeForHost(cfg, "gitlab.example.com")
+	if err != nil {
+		t.Fatalf("forgeForHost error: %v", err)
+	}
+
+	if err := forge.Fork("group", "demo", "you", t.TempDir()); err != nil {
+		t.Fatalf("Fork should treat an existing fork as success: %v", err)
+	}
+	if got := requests()[0].Body["namespace_path"]; got != "you" {
+		t.Fatalf("fork namespace_path = %v, want you", got)
+	}
+	if access, err := forge.PushAccess("group", "demo"); err != nil || access != domain.PushAccessReadOnly {
+		t.Fatalf("PushAccess(group/demo) = (%q, %v), want read_only", access, err)
+	}
+	if access, err := forge.PushAccess("you", "demo"); err != nil || access != domain.PushAccessReadWrite {
+		t.Fatalf("PushAccess(you/demo) = (%q, %v), want read_write", access, err)
+	}
+	if access, err := forge.PushAccess("other", "demo"); err != nil || access != domain.PushAccessReadOnly {
+		t.Fatalf("PushAccess(other/demo) without membership = (%q, %v), want read_only", access, err)
+	}
+}
+
+func TestGiteaForgeCreateForkAndPushAccess(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newFakeForgeAPI(t, "Authorization", map[string]func() (int, string){
+		"GET /api/v1/user":                 reply(http.StatusOK, `{"login":"you"}`),
+		"POST /api/v1/user/repos":          reply(http.StatusCreated, `{}`),
+		"POST /api/v1/orgs/team/repos":     reply(http.StatusCreated, `{}`),
+		"POST /api/v1/repos/up/demo/forks": reply(http.StatusConflict, `{"message":"repository is already forked"}`),
+		"GET /api/v1/repos/up/demo":        reply(http.StatusOK, `{"permissions":{"admin":false,"push":false,"pull":true}}`),
+	})
+	a := newForgeTestApp(t, map[string]string{"GITEA_TOKEN": "secret"})
+	cfg := domain.ConfigFile{Forges: map[string]domain.ForgeConfig{
+		"git.example.com": {Type: domain.ForgeTypeGitea, APIURL: server.URL},
+	}}
+	forge, err := a.forgeForHost(cfg, "git.example.com")
+	if err != nil {
+		t.Fatalf("forgeForHost error: %v", err)
+	}
+
+	if err := forge.CreateRepo("you", "demo", domain.VisibilityPrivate, t.TempDir()); err != nil {
+		t.Fatalf("CreateRepo(you) error: %v", err)
+	}
+	if err := forge.CreateRepo("team", "demo", domain.VisibilityPublic, t.TempDir()); err != nil {
+		t.Fatalf("CreateRepo(team) error: %v", err)
+	}
+	if err := forge.Fork("up", "demo", "team", t.TempDir()); err != nil {
+		t.Fatalf("Fork should treat an existing fork as success: %v", err)
+	}
+	if access, err := forge.PushAccess("up", "demo"); err != nil || access != domain.PushAccessReadOnly {
+		t.Fatalf("PushAccess = (%q, %v), want read_only", access, err)
+	}
+
+	got := requests()
+	var userCreate, orgCreate, fork forgeAPIRequest
+	for _, req := range got {
+		if req.Auth != "token secret" {
+			t.Fatalf("Authorization = %q, want %q", req.Auth, "token secret")
+		}
+		switch req.Method + " " + req.Path {
+		case "POST /api/v1/user/repos":
+			userCreate = req
+		case "POST /api/v1/orgs/team/repos":
+			orgCreate = req
+		case "POST /api/v1/repos/up/demo/forks":
+			fork = req
+		}
+	}
+	if userCreate.Body["name"] != "demo" || userCreate.Body["private"] != true {
+		t.Fatalf("user create body = %#v", userCreate.Body)
+	}
+	if orgCreate.Body["private"] != false {
+		t.Fatalf("org create body = %#v", orgCreate.Body)
+	}
+	if fork.Body["organization"] != "team" {
+		t.Fatalf("fork body = %#v", fork.Body)
+	}
+}
+
+func TestForgeAPIRequiresToken(t *testing.T) {
+	t.Parallel()
+
+	a := newForgeTestApp(t, nil)
+	cfg := domain.ConfigFile{Forges: map[string]domain.ForgeConfig{
+		"git.example.com": {Type: domain.ForgeTypeGitea, APIURL: "http://127.0.0.1:1"},
+	}}
+	forge, err := a.forgeForHost(cfg, "git.example.com")
+	if err != nil {
+		t.Fatalf("forgeForHost error: %v", err)
+	}
+	err = forge.CreateRepo("you", "demo", domain.VisibilityPrivate, "")
+	if err == nil || !strings.Contains(err.Error(), "GITEA_TOKEN") {
+		t.Fatalf("expected token hint, got %v", err)
+	}
+}
+
+func TestEnsureForkRemoteRepoUsesOriginForge(t *testing.T) {
+	server, _ := newFakeForgeAPI(t, "Authorization", map[string]func() (int, string){
+		"GET /api/v1/user":                   reply(http.StatusOK, `{"login":"you"}`),
+		"POST /api/v1/repos/team/demo/forks": reply(http.StatusAccepted, `{}`),
+		"GET /api/v1/repos/team/demo":        reply(http.StatusOK, `{"permissions":{"admin":false,"push":true}}`),
+	})
+	t.Setenv("BB_TEST_REMOTE_ROOT", "")
+	a := newForgeTestApp(t, map[string]string{"GITEA_TOKEN": "secret"})
+	cfg := state.DefaultConfig()
+	cfg.GitHub.PreferredRemoteURLTemplate = "git@${org}.github.com:${org}/${repo}.git"
+	cfg.Forges = map[string]domain.ForgeConfig{
+		"git.example.com": {Type: domain.ForgeTypeGitea, APIURL: server.URL},
+	}
+
+	forkURL, err := a.ensureForkRemoteRepo(cfg, "git@git.example.com:team/demo.git", "you", "ssh", t.TempDir())
+	if err != nil {
+		t.Fatalf("ensureForkRemoteRepo error: %v", err)
+	}
+	if forkURL != "git@git.example.com:you/demo.git" {
+		t.Fatalf("fork URL = %q, want the plain ssh URL on the origin host", forkURL)
+	}
+
+	access, ok := a.probePushAccessViaForge(cfg, "https://git.example.com/team/demo.git")
+	if !ok || access != domain.PushAccessReadWrite {
+		t.Fatalf("probePushAccessViaForge = (%q, %v), want read_write", access, ok)
+	}
+}
+
+func TestCreateRemoteRepoOnDefaultForgeHost(t *testing.T) {
+	server, _ := newFakeForgeAPI(t, "PRIVATE-TOKEN", map[string]func() (int, string){
+		"GET /api/v4/user":      reply(http.StatusOK, `{"username":"you"}`),
+		"POST /api/v4/projects": reply(http.StatusCreated, `{}`),
+	})
+	t.Setenv("BB_TEST_REMOTE_ROOT", "")
+	a := newForgeTestApp(t, map[string]string{"GITLAB_TOKEN": "secret"})
+	cfg := state.DefaultConfig()
+	cfg.GitHub.Host = "gitlab.example.com"
+	cfg.Forges = map[string]domain.ForgeConfig{
+		"gitlab.example.com": {Type: domain.ForgeTypeGitLab, APIURL: server.URL},
+	}
+
+	origin, err := a.createRemoteRepo(cfg, "you", "demo", domain.VisibilityPrivate, "https", "", t.TempDir())
+	if err != nil {
+		t.Fatalf("createRemoteRepo error: %v", err)
+	}
+	if origin != "https://gitlab.example.com/you/demo.git" {
+		t.Fatalf("origin = %q, want %q", origin, "https://gitlab.example.com/you/demo.git")
+	}
+}
+
+func TestGitHubAPIForgeCreateForkAndRepoLookups(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newFakeForgeAPI(t, "Authorization", map[string]func() (int, string){
+		"GET /api/v3/user":                   reply(http.StatusOK, `{"login":"you"}`),
+		"POST /api/v3/user/repos":            reply(http.StatusCreated, `{}`),
+		"POST /api/v3/orgs/team/repos":       reply(http.StatusCreated, `{}`),
+		"POST /api/v3/repos/up/demo/forks":   reply(http.StatusAccepted, `{}`),
+		"GET /api/v3/repos/up/demo":          reply(http.StatusOK, `{"private":false,"visibility":"public","permissions":{"admin":false,"maintain":false,"push":false,"pull":true}}`),
+		"GET /api/v3/repos/team/internal-ci": reply(http.StatusOK, `{"private":true,"visibility":"internal","permissions":{"admin":false,"maintain":true,"push":true}}`),
+	})
+	a := newForgeTestApp(t, map[string]string{"GITHUB_TOKEN": "secret"})
+	cfg := domain.ConfigFile{Forges: map[string]domain.ForgeConfig{
+		"ghe.example.com": {Type: domain.ForgeTypeGitHub, APIURL: server.URL + "/api/v3/"},
+	}}
+	forge, err := a.forgeForHost(cfg, "ghe.example.com")
+	if err != nil {
+		t.Fatalf("forgeForHost error: %v", err)
+	}
+	if _, ok := forge.(githubAPIForge); !ok {
+		t.Fatalf("forgeForHost = %T, want githubAPIForge when a token is set", forge)
+	}
+
+	if err := forge.CreateRepo("you", "demo", domain.VisibilityPrivate, t.TempDir()); err != nil {
+		t.Fatalf("CreateRepo(you) error: %v", err)
+	}
+	if err := forge.CreateRepo("team", "demo", domain.VisibilityPublic, t.TempDir()); err != nil {
+		t.Fatalf("CreateRepo(team) error: %v", err)
+	}
+	if err := forge.Fork("up", "demo", "team", t.TempDir()); err != nil {
+		t.Fatalf("Fork error: %v", err)
+	}
+	if access, err := forge.PushAccess("up", "demo"); err != nil || access != domain.PushAccessReadOnly {
+		t.Fatalf("PushAccess(up/demo) = (%q, %v), want read_only", access, err)
+	}
+	if access, err := forge.PushAccess("team", "internal-ci"); err != nil || access != domain.PushAccessReadWrite {
+		t.Fatalf("PushAccess(team/internal-ci) = (%q, %v), want read_write", access, err)
+	}
+	if visibility, err := forge.Visibility("up", "demo"); err != nil || visibility != domain.VisibilityPublic {
+		t.Fatalf("Visibility(up/demo) = (%q, %v), want public", visibility, err)
+	}
+	if visibility, err := forge.Visibility("team", "internal-ci"); err != nil || visibility != domain.VisibilityPrivate {
+		t.Fatalf("Visibility(team/internal-ci) = (%q, %v), want private", visibility, err)
+	}
+
+	var userCreate, orgCreate, fork forgeAPIRequest
+	for _, req := range requests() {
+		if req.Auth != "Bearer secret" {
+			t.Fatalf("Authorization = %q, want %q", req.Auth, "Bearer secret")
+		}
+		switch req.Method + " " + req.Path {
+		case "POST /api/v3/user/repos":
+			userCreate = req
+		case "POST /api/v3/orgs/team/repos":
+			orgCreate = req
+		case "POST /api/v3/repos/up/demo/forks":
+			fork = req
+		}
+	}
+	if userCreate.Body["name"] != "demo" || userCreate.Body["private"] != true {
+		t.Fatalf("user create body = %#v", userCreate.Body)
+	}
+	if orgCreate.Body["private"] != false {
+		t.Fatalf("org create body = %#v", orgCreate.Body)
+	}
+	if fork.Body["organization"] != "team" {
+		t.Fatalf("fork body = %#v", fork.Body)
+	}
+}
+
+func TestGitHubForgeFallsBackToCLIWithoutToken(t *testing.T) {
+	t.Parallel()
+
+	a := newForgeTestApp(t, nil)
+	forge, err := a.forgeForHost(domain.ConfigFile{}, "github.com")
+	if err != nil {
+		t.Fatalf("forgeForHost error: %v", err)
+	}
+	if _, ok := forge.(githubCLIForge); !ok {
+		t.Fatalf("forgeForHost = %T, want githubCLIForge without a token", forge)
+	}
+}
+
+func TestGitHubAPIRoot(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		host   string
+		apiURL string
+		want   string
+	}{
+		{host: "github.com", want: "https://api.github.com"},
+		{host: "ghe.example.com", want: "https://ghe.example.com/api/v3"},
+		{host: "ghe.example.com", apiURL: "https://api.ghe.example.com/", want: "https://api.ghe.example.com"},
+	}
+	for _, tt := range tests {
+		if got := githubAPIRoot(tt.host, domain.ForgeConfig{APIURL: tt.apiURL}); got != tt.want {
+			t.Fatalf("githubAPIRoot(%q, %q) = %q, want %q", tt.host, tt.apiURL, got, tt.want)
+		}
+	}
+}
+
+func TestForgeTokenReadsTokenFileWhenEnvUnset(t *testing.T) {
+	t.Parallel()
+
+	tokenFile := filepath.Join(t.TempDir(), "github-token")
+	if err := os.WriteFile(tokenFile, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatalf("write token file: %v", err)
+	}
+	cfg := domain.ForgeConfig{Type: domain.ForgeTypeGitHub, TokenFile: tokenFile}
+
+	token, source := forgeToken(cfg, githubTokenEnv, func(string) string { return "" })
+	if token != "from-file" || source != tokenFile {
+		t.Fatalf("forgeToken = (%q, %q), want (from-file, %q)", token, source, tokenFile)
+	}
+	token, source = forgeToken(cfg, githubTokenEnv, func(key string) string {
+		if key == githubTokenEnv {
+			return "from-env"
+		}
+		return ""
+	})
+	if token != "from-env" || source != githubTokenEnv {
+		t.Fatalf("forgeToken = (%q, %q), want env token first", token, source)
+	}
+}
+
+func TestForgeAPIRetriesRateLimitedRequests(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_700_000_000, 0)
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch calls {
+		case 1:
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(now.Add(5*time.Second).Unix(), 10))
+			http.Error(w, `{"message":"API rate limit exceeded"}`, http.StatusForbidden)
+		case 2:
+			http.Error(w, `{"message":"You have exceeded a secondary rate limit."}`, http.StatusForbidden)
+		default:
+			_, _ = io.WriteString(w, `{"login":"you"}`)
+		}
+	}))
+	defer server.Close()
+	api := newForgeAPIClient(server.URL, domain.ForgeConfig{}, githubTokenEnv, func(string) string { return "secret" })
+	api.now = func() time.Time { return now }
+	var waits []time.Duration
+	api.sleep = func(d time.Duration) { waits = append(waits, d) }
+
+	login, err := newGitHubAPIForge(api).currentUser()
+	if err != nil || login != "you" {
+		t.Fatalf("currentUser = (%q, %v), want you", login, err)
+	}
+	want := []time.Duration{5 * time.Second, forgeAPISecondaryRateLimitWait}
+	if len(waits) != len(want) || waits[0] != want[0] || waits[1] != want[1] {
+		t.Fatalf("waits = %v, want %v", waits, want)
+	}
+}
+
+func TestForgeAPIGivesUpOnLongRateLimit(t *testing.T) {
+	t.Parallel()
+
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "3600")
+		http.Error(w, `{"message":"too many requests"}`, http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+	api := newForgeAPIClient(server.URL, domain.ForgeConfig{}, githubTokenEnv, func(string) string { return "secret" })
+	api.sleep = func(time.Duration) { t.Fatal("should not sleep past the rate-limit cap") }
+
+	_, err := newGitHubAPIForge(api).currentUser()
+	if err == nil || !strings.Contains(err.Error(), "rate limited; retry in 1h0m0s") {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+	if forgeAPIStatus(err) != http.StatusTooManyRequests || calls != 1 {
+		t.Fatalf("status = %d after %d call(s), want one 429", forgeAPIStatus(err), calls)
+	}
+}
+
+func TestDetectGitHubCLIStatusAcceptsAPIToken(t *testing.T) {
+	t.Parallel()
+
+	a := newForgeTestApp(t, map[string]string{"GITHUB_TOKEN": "secret"})
+	a.LookPath = func(string) (string, error) {
+		t.Fatal("gh should not be looked up when a token is set")
+		return "", nil
+	}
+
+	status := a.detectGitHubCLIStatus(state.DefaultConfig())
+	if !status.Ready() || status.TokenSource != "GITHUB_TOKEN" {
+		t.Fatalf("status = %+v, want ready via GITHUB_TOKEN", status)
+	}
+	if err := a.ensureGitHubCLIReady(state.DefaultConfig()); err != nil {
+		t.Fatalf("ensureGitHubCLIReady error: %v", err)
+	}
+}
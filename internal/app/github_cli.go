@@ -10,18 +10,22 @@ import (
 	"bb-project/internal/domain"
 )
 
+// GitHubCLIStatus describes whether GitHub operations can run. TokenSource
+// names the environment variable or token file holding a GitHub API token;
+// when set, the REST client is used and gh is not needed.
 type GitHubCLIStatus struct {
 	Checked       bool
 	Installed     bool
 	Authenticated bool
 	AuthStatus    string
+	TokenSource   string
 }
 
 func (s GitHubCLIStatus) Ready() bool {
-	return s.Checked && s.Installed && s.Authenticated
+	return s.Checked && (s.TokenSource != "" || (s.Installed && s.Authenticated))
 }
 
-func (a *App) detectGitHubCLIStatus() GitHubCLIStatus {
+func (a *App) detectGitHubCLIStatus(cfg domain.ConfigFile) GitHubCLIStatus {
 	getenv := os.Getenv
 	if a.Getenv != nil {
 		getenv = a.Getenv
@@ -34,6 +38,18 @@ func (a *App) detectGitHubCLIStatus() GitHubCLIStatus {
 		}
 	}
 
+	host := defaultForgeHost(cfg)
+	if forgeKindForHost(cfg, host) != domain.ForgeTypeGitHub {
+		host = githubHost
+	}
+	forgeCfg, _ := forgeConfigForHost(cfg, host)
+	if _, source := forgeToken(forgeCfg, githubTokenEnv, getenv); source != "" {
+		return GitHubCLIStatus{
+			Checked:     true,
+			TokenSource: source,
+		}
+	}
+
 	lookPath := a.LookPath
 	if lookPath == nil {
 		lookPath = exec.LookPath
@@ -67,8 +83,8 @@ func (a *App) detectGitHubCLIStatus() GitHubCLIStatus {
 	}
 }
 
-func (a *App) ensureGitHubCLIReady() error {
-	status := a.detectGitHubCLIStatus()
+func (a *App) ensureGitHubCLIReady(cfg domain.ConfigFile) error {
+	status := a.detectGitHubCLIStatus(cfg)
 	if !status.Checked || status.Ready() {
 		return nil
 	}
@@ -83,32 +99,54 @@ func (a *App) ensureGitHubCLIReady() error {
 	return errors.New(msg)
 }
 
-func (a *App) reportGitHubCLIWarnings(cfg domain.ConfigFile, repos []domain.MachineRepoRecord, allowedCatalogs map[string]struct{}) int {
+// githubCLIWarning describes why GitHub operations are not ready and how to
+// fix it.
+type githubCLIWarning struct {
+	Message     string
+	Remediation string
+	Detail      string
+}
+
+func (a *App) githubCLIWarning(cfg domain.ConfigFile, repos []domain.MachineRepoRecord, allowedCatalogs map[string]struct{}) (githubCLIWarning, bool) {
 	if !requiresGitHubCLI(cfg, repos, allowedCatalogs) {
-		return 0
+		return githubCLIWarning{}, false
 	}
 
-	status := a.detectGitHubCLIStatus()
+	status := a.detectGitHubCLIStatus(cfg)
 	if !status.Checked || status.Ready() {
-		return 0
+		return githubCLIWarning{}, false
 	}
 
 	if !status.Installed {
-		fmt.Fprintln(a.Stdout, "warning: GitHub operations require gh, but it is not available on PATH")
-		fmt.Fprintln(a.Stdout, "warning: install gh (for example `brew install gh`) and run `gh auth login`")
-		return 1
-	}
+		return githubCLIWarning{
+			Message:     "GitHub operations require gh, but it is not available on PATH",
+			Remediation: "install gh (for example `brew install gh`) and run `gh auth login`",
+		}, true
+	}
+	return githubCLIWarning{
+		Message:     "gh is installed but not authenticated for GitHub operations",
+		Remediation: "run `gh auth login` and confirm with `gh auth status`",
+		Detail:      strings.TrimSpace(status.AuthStatus),
+	}, true
+}
 
-	fmt.Fprintln(a.Stdout, "warning: gh is installed but not authenticated for GitHub operations")
-	fmt.Fprintln(a.Stdout, "warning: run `gh auth login` and confirm with `gh auth status`")
-	if details := strings.TrimSpace(status.AuthStatus); details != "" {
-		fmt.Fprintf(a.Stdout, "warning: gh auth status detail: %s\n", details)
+func (a *App) reportGitHubCLIWarnings(cfg domain.ConfigFile, repos []domain.MachineRepoRecord, allowedCatalogs map[string]struct{}) int {
+	warning, ok := a.githubCLIWarning(cfg, repos, allowedCatalogs)
+	if !ok {
+		return 0
+	}
+	fmt.Fprintf(a.Stdout, "warning: %s\n", warning.Message)
+	fmt.Fprintf(a.Stdout, "warning: %s\n", warning.Remediation)
+	if warning.Detail != "" {
+		fmt.Fprintf(a.Stdout, "warning: gh auth status detail: %s\n", warning.Detail)
 	}
 	return 1
 }
 
+// requiresGitHubCLI reports whether new projects or any selected repo live on
+// a GitHub forge.
 func requiresGitHubCLI(cfg domain.ConfigFile, repos []domain.MachineRepoRecord, allowedCatalogs map[string]struct{}) bool {
-	if strings.TrimSpace(cfg.GitHub.Owner) != "" {
+	if strings.TrimSpace(cfg.GitHub.Owner) != "" && forgeKindForHost(cfg, defaultForgeHost(cfg)) == domain.ForgeTypeGitHub {
 		return true
 	}
 	for _, repo := range repos {
@@ -117,7 +155,7 @@ func requiresGitHubCLI(cfg domain.ConfigFile, repos []domain.MachineRepoRecord,
 				continue
 			}
 		}
-		if _, _, ok := githubSourceRepoForOrigin(repo.OriginURL); ok {
+		if originForgeKind(cfg, repo.OriginURL) == domain.ForgeTypeGitHub {
 			return true
 		}
 	}
@@ -30,6 +30,12 @@ func validateGitHubRemoteURLTemplate(template string) error {
 }
 
 func githubRemoteURL(owner string, repo string, protocol string, template string) (string, error) {
+	return forgeRemoteURL(githubHost, owner, repo, protocol, template)
+}
+
+// forgeRemoteURL builds the remote URL for owner/repo on host, rendering
+// template when set.
+func forgeRemoteURL(host string, owner string, repo string, protocol string, template string) (string, error) {
 	owner = strings.TrimSpace(owner)
 	repo = strings.TrimSpace(repo)
 	if owner == "" {
@@ -45,9 +51,9 @@ func githubRemoteURL(owner string, repo string, protocol string, template string
 	}
 
 	if strings.EqualFold(strings.TrimSpace(protocol), "https") {
-		return fmt.Sprintf("https://github.com/%s/%s.git", owner, repo), nil
+		return fmt.Sprintf("https://%s/%s/%s.git", host, owner, repo), nil
 	}
-	return fmt.Sprintf("git@github.com:%s/%s.git", owner, repo), nil
+	return fmt.Sprintf("git@%s:%s/%s.git", host, owner, repo), nil
 }
 
 func renderGitHubRemoteURLTemplate(template string, owner string, repo string) (string, error) {
@@ -44,6 +44,9 @@ func printProjectInfo(stdout anyWriter, paths state.Paths, rec domain.MachineRep
 	fmt.Fprintf(stdout, "Ahead/Behind: %d/%d\n", rec.Ahead, rec.Behind)
 	fmt.Fprintf(stdout, "Dirty: tracked=%s untracked=%s\n", onOffLabel(rec.HasDirtyTracked), onOffLabel(rec.HasUntracked))
 	fmt.Fprintf(stdout, "Syncable: %s\n", yesNo(rec.Syncable))
+	for _, wt := range rec.Worktrees {
+		fmt.Fprintf(stdout, "Worktree: %s\n", describeWorktree(wt))
+	}
 
 	if strings.TrimSpace(rec.RepoKey) == "" {
 		return
@@ -112,7 +112,7 @@ func TestCreateRemoteRepoUsesAttachedCommandForGitHubCLI(t *testing.T) {
 	}
 
 	repoPath := t.TempDir()
-	origin, err := app.createRemoteRepo("you", "demo", domain.VisibilityPublic, "https", "", repoPath)
+	origin, err := app.createRemoteRepo(domain.ConfigFile{}, "you", "demo", domain.VisibilityPublic, "https", "", repoPath)
 	if err != nil {
 		t.Fatalf("createRemoteRepo error: %v", err)
 	}
@@ -146,7 +146,7 @@ func TestCreateRemoteRepoReturnsHelpfulErrorWhenGHMissing(t *testing.T) {
 		return "", errors.New("not found")
 	}
 
-	_, err := app.createRemoteRepo("you", "demo", domain.VisibilityPrivate, "ssh", "", t.TempDir())
+	_, err := app.createRemoteRepo(domain.ConfigFile{}, "you", "demo", domain.VisibilityPrivate, "ssh", "", t.TempDir())
 	if err == nil {
 		t.Fatal("expected error")
 	}
@@ -177,6 +177,7 @@ func TestCreateRemoteRepoUsesGitHubRemoteTemplate(t *testing.T) {
 	}
 
 	origin, err := app.createRemoteRepo(
+		domain.ConfigFile{},
 		"niieani",
 		"bb-project",
 		domain.VisibilityPrivate,
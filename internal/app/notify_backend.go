@@ -11,14 +11,22 @@ import (
 )
 
 const (
-	notifyBackendStdout    = "stdout"
-	notifyBackendOSAScript = "osascript"
-	notifyBackendEnvVar    = "BB_NOTIFY_BACKEND"
+	notifyBackendStdout     = "stdout"
+	notifyBackendOSAScript  = "osascript"
+	notifyBackendWebhook    = "webhook"
+	notifyBackendNotifySend = "notify-send"
+	notifyBackendDBus       = "dbus"
+	notifyBackendEnvVar     = "BB_NOTIFY_BACKEND"
 )
 
+// notifyMessage is one notification for a repo. Recovered messages report a
+// previously notified repo that is syncable again; Fingerprint then holds the
+// reasons that were resolved.
 type notifyMessage struct {
+	MachineID   string
 	Repo        domain.MachineRepoRecord
 	Fingerprint string
+	Recovered   bool
 }
 
 type notifySender interface {
@@ -30,6 +38,10 @@ type stdoutNotifySender struct {
 }
 
 func (s *stdoutNotifySender) Send(msg notifyMessage) error {
+	if msg.Recovered {
+		_, err := fmt.Fprintf(s.out, "notify %s: recovered (%s)\n", msg.Repo.Name, msg.Fingerprint)
+		return err
+	}
 	_, err := fmt.Fprintf(s.out, "notify %s: %s\n", msg.Repo.Name, msg.Fingerprint)
 	return err
 }
@@ -42,6 +54,9 @@ func (s *osascriptNotifySender) Send(msg notifyMessage) error {
 	title := "bb sync"
 	subtitle := msg.Repo.Name
 	body := fmt.Sprintf("Unsyncable: %s", msg.Fingerprint)
+	if msg.Recovered {
+		body = fmt.Sprintf("Recovered: %s", msg.Fingerprint)
+	}
 	script := fmt.Sprintf("display notification %s with title %s subtitle %s", applescriptQuote(body), applescriptQuote(title), applescriptQuote(subtitle))
 	out, err := s.runCommand("osascript", "-e", script)
 	if err != nil {
@@ -50,13 +65,61 @@ func (s *osascriptNotifySender) Send(msg notifyMessage) error {
 	return nil
 }
 
+type notifySendNotifySender struct {
+	runCommand func(name string, args ...string) (string, error)
+}
+
+func (s *notifySendNotifySender) Send(msg notifyMessage) error {
+	title, body := desktopNotificationText(msg)
+	out, err := s.runCommand("notify-send", "--app-name=bb", "--urgency=normal", title, body)
+	if err != nil {
+		return fmt.Errorf("notify-send failed: %w: %s", err, strings.TrimSpace(out))
+	}
+	return nil
+}
+
+// dbusNotifySender calls org.freedesktop.Notifications.Notify directly on the
+// session bus through gdbus, so it works without libnotify's notify-send.
+type dbusNotifySender struct {
+	runCommand func(name string, args ...string) (string, error)
+}
+
+func (s *dbusNotifySender) Send(msg notifyMessage) error {
+	title, body := desktopNotificationText(msg)
+	out, err := s.runCommand(
+		"gdbus", "call", "--session",
+		"--dest", "org.freedesktop.Notifications",
+		"--object-path", "/org/freedesktop/Notifications",
+		"--method", "org.freedesktop.Notifications.Notify",
+		gvariantQuote("bb"),
+		"uint32 0",
+		gvariantQuote(""),
+		gvariantQuote(title),
+		gvariantQuote(body),
+		"@as []",
+		"@a{sv} {}",
+		"int32 -1",
+	)
+	if err != nil {
+		return fmt.Errorf("dbus notify failed: %w: %s", err, strings.TrimSpace(out))
+	}
+	return nil
+}
+
+func desktopNotificationText(msg notifyMessage) (title string, body string) {
+	if msg.Recovered {
+		return "bb sync", fmt.Sprintf("%s recovered: %s", msg.Repo.Name, msg.Fingerprint)
+	}
+	return "bb sync", fmt.Sprintf("%s unsyncable: %s", msg.Repo.Name, msg.Fingerprint)
+}
+
 func defaultRunCommand(name string, args ...string) (string, error) {
 	cmd := exec.Command(name, args...)
 	out, err := cmd.CombinedOutput()
 	return string(out), err
 }
 
-func newNotifySender(backend string, out io.Writer, runCommand func(name string, args ...string) (string, error)) (notifySender, error) {
+func newNotifySender(backend string, cfg domain.NotifyConfig, out io.Writer, runCommand func(name string, args ...string) (string, error)) (notifySender, error) {
 	switch backend {
 	case notifyBackendStdout:
 		return &stdoutNotifySender{out: out}, nil
@@ -65,8 +128,20 @@ func newNotifySender(backend string, out io.Writer, runCommand func(name string,
 			runCommand = defaultRunCommand
 		}
 		return &osascriptNotifySender{runCommand: runCommand}, nil
+	case notifyBackendNotifySend:
+		if runCommand == nil {
+			runCommand = defaultRunCommand
+		}
+		return &notifySendNotifySender{runCommand: runCommand}, nil
+	case notifyBackendDBus:
+		if runCommand == nil {
+			runCommand = defaultRunCommand
+		}
+		return &dbusNotifySender{runCommand: runCommand}, nil
+	case notifyBackendWebhook:
+		return newWebhookNotifySender(cfg.Webhook)
 	default:
-		return nil, fmt.Errorf("invalid notify backend %q (supported: %s, %s)", backend, notifyBackendStdout, notifyBackendOSAScript)
+		return nil, fmt.Errorf("invalid notify backend %q (supported: %s)", backend, strings.Join(supportedNotifyBackends(), ", "))
 	}
 }
 
@@ -75,25 +150,44 @@ func (a *App) resolveNotifyBackend(override string) (string, error) {
 }
 
 func (a *App) resolveNotifyBackendWithDefault(override string, defaultBackend string) (string, error) {
-	backend := strings.ToLower(strings.TrimSpace(override))
+	backend := strings.TrimSpace(override)
 	if backend == "" {
-		getenv := os.Getenv
-		if a.Getenv != nil {
-			getenv = a.Getenv
-		}
-		backend = strings.ToLower(strings.TrimSpace(getenv(notifyBackendEnvVar)))
+		backend = strings.TrimSpace(a.notifyBackendEnv())
 	}
 	if backend == "" {
-		backend = strings.ToLower(strings.TrimSpace(defaultBackend))
+		backend = defaultBackend
 	}
+	return normalizeNotifyBackend(backend)
+}
+
+// notifyBackendOverridden reports whether the run has an explicit backend from
+// the flag or BB_NOTIFY_BACKEND, which takes precedence over notify.backends.
+func (a *App) notifyBackendOverridden(override string) bool {
+	return strings.TrimSpace(override) != "" || strings.TrimSpace(a.notifyBackendEnv()) != ""
+}
+
+func (a *App) notifyBackendEnv() string {
+	getenv := os.Getenv
+	if a.Getenv != nil {
+		getenv = a.Getenv
+	}
+	return getenv(notifyBackendEnvVar)
+}
+
+func normalizeNotifyBackend(raw string) (string, error) {
+	backend := strings.ToLower(strings.TrimSpace(raw))
 	switch backend {
-	case notifyBackendStdout, notifyBackendOSAScript:
+	case notifyBackendStdout, notifyBackendOSAScript, notifyBackendNotifySend, notifyBackendDBus, notifyBackendWebhook:
 		return backend, nil
 	default:
 		return "", fmt.Errorf("invalid notify backend %q", backend)
 	}
 }
 
+func supportedNotifyBackends() []string {
+	return []string{notifyBackendStdout, notifyBackendOSAScript, notifyBackendNotifySend, notifyBackendDBus, notifyBackendWebhook}
+}
+
 func applescriptQuote(value string) string {
 	replacer := strings.NewReplacer(
 		`\\`, `\\\\`,
@@ -104,6 +198,23 @@ func applescriptQuote(value string) string {
 	return `"` + replacer.Replace(value) + `"`
 }
 
+// gvariantQuote renders value as a GVariant text-format string literal.
+func gvariantQuote(value string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		`'`, `\'`,
+		"\n", `\n`,
+		"\r", "",
+	)
+	return "'" + replacer.Replace(value) + "'"
+}
+
+// notifyLastSentCacheKey scopes dedupe/throttle state to a backend so each
+// backend tracks what it has already delivered.
+func notifyLastSentCacheKey(backend string, rec domain.MachineRepoRecord) string {
+	return backend + "|" + notifyCacheKey(rec)
+}
+
 func notifyFailureCacheKey(backend string, rec domain.MachineRepoRecord) string {
 	return backend + "|" + notifyCacheKey(rec)
 }
@@ -28,7 +28,7 @@ func TestNotifyUnsyncableUsesExplicitBackend(t *testing.T) {
 	paths := state.NewPaths(t.TempDir())
 	a := New(paths, &bytes.Buffer{}, &bytes.Buffer{})
 	sender := &fakeNotifySender{name: notifyBackendOSAScript}
-	a.NewNotifySender = func(name string) (notifySender, error) {
+	a.NewNotifySender = func(name string, _ domain.NotifyConfig) (notifySender, error) {
 		if name != notifyBackendOSAScript {
 			t.Fatalf("backend name = %q, want %q", name, notifyBackendOSAScript)
 		}
@@ -36,12 +36,12 @@ func TestNotifyUnsyncableUsesExplicitBackend(t *testing.T) {
 	}
 
 	cfg := state.DefaultConfig()
-	err := a.notifyUnsyncable(cfg, []domain.MachineRepoRecord{{
+	err := a.notifyUnsyncable(cfg, "machine-a", []domain.MachineRepoRecord{{
 		RepoKey:           "software/api",
 		Name:              "api",
 		Syncable:          false,
 		UnsyncableReasons: []domain.UnsyncableReason{domain.ReasonDirtyTracked},
-	}}, notifyBackendOSAScript)
+	}}, nil, notifyBackendOSAScript)
 	if err != nil {
 		t.Fatalf("notifyUnsyncable failed: %v", err)
 	}
@@ -56,12 +56,12 @@ func TestNotifyUnsyncableInvalidBackend(t *testing.T) {
 	paths := state.NewPaths(t.TempDir())
 	a := New(paths, &bytes.Buffer{}, &bytes.Buffer{})
 	cfg := state.DefaultConfig()
-	err := a.notifyUnsyncable(cfg, []domain.MachineRepoRecord{{
+	err := a.notifyUnsyncable(cfg, "machine-a", []domain.MachineRepoRecord{{
 		RepoKey:           "software/api",
 		Name:              "api",
 		Syncable:          false,
 		UnsyncableReasons: []domain.UnsyncableReason{domain.ReasonDirtyTracked},
-	}}, "invalid-backend")
+	}}, nil, "invalid-backend")
 	if err == nil {
 		t.Fatal("expected error for invalid backend")
 	}
@@ -81,7 +81,7 @@ func TestNotifyUnsyncablePersistsAndClearsDeliveryFailures(t *testing.T) {
 	a.Now = func() time.Time { return now }
 
 	currentSender := &fakeNotifySender{name: notifyBackendStdout, sendErr: errors.New("notify failed")}
-	a.NewNotifySender = func(name string) (notifySender, error) {
+	a.NewNotifySender = func(name string, _ domain.NotifyConfig) (notifySender, error) {
 		if name != notifyBackendStdout {
 			t.Fatalf("backend name = %q, want %q", name, notifyBackendStdout)
 		}
@@ -96,7 +96,7 @@ func TestNotifyUnsyncablePersistsAndClearsDeliveryFailures(t *testing.T) {
 		UnsyncableReasons: []domain.UnsyncableReason{domain.ReasonDirtyTracked},
 	}
 	cfg := state.DefaultConfig()
-	if err := a.notifyUnsyncable(cfg, []domain.MachineRepoRecord{record}, notifyBackendStdout); err != nil {
+	if err := a.notifyUnsyncable(cfg, "machine-a", []domain.MachineRepoRecord{record}, nil, notifyBackendStdout); err != nil {
 		t.Fatalf("notifyUnsyncable failed: %v", err)
 	}
 
@@ -108,13 +108,13 @@ func TestNotifyUnsyncablePersistsAndClearsDeliveryFailures(t *testing.T) {
 	if _, ok := cache.DeliveryFailures[failureKey]; !ok {
 		t.Fatalf("expected delivery failure for key %q", failureKey)
 	}
-	if _, ok := cache.LastSent[notifyCacheKey(record)]; ok {
+	if _, ok := cache.LastSent[notifyLastSentCacheKey(notifyBackendStdout, record)]; ok {
 		t.Fatalf("did not expect last_sent entry for failed delivery")
 	}
 
 	currentSender = &fakeNotifySender{name: notifyBackendStdout}
 	a.Now = func() time.Time { return now.Add(time.Minute) }
-	if err := a.notifyUnsyncable(cfg, []domain.MachineRepoRecord{record}, notifyBackendStdout); err != nil {
+	if err := a.notifyUnsyncable(cfg, "machine-a", []domain.MachineRepoRecord{record}, nil, notifyBackendStdout); err != nil {
 		t.Fatalf("notifyUnsyncable failed on retry: %v", err)
 	}
 
@@ -125,7 +125,7 @@ func TestNotifyUnsyncablePersistsAndClearsDeliveryFailures(t *testing.T) {
 	if _, ok := cache.DeliveryFailures[failureKey]; ok {
 		t.Fatalf("expected delivery failure to clear for key %q", failureKey)
 	}
-	if _, ok := cache.LastSent[notifyCacheKey(record)]; !ok {
+	if _, ok := cache.LastSent[notifyLastSentCacheKey(notifyBackendStdout, record)]; !ok {
 		t.Fatalf("expected last_sent entry after successful delivery")
 	}
 }
@@ -157,3 +157,316 @@ func TestResolveNotifyBackendExplicitWinsEnv(t *testing.T) {
 		t.Fatalf("backend = %q, want %q", got, notifyBackendStdout)
 	}
 }
+
+func TestNotifyUnsyncableRoutesToConfiguredBackends(t *testing.T) {
+	t.Parallel()
+
+	paths := state.NewPaths(t.TempDir())
+	a := New(paths, &bytes.Buffer{}, &bytes.Buffer{})
+	a.Getenv = func(string) string { return "" }
+	now := time.Date(2026, 2, 15, 12, 0, 0, 0, time.UTC)
+	a.Now = func() time.Time { return now }
+
+	senders := map[string]*fakeNotifySender{
+		notifyBackendStdout:  {name: notifyBackendStdout},
+		notifyBackendWebhook: {name: notifyBackendWebhook},
+	}
+	a.NewNotifySender = func(name string, _ domain.NotifyConfig) (notifySender, error) {
+		sender, ok := senders[name]
+		if !ok {
+			t.Fatalf("unexpected backend %q", name)
+		}
+		return sender, nil
+	}
+
+	cfg := state.DefaultConfig()
+	cfg.Notify.Backends = []domain.NotifyBackendConfig{
+		{Name: notifyBackendStdout, ExcludeReasons: []domain.UnsyncableReason{domain.ReasonDirtyUntracked}},
+		{Name: notifyBackendWebhook, Reasons: []domain.UnsyncableReason{domain.ReasonDiverged}},
+	}
+	untracked := domain.MachineRepoRecord{
+		RepoKey:           "software/docs",
+		Name:              "docs",
+		UnsyncableReasons: []domain.UnsyncableReason{domain.ReasonDirtyUntracked},
+	}
+	diverged := domain.MachineRepoRecord{
+		RepoKey:           "software/api",
+		Name:              "api",
+		UnsyncableReasons: []domain.UnsyncableReason{domain.ReasonDiverged, domain.ReasonDirtyUntracked},
+	}
+	if err := a.notifyUnsyncable(cfg, "machine-a", []domain.MachineRepoRecord{untracked, diverged}, nil, ""); err != nil {
+		t.Fatalf("notifyUnsyncable failed: %v", err)
+	}
+
+	if got := len(senders[notifyBackendStdout].sent); got != 1 {
+		t.Fatalf("stdout sent count = %d, want 1", got)
+	}
+	if got := senders[notifyBackendStdout].sent[0].Fingerprint; got != "diverged" {
+		t.Fatalf("stdout fingerprint = %q, want diverged", got)
+	}
+	if got := len(senders[notifyBackendWebhook].sent); got != 1 {
+		t.Fatalf("webhook sent count = %d, want 1", got)
+	}
+	if got := senders[notifyBackendWebhook].sent[0].Fingerprint; got != "diverged+dirty_untracked" && got != "dirty_untracked+diverged" {
+		t.Fatalf("webhook fingerprint = %q", got)
+	}
+
+	cache, err := state.LoadNotifyCache(paths)
+	if err != nil {
+		t.Fatalf("load notify cache: %v", err)
+	}
+	for _, backend := range []string{notifyBackendStdout, notifyBackendWebhook} {
+		if _, ok := cache.LastSent[notifyLastSentCacheKey(backend, diverged)]; !ok {
+			t.Fatalf("expected last_sent entry for backend %s", backend)
+		}
+	}
+
+	// A failing backend must not affect dedupe state of the other one.
+	senders[notifyBackendWebhook].sendErr = errors.New("boom")
+	diverged.UnsyncableReasons = []domain.UnsyncableReason{domain.ReasonDiverged, domain.ReasonDirtyTracked}
+	a.Now = func() time.Time { return now.Add(time.Hour) }
+	if err := a.notifyUnsyncable(cfg, "machine-a", []domain.MachineRepoRecord{diverged}, nil, ""); err != nil {
+		t.Fatalf("notifyUnsyncable failed: %v", err)
+	}
+	cache, err = state.LoadNotifyCache(paths)
+	if err != nil {
+		t.Fatalf("load notify cache: %v", err)
+	}
+	if got := cache.LastSent[notifyLastSentCacheKey(notifyBackendStdout, diverged)].Fingerprint; got != "diverged+dirty_tracked" && got != "dirty_tracked+diverged" {
+		t.Fatalf("stdout fingerprint after change = %q", got)
+	}
+	if _, ok := cache.DeliveryFailures[notifyFailureCacheKey(notifyBackendWebhook, diverged)]; !ok {
+		t.Fatalf("expected webhook delivery failure")
+	}
+	if _, ok := cache.DeliveryFailures[notifyFailureCacheKey(notifyBackendStdout, diverged)]; ok {
+		t.Fatalf("did not expect stdout delivery failure")
+	}
+}
+
+func TestNotifyUnsyncableOverrideIgnoresConfiguredBackends(t *testing.T) {
+	t.Parallel()
+
+	paths := state.NewPaths(t.TempDir())
+	a := New(paths, &bytes.Buffer{}, &bytes.Buffer{})
+	var names []string
+	a.NewNotifySender = func(name string, _ domain.NotifyConfig) (notifySender, error) {
+		names = append(names, name)
+		return &fakeNotifySender{name: name}, nil
+	}
+
+	cfg := state.DefaultConfig()
+	cfg.Notify.Backends = []domain.NotifyBackendConfig{{Name: notifyBackendWebhook}, {Name: notifyBackendNotifySend}}
+	record := domain.MachineRepoRecord{RepoKey: "software/api", Name: "api", UnsyncableReasons: []domain.UnsyncableReason{domain.ReasonDiverged}}
+	if err := a.notifyUnsyncable(cfg, "machine-a", []domain.MachineRepoRecord{record}, nil, notifyBackendStdout); err != nil {
+		t.Fatalf("notifyUnsyncable failed: %v", err)
+	}
+	if len(names) != 1 || names[0] != notifyBackendStdout {
+		t.Fatalf("backends = %v, want [stdout]", names)
+	}
+}
+
+func TestNotifyUnsyncableMigratesLegacyLastSent(t *testing.T) {
+	t.Parallel()
+
+	paths := state.NewPaths(t.TempDir())
+	a := New(paths, &bytes.Buffer{}, &bytes.Buffer{})
+	now := time.Date(2026, 2, 15, 12, 0, 0, 0, time.UTC)
+	a.Now = func() time.Time { return now }
+	sender := &fakeNotifySender{name: notifyBackendStdout}
+	a.NewNotifySender = func(string, domain.NotifyConfig) (notifySender, error) { return sender, nil }
+
+	record := domain.MachineRepoRecord{RepoKey: "software/api", Name: "api", UnsyncableReasons: []domain.UnsyncableReason{domain.ReasonDiverged}}
+	cache, err := state.LoadNotifyCache(paths)
+	if err != nil {
+		t.Fatalf("load notify cache: %v", err)
+	}
+	cache.LastSent[notifyCacheKey(record)] = domain.NotifyCacheEntry{Fingerprint: "diverged", SentAt: now.Add(-time.Hour)}
+	if err := state.SaveNotifyCache(paths, cache); err != nil {
+		t.Fatalf("save notify cache: %v", err)
+	}
+
+	cfg := state.DefaultConfig()
+	if err := a.notifyUnsyncable(cfg, "machine-a", []domain.MachineRepoRecord{record}, nil, notifyBackendStdout); err != nil {
+		t.Fatalf("notifyUnsyncable failed: %v", err)
+	}
+	if len(sender.sent) != 0 {
+		t.Fatalf("expected legacy entry to dedupe, sent %d", len(sender.sent))
+	}
+	cache, err = state.LoadNotifyCache(paths)
+	if err != nil {
+		t.Fatalf("load notify cache: %v", err)
+	}
+	if _, ok := cache.LastSent[notifyCacheKey(record)]; ok {
+		t.Fatalf("expected legacy last_sent key to be removed")
+	}
+	if _, ok := cache.LastSent[notifyLastSentCacheKey(notifyBackendStdout, record)]; !ok {
+		t.Fatalf("expected backend-scoped last_sent key")
+	}
+}
+
+func TestNotifyUnsyncableRecoveredPrunesAndNotifies(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		recovered     bool
+		wantRecovered int
+	}{
+		{name: "opt-in sends resolution", recovered: true, wantRecovered: 1},
+		{name: "default only prunes", recovered: false, wantRecovered: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			paths := state.NewPaths(t.TempDir())
+			a := New(paths, &bytes.Buffer{}, &bytes.Buffer{})
+			now := time.Date(2026, 2, 15, 12, 0, 0, 0, time.UTC)
+			a.Now = func() time.Time { return now }
+			sender := &fakeNotifySender{name: notifyBackendStdout}
+			a.NewNotifySender = func(string, domain.NotifyConfig) (notifySender, error) { return sender, nil }
+
+			cfg := state.DefaultConfig()
+			cfg.Notify.Recovered = tt.recovered
+			broken := domain.MachineRepoRecord{
+				RepoKey:           "software/api",
+				Name:              "api",
+				Syncable:          false,
+				UnsyncableReasons: []domain.UnsyncableReason{domain.ReasonDiverged},
+			}
+			if err := a.notifyUnsyncable(cfg, "machine-a", []domain.MachineRepoRecord{broken}, nil, notifyBackendStdout); err != nil {
+				t.Fatalf("notifyUnsyncable failed: %v", err)
+			}
+
+			fixed := broken
+			fixed.Syncable = true
+			fixed.UnsyncableReasons = nil
+			transitioned := map[string]bool{repoRecordIdentityKey(fixed): true}
+			a.Now = func() time.Time { return now.Add(time.Minute) }
+			if err := a.notifyUnsyncable(cfg, "machine-a", []domain.MachineRepoRecord{fixed}, transitioned, notifyBackendStdout); err != nil {
+				t.Fatalf("notifyUnsyncable failed: %v", err)
+			}
+
+			recovered := 0
+			for _, msg := range sender.sent {
+				if msg.Recovered {
+					recovered++
+					if msg.Fingerprint != "diverged" {
+						t.Fatalf("recovered fingerprint = %q, want diverged", msg.Fingerprint)
+					}
+				}
+			}
+			if recovered != tt.wantRecovered {
+				t.Fatalf("recovered messages = %d, want %d", recovered, tt.wantRecovered)
+			}
+			cache, err := state.LoadNotifyCache(paths)
+			if err != nil {
+				t.Fatalf("load notify cache: %v", err)
+			}
+			if _, ok := cache.LastSent[notifyLastSentCacheKey(notifyBackendStdout, fixed)]; ok {
+				t.Fatal("expected last_sent entry to be pruned after recovery")
+			}
+
+			// The same regression within the throttle window notifies again.
+			sent := len(sender.sent)
+			a.Now = func() time.Time { return now.Add(2 * time.Minute) }
+			if err := a.notifyUnsyncable(cfg, "machine-a", []domain.MachineRepoRecord{broken}, nil, notifyBackendStdout); err != nil {
+				t.Fatalf("notifyUnsyncable failed: %v", err)
+			}
+			if len(sender.sent) != sent+1 || sender.sent[len(sender.sent)-1].Recovered {
+				t.Fatalf("expected regression to notify again, sent=%d", len(sender.sent)-sent)
+			}
+		})
+	}
+}
+
+func TestNotifyUnsyncableRecoveredSkipsReposNeverNotified(t *testing.T) {
+	t.Parallel()
+
+	paths := state.NewPaths(t.TempDir())
+	a := New(paths, &bytes.Buffer{}, &bytes.Buffer{})
+	sender := &fakeNotifySender{name: notifyBackendStdout}
+	a.NewNotifySender = func(string, domain.NotifyConfig) (notifySender, error) { return sender, nil }
+
+	cfg := state.DefaultConfig()
+	cfg.Notify.Recovered = true
+	record := domain.MachineRepoRecord{RepoKey: "software/api", Name: "api", Syncable: true}
+	transitioned := map[string]bool{repoRecordIdentityKey(record): true}
+	if err := a.notifyUnsyncable(cfg, "machine-a", []domain.MachineRepoRecord{record}, transitioned, notifyBackendStdout); err != nil {
+		t.Fatalf("notifyUnsyncable failed: %v", err)
+	}
+	if len(sender.sent) != 0 {
+		t.Fatalf("sent count = %d, want 0", len(sender.sent))
+	}
+}
+
+func TestNotifyUnsyncableKeepsEntriesOfReposThatDidNotTransition(t *testing.T) {
+	t.Parallel()
+
+	paths := state.NewPaths(t.TempDir())
+	a := New(paths, &bytes.Buffer{}, &bytes.Buffer{})
+	sender := &fakeNotifySender{name: notifyBackendStdout}
+	a.NewNotifySender = func(string, domain.NotifyConfig) (notifySender, error) { return sender, nil }
+
+	record := domain.MachineRepoRecord{RepoKey: "software/api", Name: "api", Syncable: true}
+	cache := domain.NotifyCacheFile{
+		LastSent:         map[string]domain.NotifyCacheEntry{notifyLastSentCacheKey(notifyBackendStdout, record): {Fingerprint: "diverged"}},
+		DeliveryFailures: map[string]domain.NotifyDeliveryFailure{},
+	}
+	if err := state.SaveNotifyCache(paths, cache); err != nil {
+		t.Fatalf("SaveNotifyCache: %v", err)
+	}
+
+	cfg := state.DefaultConfig()
+	cfg.Notify.Recovered = true
+	if err := a.notifyUnsyncable(cfg, "machine-a", []domain.MachineRepoRecord{record}, nil, notifyBackendStdout); err != nil {
+		t.Fatalf("notifyUnsyncable failed: %v", err)
+	}
+	if len(sender.sent) != 0 {
+		t.Fatalf("sent = %+v, want no recovered message without a transition", sender.sent)
+	}
+	cache, err := state.LoadNotifyCache(paths)
+	if err != nil {
+		t.Fatalf("LoadNotifyCache: %v", err)
+	}
+	if _, ok := cache.LastSent[notifyLastSentCacheKey(notifyBackendStdout, record)]; !ok {
+		t.Fatal("entry pruned for a repo that did not transition")
+	}
+}
+
+func TestPruneNotifyCacheForRecoveredClearsOnlyTransitionedRepos(t *testing.T) {
+	t.Parallel()
+
+	paths := state.NewPaths(t.TempDir())
+	a := New(paths, &bytes.Buffer{}, &bytes.Buffer{})
+	recovered := domain.MachineRepoRecord{RepoKey: "software/api", Name: "api", Syncable: true}
+	steady := domain.MachineRepoRecord{RepoKey: "software/web", Name: "web", Syncable: true}
+	cache := domain.NotifyCacheFile{
+		LastSent: map[string]domain.NotifyCacheEntry{
+			notifyLastSentCacheKey(notifyBackendStdout, recovered): {Fingerprint: "diverged"},
+			notifyLastSentCacheKey(notifyBackendStdout, steady):    {Fingerprint: "diverged"},
+		},
+		DeliveryFailures: map[string]domain.NotifyDeliveryFailure{
+			notifyFailureCacheKey(notifyBackendWebhook, recovered): {Backend: notifyBackendWebhook},
+		},
+	}
+	if err := state.SaveNotifyCache(paths, cache); err != nil {
+		t.Fatalf("SaveNotifyCache: %v", err)
+	}
+
+	a.pruneNotifyCacheForRecovered([]domain.MachineRepoRecord{recovered, steady}, map[string]bool{repoRecordIdentityKey(recovered): true})
+
+	cache, err := state.LoadNotifyCache(paths)
+	if err != nil {
+		t.Fatalf("LoadNotifyCache: %v", err)
+	}
+	if _, ok := cache.LastSent[notifyLastSentCacheKey(notifyBackendStdout, recovered)]; ok {
+		t.Fatal("entry of the recovered repo survived")
+	}
+	if len(cache.DeliveryFailures) != 0 {
+		t.Fatalf("delivery failures = %+v, want the recovered repo's cleared", cache.DeliveryFailures)
+	}
+	if _, ok := cache.LastSent[notifyLastSentCacheKey(notifyBackendStdout, steady)]; !ok {
+		t.Fatal("entry of a repo that did not transition was pruned")
+	}
+}
@@ -0,0 +1,119 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"bb-project/internal/domain"
+	"bb-project/internal/state"
+)
+
+func TestNotifySendSenderRunsNotifySend(t *testing.T) {
+	t.Parallel()
+
+	var calls [][]string
+	sender, err := newNotifySender(notifyBackendNotifySend, domain.NotifyConfig{}, &bytes.Buffer{}, func(name string, args ...string) (string, error) {
+		calls = append(calls, append([]string{name}, args...))
+		return "", nil
+	})
+	if err != nil {
+		t.Fatalf("newNotifySender failed: %v", err)
+	}
+	err = sender.Send(notifyMessage{
+		Repo:        domain.MachineRepoRecord{Name: "api"},
+		Fingerprint: "dirty_tracked",
+	})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	want := []string{"notify-send", "--app-name=bb", "--urgency=normal", "bb sync", "api unsyncable: dirty_tracked"}
+	if len(calls) != 1 || strings.Join(calls[0], "\x00") != strings.Join(want, "\x00") {
+		t.Fatalf("calls = %q, want [%q]", calls, want)
+	}
+}
+
+func TestDBusSenderCallsNotificationsService(t *testing.T) {
+	t.Parallel()
+
+	var calls [][]string
+	sender, err := newNotifySender(notifyBackendDBus, domain.NotifyConfig{}, &bytes.Buffer{}, func(name string, args ...string) (string, error) {
+		calls = append(calls, append([]string{name}, args...))
+		return "(uint32 7,)", nil
+	})
+	if err != nil {
+		t.Fatalf("newNotifySender failed: %v", err)
+	}
+	err = sender.Send(notifyMessage{
+		Repo:        domain.MachineRepoRecord{Name: "it's"},
+		Fingerprint: "dirty_tracked",
+	})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if len(calls) != 1 {
+		t.Fatalf("call count = %d, want 1", len(calls))
+	}
+	got := strings.Join(calls[0], " ")
+	for _, want := range []string{
+		"gdbus call --session",
+		"--dest org.freedesktop.Notifications",
+		"--object-path /org/freedesktop/Notifications",
+		"--method org.freedesktop.Notifications.Notify",
+		`'bb sync' 'it\'s unsyncable: dirty_tracked'`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in command, got %q", want, got)
+		}
+	}
+}
+
+func TestDesktopSenderFailureIsRecorded(t *testing.T) {
+	t.Parallel()
+
+	paths := state.NewPaths(t.TempDir())
+	a := New(paths, &bytes.Buffer{}, &bytes.Buffer{})
+	a.RunCommand = func(name string, args ...string) (string, error) {
+		return "Cannot autolaunch D-Bus without X11 $DISPLAY", errors.New("exit status 1")
+	}
+
+	record := domain.MachineRepoRecord{
+		RepoKey:           "software/api",
+		Name:              "api",
+		Syncable:          false,
+		UnsyncableReasons: []domain.UnsyncableReason{domain.ReasonDirtyTracked},
+	}
+	cfg := state.DefaultConfig()
+	if err := a.notifyUnsyncable(cfg, "machine-a", []domain.MachineRepoRecord{record}, nil, notifyBackendNotifySend); err != nil {
+		t.Fatalf("notifyUnsyncable failed: %v", err)
+	}
+
+	cache, err := state.LoadNotifyCache(paths)
+	if err != nil {
+		t.Fatalf("load notify cache: %v", err)
+	}
+	failure, ok := cache.DeliveryFailures[notifyFailureCacheKey(notifyBackendNotifySend, record)]
+	if !ok {
+		t.Fatalf("expected delivery failure for notify-send backend")
+	}
+	if !strings.Contains(failure.Error, "notify-send failed") {
+		t.Fatalf("failure error = %q", failure.Error)
+	}
+}
+
+func TestGVariantQuote(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"plain":       "'plain'",
+		"it's":        `'it\'s'`,
+		`back\slash`:  `'back\\slash'`,
+		"line\nbreak": `'line\nbreak'`,
+	}
+	for in, want := range tests {
+		if got := gvariantQuote(in); got != want {
+			t.Fatalf("gvariantQuote(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -5,16 +5,25 @@ import (
 	"sort"
 	"strings"
 
+	"bb-project/internal/domain"
 	"bb-project/internal/state"
 )
 
-func (a *App) reportNotifyDeliveryFailures() (int, error) {
+// notifyDeliveryWarning is one recorded delivery failure with a display label
+// for the repo it was about.
+type notifyDeliveryWarning struct {
+	Key       string
+	RepoLabel string
+	Failure   domain.NotifyDeliveryFailure
+}
+
+func (a *App) loadNotifyDeliveryWarnings() ([]notifyDeliveryWarning, error) {
 	cache, err := state.LoadNotifyCache(a.Paths)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 	if len(cache.DeliveryFailures) == 0 {
-		return 0, nil
+		return nil, nil
 	}
 
 	keys := make([]string, 0, len(cache.DeliveryFailures))
@@ -23,6 +32,7 @@ func (a *App) reportNotifyDeliveryFailures() (int, error) {
 	}
 	sort.Strings(keys)
 
+	warnings := make([]notifyDeliveryWarning, 0, len(keys))
 	for _, key := range keys {
 		failure := cache.DeliveryFailures[key]
 		repoLabel := strings.TrimSpace(failure.RepoKey)
@@ -35,15 +45,26 @@ func (a *App) reportNotifyDeliveryFailures() (int, error) {
 		if repoLabel == "" {
 			repoLabel = key
 		}
+		warnings = append(warnings, notifyDeliveryWarning{Key: key, RepoLabel: repoLabel, Failure: failure})
+	}
+	return warnings, nil
+}
+
+func (a *App) reportNotifyDeliveryFailures() (int, error) {
+	warnings, err := a.loadNotifyDeliveryWarnings()
+	if err != nil {
+		return 0, err
+	}
+	for _, warning := range warnings {
 		fmt.Fprintf(a.Stdout,
 			"warning: notification delivery failed backend=%s repo=%s at=%s error=%s\n",
-			strings.TrimSpace(failure.Backend),
-			repoLabel,
-			failure.FailedAt.UTC().Format(timeRFC3339),
-			strings.TrimSpace(failure.Error),
+			strings.TrimSpace(warning.Failure.Backend),
+			warning.RepoLabel,
+			warning.Failure.FailedAt.UTC().Format(timeRFC3339),
+			strings.TrimSpace(warning.Failure.Error),
 		)
 	}
-	return len(keys), nil
+	return len(warnings), nil
 }
 
 const timeRFC3339 = "2006-01-02T15:04:05Z07:00"
@@ -0,0 +1,116 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"bb-project/internal/domain"
+)
+
+const (
+	defaultNotifyWebhookTimeout = 10 * time.Second
+
+	webhookEventUnsyncable = "unsyncable"
+	webhookEventRecovered  = "recovered"
+)
+
+type webhookNotifyPayload struct {
+	Event             string                    `json:"event"`
+	RepoKey           string                    `json:"repo_key"`
+	Name              string                    `json:"name"`
+	Path              string                    `json:"path"`
+	MachineID         string                    `json:"machine_id"`
+	Fingerprint       string                    `json:"fingerprint"`
+	UnsyncableReasons []domain.UnsyncableReason `json:"unsyncable_reasons"`
+}
+
+type webhookNotifySender struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func newWebhookNotifySender(cfg domain.NotifyWebhookConfig) (*webhookNotifySender, error) {
+	if err := validateNotifyWebhookURL(cfg.URL); err != nil {
+		return nil, err
+	}
+	timeout := defaultNotifyWebhookTimeout
+	if cfg.TimeoutSeconds > 0 {
+		timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+	return &webhookNotifySender{
+		url:     strings.TrimSpace(cfg.URL),
+		headers: cfg.Headers,
+		client:  &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (s *webhookNotifySender) Send(msg notifyMessage) error {
+	reasons := msg.Repo.UnsyncableReasons
+	if reasons == nil {
+		reasons = []domain.UnsyncableReason{}
+	}
+	event := webhookEventUnsyncable
+	if msg.Recovered {
+		event = webhookEventRecovered
+	}
+	body, err := json.Marshal(webhookNotifyPayload{
+		Event:             event,
+		RepoKey:           msg.Repo.RepoKey,
+		Name:              msg.Repo.Name,
+		Path:              msg.Repo.Path,
+		MachineID:         msg.MachineID,
+		Fingerprint:       msg.Fingerprint,
+		UnsyncableReasons: reasons,
+	})
+	if err != nil {
+		return fmt.Errorf("encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook notify failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "bb-project")
+	for name, value := range s.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook notify failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook notify failed: status %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+func validateNotifyWebhookURL(raw string) error {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return errors.New("notify.webhook.url is required for the webhook backend")
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("notify.webhook.url is invalid: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("notify.webhook.url must use http or https")
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("notify.webhook.url must include a host")
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"bb-project/internal/domain"
+	"bb-project/internal/state"
+)
+
+func TestWebhookNotifySenderPostsPayload(t *testing.T) {
+	t.Parallel()
+
+	var gotPayload webhookNotifyPayload
+	var gotAuth string
+	var gotContentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		gotAuth = r.Header.Get("Authorization")
+		gotContentType = r.Header.Get("Content-Type")
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &gotPayload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	paths := state.NewPaths(t.TempDir())
+	a := New(paths, &bytes.Buffer{}, &bytes.Buffer{})
+	a.Now = func() time.Time { return time.Date(2026, 2, 15, 12, 0, 0, 0, time.UTC) }
+
+	cfg := state.DefaultConfig()
+	cfg.Notify.Webhook = domain.NotifyWebhookConfig{
+		URL:     server.URL + "/hook",
+		Headers: map[string]string{"Authorization": "Bearer secret"},
+	}
+	record := domain.MachineRepoRecord{
+		RepoKey:           "software/api",
+		Name:              "api",
+		Path:              "/tmp/software/api",
+		Syncable:          false,
+		UnsyncableReasons: []domain.UnsyncableReason{domain.ReasonDiverged, domain.ReasonDirtyTracked},
+	}
+	if err := a.notifyUnsyncable(cfg, "machine-a", []domain.MachineRepoRecord{record}, nil, notifyBackendWebhook); err != nil {
+		t.Fatalf("notifyUnsyncable failed: %v", err)
+	}
+
+	if gotAuth != "Bearer secret" {
+		t.Fatalf("authorization header = %q, want %q", gotAuth, "Bearer secret")
+	}
+	if gotContentType != "application/json" {
+		t.Fatalf("content-type = %q, want application/json", gotContentType)
+	}
+	if gotPayload.RepoKey != "software/api" || gotPayload.Name != "api" || gotPayload.Path != "/tmp/software/api" {
+		t.Fatalf("unexpected repo fields in payload: %#v", gotPayload)
+	}
+	if gotPayload.Event != webhookEventUnsyncable {
+		t.Fatalf("event = %q, want %q", gotPayload.Event, webhookEventUnsyncable)
+	}
+	if gotPayload.MachineID != "machine-a" {
+		t.Fatalf("machine_id = %q, want machine-a", gotPayload.MachineID)
+	}
+	if gotPayload.Fingerprint != "dirty_tracked+diverged" {
+		t.Fatalf("fingerprint = %q, want %q", gotPayload.Fingerprint, "dirty_tracked+diverged")
+	}
+	if len(gotPayload.UnsyncableReasons) != 2 {
+		t.Fatalf("unsyncable_reasons = %v, want 2 entries", gotPayload.UnsyncableReasons)
+	}
+
+	cache, err := state.LoadNotifyCache(paths)
+	if err != nil {
+		t.Fatalf("load notify cache: %v", err)
+	}
+	if _, ok := cache.LastSent[notifyLastSentCacheKey(notifyBackendWebhook, record)]; !ok {
+		t.Fatal("expected last_sent entry after successful webhook delivery")
+	}
+}
+
+func TestWebhookNotifySenderRecordsDeliveryFailure(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "upstream down", http.StatusBadGateway)
+	}))
+	t.Cleanup(server.Close)
+
+	paths := state.NewPaths(t.TempDir())
+	a := New(paths, &bytes.Buffer{}, &bytes.Buffer{})
+
+	cfg := state.DefaultConfig()
+	cfg.Notify.Webhook.URL = server.URL
+	record := domain.MachineRepoRecord{
+		RepoKey:           "software/api",
+		Name:              "api",
+		Syncable:          false,
+		UnsyncableReasons: []domain.UnsyncableReason{domain.ReasonDirtyTracked},
+	}
+	if err := a.notifyUnsyncable(cfg, "machine-a", []domain.MachineRepoRecord{record}, nil, notifyBackendWebhook); err != nil {
+		t.Fatalf("notifyUnsyncable failed: %v", err)
+	}
+
+	cache, err := state.LoadNotifyCache(paths)
+	if err != nil {
+		t.Fatalf("load notify cache: %v", err)
+	}
+	failure, ok := cache.DeliveryFailures[notifyFailureCacheKey(notifyBackendWebhook, record)]
+	if !ok {
+		t.Fatal("expected webhook delivery failure to be recorded")
+	}
+	if failure.Backend != notifyBackendWebhook {
+		t.Fatalf("failure backend = %q, want %q", failure.Backend, notifyBackendWebhook)
+	}
+	if !strings.Contains(failure.Error, "status 502") {
+		t.Fatalf("failure error = %q, want status 502", failure.Error)
+	}
+}
+
+func TestNewNotifySenderWebhookRequiresURL(t *testing.T) {
+	t.Parallel()
+
+	_, err := newNotifySender(notifyBackendWebhook, domain.NotifyConfig{}, &bytes.Buffer{}, nil)
+	if err == nil {
+		t.Fatal("expected error when webhook url is missing")
+	}
+	if !strings.Contains(err.Error(), "notify.webhook.url") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
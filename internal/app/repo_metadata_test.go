@@ -26,7 +26,7 @@ func TestEnsureRepoMetadataSkipsUnchangedWrite(t *testing.T) {
 	visibility := domain.VisibilityPrivate
 	preferredCatalog := "references"
 
-	_, created, err := a.ensureRepoMetadata(cfg, repoKey, name, origin, visibility, preferredCatalog)
+	_, created, err := a.ensureRepoMetadata(cfg, repoKey, name, origin, visibility, preferredCatalog, a.logf)
 	if err != nil {
 		t.Fatalf("first ensureRepoMetadata: %v", err)
 	}
@@ -40,7 +40,7 @@ func TestEnsureRepoMetadataSkipsUnchangedWrite(t *testing.T) {
 		t.Fatalf("set old modtime: %v", err)
 	}
 
-	_, created, err = a.ensureRepoMetadata(cfg, repoKey, name, origin, visibility, preferredCatalog)
+	_, created, err = a.ensureRepoMetadata(cfg, repoKey, name, origin, visibility, preferredCatalog, a.logf)
 	if err != nil {
 		t.Fatalf("second ensureRepoMetadata: %v", err)
 	}
@@ -83,7 +83,7 @@ func TestEnsureRepoMetadataWritesWhenBackfillNeeded(t *testing.T) {
 	origin := "git@github.com:you/netclode.git"
 	visibility := domain.VisibilityPrivate
 	preferredCatalog := "references"
-	meta, created, err := a.ensureRepoMetadata(cfg, repoKey, name, origin, visibility, preferredCatalog)
+	meta, created, err := a.ensureRepoMetadata(cfg, repoKey, name, origin, visibility, preferredCatalog, a.logf)
 	if err != nil {
 		t.Fatalf("ensureRepoMetadata: %v", err)
 	}
@@ -136,6 +136,7 @@ func TestEnsureRepoMetadataNormalizesInvalidPushAccess(t *testing.T) {
 		"git@github.com:you/netclode.git",
 		domain.VisibilityPrivate,
 		"references",
+		a.logf,
 	)
 	if err != nil {
 		t.Fatalf("ensureRepoMetadata: %v", err)
@@ -232,6 +233,11 @@ func TestRunRepoPushAccessSetRejectsInvalidValue(t *testing.T) {
 func TestShouldProbePushAccessForOrigin(t *testing.T) {
 	t.Parallel()
 
+	cfg := domain.ConfigFile{Forges: map[string]domain.ForgeConfig{
+		"git.example.com":    {Type: domain.ForgeTypeGitea},
+		"gitlab.example.com": {Type: domain.ForgeTypeGitLab},
+	}}
+
 	tests := []struct {
 		name   string
 		origin string
@@ -242,6 +248,8 @@ func TestShouldProbePushAccessForOrigin(t *testing.T) {
 		{name: "github alias host", origin: "git@niieani.github.com:niieani/condu.git", want: true},
 		{name: "file path remote", origin: "/tmp/remotes/you/demo.git", want: true},
 		{name: "non github host", origin: "https://gitflic.ru/project/demo.git", want: false},
+		{name: "configured gitea host", origin: "git@git.example.com:team/demo.git", want: true},
+		{name: "configured gitlab subgroup", origin: "https://gitlab.example.com/group/sub/demo.git", want: true},
 		{name: "empty", origin: "", want: false},
 		{name: "invalid", origin: "::not-a-url::", want: false},
 	}
@@ -251,7 +259,7 @@ func TestShouldProbePushAccessForOrigin(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			if got := shouldProbePushAccessForOrigin(tt.origin); got != tt.want {
+			if got := shouldProbePushAccessForOrigin(cfg, tt.origin); got != tt.want {
 				t.Fatalf("shouldProbePushAccessForOrigin(%q) = %v, want %v", tt.origin, got, tt.want)
 			}
 		})
@@ -275,7 +283,7 @@ func TestLoadRepoMetadataWithPushAccessSkipsProbeWhenNotRequested(t *testing.T)
 		t.Fatalf("save metadata: %v", err)
 	}
 
-	loaded, hasMeta, err := a.loadRepoMetadataWithPushAccess("/tmp/does-not-matter", repoKey, meta.OriginURL, false)
+	loaded, hasMeta, err := a.loadRepoMetadataWithPushAccess(state.DefaultConfig(), "/tmp/does-not-matter", repoKey, meta.OriginURL, false, a.logf)
 	if err != nil {
 		t.Fatalf("loadRepoMetadataWithPushAccess error: %v", err)
 	}
@@ -350,7 +358,7 @@ func TestLoadRepoMetadataWithPushAccessProbesUnknownEvenWhenNotRequested(t *test
 		return `{"viewerPermission":"READ"}`, nil
 	}
 
-	loaded, hasMeta, err := a.loadRepoMetadataWithPushAccess(repoPath, repoKey, meta.OriginURL, false)
+	loaded, hasMeta, err := a.loadRepoMetadataWithPushAccess(state.DefaultConfig(), repoPath, repoKey, meta.OriginURL, false, a.logf)
 	if err != nil {
 		t.Fatalf("loadRepoMetadataWithPushAccess error: %v", err)
 	}
@@ -431,7 +439,7 @@ func TestProbeAndUpdateRepoPushAccessUsesGitHubViewerPermission(t *testing.T) {
 		BranchFollowEnabled: true,
 	}
 
-	updated, changed, err := a.probeAndUpdateRepoPushAccess(repoPath, meta.OriginURL, meta, true)
+	updated, changed, err := a.probeAndUpdateRepoPushAccess(state.DefaultConfig(), repoPath, meta.OriginURL, meta, true, a.logf)
 	if err != nil {
 		t.Fatalf("probeAndUpdateRepoPushAccess error: %v", err)
 	}
@@ -515,7 +523,7 @@ func TestProbeAndUpdateRepoPushAccessUsesGitHubViewerPermissionForReadWriteWitho
 		BranchFollowEnabled: true,
 	}
 
-	updated, changed, err := a.probeAndUpdateRepoPushAccess(repoPath, meta.OriginURL, meta, true)
+	updated, changed, err := a.probeAndUpdateRepoPushAccess(state.DefaultConfig(), repoPath, meta.OriginURL, meta, true, a.logf)
 	if err != nil {
 		t.Fatalf("probeAndUpdateRepoPushAccess error: %v", err)
 	}
@@ -568,3 +576,69 @@ func TestParseGitHubViewerPermissionPushAccess(t *testing.T) {
 		})
 	}
 }
+
+func TestRunRepoBranchPinsAndRestoresFollowing(t *testing.T) {
+	t.Parallel()
+
+	paths := state.NewPaths(t.TempDir())
+	a := New(paths, io.Discard, io.Discard)
+	meta := domain.RepoMetadataFile{RepoKey: "software/demo", Name: "demo", BranchFollowEnabled: true}
+	if err := state.SaveRepoMetadata(paths, meta); err != nil {
+		t.Fatalf("save metadata: %v", err)
+	}
+
+	if code, err := a.RunRepoBranch(RepoBranchOptions{Selector: "software/demo", Pin: "main"}); err != nil || code != 0 {
+		t.Fatalf("pin: code=%d err=%v", code, err)
+	}
+	got, err := state.LoadRepoMetadata(paths, "software/demo")
+	if err != nil {
+		t.Fatalf("load metadata: %v", err)
+	}
+	if got.BranchFollowEnabled || got.PinnedBranch != "main" {
+		t.Fatalf("after pin: follow=%t pinned=%q, want false/main", got.BranchFollowEnabled, got.PinnedBranch)
+	}
+
+	if code, err := a.RunRepoBranch(RepoBranchOptions{Selector: "software/demo", Pin: "bad..name"}); err == nil || code != 2 {
+		t.Fatalf("invalid pin: code=%d err=%v, want code 2 with error", code, err)
+	}
+
+	if code, err := a.RunRepoBranch(RepoBranchOptions{Selector: "software/demo", Follow: true}); err != nil || code != 0 {
+		t.Fatalf("follow: code=%d err=%v", code, err)
+	}
+	got, err = state.LoadRepoMetadata(paths, "software/demo")
+	if err != nil {
+		t.Fatalf("load metadata: %v", err)
+	}
+	if !got.BranchFollowEnabled || got.PinnedBranch != "" {
+		t.Fatalf("after follow: follow=%t pinned=%q, want true/empty", got.BranchFollowEnabled, got.PinnedBranch)
+	}
+}
+
+func TestRunRepoPrimaryRejectsUnknownMachineAndClears(t *testing.T) {
+	t.Parallel()
+
+	paths := state.NewPaths(t.TempDir())
+	a := New(paths, io.Discard, io.Discard)
+	a.Hostname = func() (string, error) { return "host-a", nil }
+	if err := state.SaveConfig(paths, state.DefaultConfig()); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	meta := domain.RepoMetadataFile{RepoKey: "software/demo", Name: "demo", PrimaryMachineID: "desktop"}
+	if err := state.SaveRepoMetadata(paths, meta); err != nil {
+		t.Fatalf("save metadata: %v", err)
+	}
+
+	if code, err := a.RunRepoPrimary(RepoPrimaryOptions{Selector: "software/demo", MachineID: "ghost"}); err == nil || code != 2 {
+		t.Fatalf("unknown machine: code=%d err=%v, want code 2 with error", code, err)
+	}
+	if code, err := a.RunRepoPrimary(RepoPrimaryOptions{Selector: "software/demo", Clear: true}); err != nil || code != 0 {
+		t.Fatalf("clear: code=%d err=%v", code, err)
+	}
+	got, err := state.LoadRepoMetadata(paths, "software/demo")
+	if err != nil {
+		t.Fatalf("load metadata: %v", err)
+	}
+	if got.PrimaryMachineID != "" {
+		t.Fatalf("primary_machine_id = %q, want cleared", got.PrimaryMachineID)
+	}
+}
@@ -172,11 +172,12 @@ func (a *App) runRepoMoveLocked(cfg domain.ConfigFile, machine *domain.MachineFi
 }
 
 func resolveRepoMoveTarget(repo domain.MachineRepoRecord, targetCatalog domain.Catalog, as string) (repoKey string, relative string, repoName string, err error) {
+	layout := domain.EffectiveCatalogLayout(targetCatalog)
 	trimmedAs := strings.TrimSpace(as)
 	if trimmedAs != "" {
 		repoKey, relative, repoName, ok := domain.DeriveRepoKeyFromRelative(targetCatalog, trimmedAs)
 		if !ok {
-			return "", "", "", fmt.Errorf("move target path must match catalog layout depth %d", domain.EffectiveRepoPathDepth(targetCatalog))
+			return "", "", "", fmt.Errorf("move target path must match catalog layout %s", layout)
 		}
 		return repoKey, relative, repoName, nil
 	}
@@ -189,11 +190,20 @@ func resolveRepoMoveTarget(repo domain.MachineRepoRecord, targetCatalog domain.C
 	if parseErr != nil {
 		return "", "", "", fmt.Errorf("cannot parse repo_key %q: %w", oldRepoKey, parseErr)
 	}
-	repoKey, relative, repoName, ok := domain.DeriveRepoKeyFromRelative(targetCatalog, oldRelative)
-	if !ok {
-		return "", "", "", fmt.Errorf("move target path must match catalog layout depth %d; pass --as", domain.EffectiveRepoPathDepth(targetCatalog))
+	if repoKey, relative, repoName, ok := domain.DeriveRepoKeyFromRelative(targetCatalog, oldRelative); ok {
+		return repoKey, relative, repoName, nil
+	}
+	// The old path does not fit the target layout; build one from the
+	// origin's host, owner and name instead.
+	if identity, err := domain.NormalizeOriginIdentity(repo.OriginURL); err == nil {
+		host, owner, name := deriveIdentityOwnerRepo(identity)
+		if relative, ok := layout.Expand(host, owner, name); ok {
+			if repoKey, relative, repoName, ok := domain.DeriveRepoKeyFromRelative(targetCatalog, relative); ok {
+				return repoKey, relative, repoName, nil
+			}
+		}
 	}
-	return repoKey, relative, repoName, nil
+	return "", "", "", fmt.Errorf("move target path must match catalog layout %s; pass --as", layout)
 }
 
 func loadRepoMetadataForMove(paths state.Paths, repoKey string) (domain.RepoMetadataFile, bool, error) {
@@ -349,3 +349,22 @@ func setupRepoMoveFixture(t *testing.T, now time.Time) (*App, state.Paths, strin
 
 	return app, paths, oldPath, filepath.Join(referencesRoot, "api"), oldRepoKey
 }
+
+func TestResolveRepoMoveTargetDerivesPathsFromTargetLayout(t *testing.T) {
+	t.Parallel()
+
+	repo := domain.MachineRepoRecord{RepoKey: "software/api", OriginURL: "git@github.com:you/api.git"}
+
+	repoKey, relative, _, err := resolveRepoMoveTarget(repo, domain.Catalog{Name: "src", Layout: "{host}/{owner}/{repo}"}, "")
+	if err != nil || repoKey != "src/github.com/you/api" || relative != "github.com/you/api" {
+		t.Fatalf("resolveRepoMoveTarget(ghq) = %q, %q, %v", repoKey, relative, err)
+	}
+	repoKey, _, _, err = resolveRepoMoveTarget(repo, domain.Catalog{Name: "mixed", Layout: "depth:1-3"}, "")
+	if err != nil || repoKey != "mixed/api" {
+		t.Fatalf("resolveRepoMoveTarget(range) = %q, %v, want mixed/api", repoKey, err)
+	}
+	_, _, _, err = resolveRepoMoveTarget(domain.MachineRepoRecord{RepoKey: "software/api"}, domain.Catalog{Name: "src", Layout: "{host}/{owner}/{repo}"}, "")
+	if err == nil || !strings.Contains(err.Error(), "pass --as") {
+		t.Fatalf("resolveRepoMoveTarget(no origin) error = %v, want pass --as hint", err)
+	}
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"time"
+
+	"bb-project/internal/domain"
+	"bb-project/internal/gitx"
+	"bb-project/internal/state"
+)
+
+// scanCacheMaxAge bounds how long a cached observation is reused. Fingerprints
+// see the files git rewrites and the worktree's top-level entries, so an
+// in-place edit of a file deeper in the tree is picked up when its entry
+// expires, or with scan --full.
+const scanCacheMaxAge = 10 * time.Minute
+
+// scanCacheContext fingerprints everything besides the repo itself that an
+// observation depends on: the sync and GitHub config, whether pushing was
+// allowed, and the shared repo metadata directory (metadata writes replace
+// files, which moves the directory's mtime).
+func (a *App) scanCacheContext(cfg domain.ConfigFile, allowPush bool) string {
+	reposStamp := "-"
+	if info, err := os.Stat(a.Paths.RepoDir()); err == nil {
+		reposStamp = fmt.Sprintf("%d", info.ModTime().UnixNano())
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%+v|%+v|%t|%s", cfg.Sync, cfg.GitHub, allowPush, reposStamp)))
+	return hex.EncodeToString(sum[:])
+}
+
+// cachedScanRecord returns the previous record for repo when its fingerprint
+// still matches the cache entry and the entry has not expired.
+func cachedScanRecord(
+	cache domain.ScanCacheFile,
+	prev map[string]domain.MachineRepoRecord,
+	repo discoveredRepo,
+	fingerprint string,
+	now time.Time,
+) (domain.MachineRepoRecord, bool) {
+	if fingerprint == "" {
+		return domain.MachineRepoRecord{}, false
+	}
+	entry, ok := cache.Repos[repo.Path]
+	if !ok || entry.Fingerprint != fingerprint {
+		return domain.MachineRepoRecord{}, false
+	}
+	if now.Sub(entry.ObservedAt) > scanCacheMaxAge {
+		return domain.MachineRepoRecord{}, false
+	}
+	rec, ok := prev[repoRecordIdentityKey(domain.MachineRepoRecord{RepoKey: repo.RepoKey, Path: repo.Path})]
+	if !ok || rec.Catalog != repo.Catalog.Name || rec.Name != repo.Name {
+		return domain.MachineRepoRecord{}, false
+	}
+	return rec, true
+}
+
+// scanCacheReportsChanges reports whether any repo of the selected catalogs
+// moved on disk since the last scan recorded it. Without a cache there is
+// nothing to compare, and only the freshness window applies.
+func (a *App) scanCacheReportsChanges(cfg domain.ConfigFile, machine domain.MachineFile, selected []domain.Catalog) bool {
+	cache := state.LoadScanCache(a.Paths)
+	if cache.Context == "" {
+		return false
+	}
+	if cache.Context != a.scanCacheContext(cfg, false) {
+		return true
+	}
+	selectedNames := make(map[string]struct{}, len(selected))
+	for _, catalog := range selected {
+		selectedNames[catalog.Name] = struct{}{}
+	}
+	for _, rec := range machine.Repos {
+		if _, ok := selectedNames[rec.Catalog]; !ok {
+			continue
+		}
+		// Records sync synthesized for missing clones have no entry and no
+		// fingerprint until the repo appears on disk.
+		if cache.Repos[rec.Path].Fingerprint != gitx.Fingerprint(rec.Path) {
+			return true
+		}
+	}
+	return false
+}
@@ -4,6 +4,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"sync"
 	"testing"
@@ -40,10 +41,11 @@ func TestShouldRefreshScanSnapshot(t *testing.T) {
 	selected := []domain.Catalog{{Name: "software"}}
 
 	tests := []struct {
-		name    string
-		machine domain.MachineFile
-		window  time.Duration
-		want    bool
+		name         string
+		machine      domain.MachineFile
+		window       time.Duration
+		reposChanged bool
+		want         bool
 	}{
 		{
 			name:    "never scanned",
@@ -78,6 +80,16 @@ func TestShouldRefreshScanSnapshot(t *testing.T) {
 			window: time.Minute,
 			want:   true,
 		},
+		{
+			name: "fresh but repos changed on disk",
+			machine: domain.MachineFile{
+				LastScanAt:       now.Add(-10 * time.Second),
+				LastScanCatalogs: []string{"software"},
+			},
+			window:       time.Minute,
+			reposChanged: true,
+			want:         true,
+		},
 		{
 			name: "window disabled",
 			machine: domain.MachineFile{
@@ -91,7 +103,7 @@ func TestShouldRefreshScanSnapshot(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := shouldRefreshScanSnapshot(tt.machine, selected, now, tt.window)
+			got := shouldRefreshScanSnapshot(tt.machine, selected, now, tt.window, tt.reposChanged)
 			if got != tt.want {
 				t.Fatalf("shouldRefreshScanSnapshot() = %t, want %t", got, tt.want)
 			}
@@ -206,3 +218,256 @@ func TestScanAndPublishObservesReposInParallel(t *testing.T) {
 		t.Fatalf("max in-flight observations = %d, want at least 2", maxInFlight)
 	}
 }
+
+func TestScanAndPublishReusesCachedObservationsUntilRepoMoves(t *testing.T) {
+	home := t.TempDir()
+	catalogRoot := filepath.Join(home, "catalog")
+	for _, rel := range []string{"api", "web"} {
+		gitDir := filepath.Join(catalogRoot, rel, ".git")
+		if err := os.MkdirAll(gitDir, 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", gitDir, err)
+		}
+		if err := os.WriteFile(filepath.Join(gitDir, "HEAD"), []byte("ref: refs/heads/main\n"), 0o644); err != nil {
+			t.Fatalf("write HEAD: %v", err)
+		}
+	}
+
+	now := time.Date(2026, 2, 14, 10, 0, 0, 0, time.UTC)
+	paths := state.NewPaths(home)
+	a := New(paths, io.Discard, io.Discard)
+	a.SetVerbose(false)
+	a.Now = func() time.Time { return now }
+
+	var mu sync.Mutex
+	observed := map[string]int{}
+	a.observeRepoHook = func(_ domain.ConfigFile, repo discoveredRepo, _ bool) (domain.MachineRepoRecord, error) {
+		mu.Lock()
+		observed[repo.Name]++
+		mu.Unlock()
+		return domain.MachineRepoRecord{
+			RepoKey:   repo.RepoKey,
+			Name:      repo.Name,
+			Catalog:   repo.Catalog.Name,
+			Path:      repo.Path,
+			Syncable:  true,
+			StateHash: "ok",
+		}, nil
+	}
+
+	cfg := state.DefaultConfig()
+	machine := state.BootstrapMachine("machine-a", "host-a", now)
+	machine.Catalogs = []domain.Catalog{{Name: "software", Root: catalogRoot}}
+	machine.DefaultCatalog = "software"
+	scan := func(opts ScanOptions) {
+		t.Helper()
+		if _, err := a.scanAndPublish(cfg, &machine, opts); err != nil {
+			t.Fatalf("scanAndPublish failed: %v", err)
+		}
+	}
+
+	scan(ScanOptions{})
+	if a.scanCacheReportsChanges(cfg, machine, machine.Catalogs) {
+		t.Fatal("scan cache reports changes right after a scan")
+	}
+	scan(ScanOptions{})
+	if observed["api"] != 1 || observed["web"] != 1 {
+		t.Fatalf("observations after unchanged rescan = %v, want one each", observed)
+	}
+	if len(machine.Repos) != 2 {
+		t.Fatalf("machine repos = %d, want cached records kept", len(machine.Repos))
+	}
+
+	head := filepath.Join(catalogRoot, "api", ".git", "HEAD")
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(head, later, later); err != nil {
+		t.Fatalf("touch HEAD: %v", err)
+	}
+	if !a.scanCacheReportsChanges(cfg, machine, machine.Catalogs) {
+		t.Fatal("scan cache missed a moved HEAD")
+	}
+	scan(ScanOptions{})
+	if observed["api"] != 2 || observed["web"] != 1 {
+		t.Fatalf("observations after HEAD moved = %v, want api re-observed only", observed)
+	}
+
+	scan(ScanOptions{Full: true})
+	if observed["api"] != 3 || observed["web"] != 2 {
+		t.Fatalf("observations after full scan = %v, want every repo re-observed", observed)
+	}
+
+	now = now.Add(scanCacheMaxAge + time.Second)
+	scan(ScanOptions{})
+	if observed["api"] != 4 || observed["web"] != 3 {
+		t.Fatalf("observations after cache expiry = %v, want every repo re-observed", observed)
+	}
+}
+
+func TestScanAndPublishSeesInPlaceEditsOfTrackedFiles(t *testing.T) {
+	t.Parallel()
+
+	home := t.TempDir()
+	now := time.Date(2026, 2, 14, 10, 0, 0, 0, time.UTC)
+	a := New(state.NewPaths(home), io.Discard, io.Discard)
+	a.SetVerbose(false)
+	a.Now = func() time.Time { return now }
+
+	remotePath := setupCloneTestRemote(t, filepath.Join(home, "remotes"), "you", "api")
+	catalogRoot := filepath.Join(home, "catalog")
+	repoPath := filepath.Join(catalogRoot, "api")
+	if _, err := a.Git.RunGit(home, "clone", remotePath, repoPath); err != nil {
+		t.Fatalf("clone: %v", err)
+	}
+
+	cfg := state.DefaultConfig()
+	machine := state.BootstrapMachine("machine-a", "host-a", now)
+	machine.Catalogs = []domain.Catalog{{Name: "software", Root: catalogRoot}}
+	machine.DefaultCatalog = "software"
+	if _, err := a.scanAndPublish(cfg, &machine, ScanOptions{}); err != nil {
+		t.Fatalf("scanAndPublish failed: %v", err)
+	}
+	if len(machine.Repos) != 1 || machine.Repos[0].HasDirtyTracked {
+		t.Fatalf("machine repos = %+v, want one clean record", machine.Repos)
+	}
+
+	// Rewriting a tracked file leaves the index alone.
+	if err := os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("edited\n"), 0o644); err != nil {
+		t.Fatalf("edit README: %v", err)
+	}
+	if !a.scanCacheReportsChanges(cfg, machine, machine.Catalogs) {
+		t.Fatal("scan cache missed an in-place edit")
+	}
+	if _, err := a.scanAndPublish(cfg, &machine, ScanOptions{}); err != nil {
+		t.Fatalf("scanAndPublish failed: %v", err)
+	}
+	if len(machine.Repos) != 1 || !machine.Repos[0].HasDirtyTracked {
+		t.Fatalf("machine repos = %+v, want the edit reported as dirty", machine.Repos)
+	}
+}
+
+func TestScanAndPublishRecordsWorktreesUnderTheirRepository(t *testing.T) {
+	t.Parallel()
+
+	home := t.TempDir()
+	now := time.Date(2026, 2, 14, 10, 0, 0, 0, time.UTC)
+	paths := state.NewPaths(home)
+	a := New(paths, io.Discard, io.Discard)
+	a.SetVerbose(false)
+	a.Now = func() time.Time { return now }
+
+	remotePath := setupCloneTestRemote(t, filepath.Join(home, "remotes"), "you", "api")
+	catalogRoot := filepath.Join(home, "catalog")
+	repoPath := filepath.Join(catalogRoot, "api")
+	worktreePath := filepath.Join(catalogRoot, "api-feature")
+	if _, err := a.Git.RunGit(home, "clone", remotePath, repoPath); err != nil {
+		t.Fatalf("clone: %v", err)
+	}
+	if _, err := a.Git.RunGit(repoPath, "worktree", "add", "-b", "feature", worktreePath); err != nil {
+		t.Fatalf("worktree add: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(worktreePath, "wip.txt"), []byte("wip\n"), 0o644); err != nil {
+		t.Fatalf("write wip: %v", err)
+	}
+	// Worktrees of a bare repository have no main worktree to hang off, so
+	// the first one stands in for the repository and lists the others.
+	barePath := filepath.Join(home, "bare", "tools.git")
+	toolsPath := filepath.Join(catalogRoot, "tools")
+	toolsNextPath := filepath.Join(catalogRoot, "tools-next")
+	if _, err := a.Git.RunGit(home, "clone", "--bare", remotePath, barePath); err != nil {
+		t.Fatalf("clone --bare: %v", err)
+	}
+	if _, err := a.Git.RunGit(barePath, "worktree", "add", toolsPath, "main"); err != nil {
+		t.Fatalf("worktree add tools: %v", err)
+	}
+	if _, err := a.Git.RunGit(barePath, "worktree", "add", "-b", "next", toolsNextPath); err != nil {
+		t.Fatalf("worktree add tools-next: %v", err)
+	}
+
+	cfg := state.DefaultConfig()
+	machine := state.BootstrapMachine("machine-a", "host-a", now)
+	machine.Catalogs = []domain.Catalog{{Name: "software", Root: catalogRoot}}
+	machine.DefaultCatalog = "software"
+	discovered, err := discoverRepos(machine.Catalogs)
+	if err != nil {
+		t.Fatalf("discoverRepos failed: %v", err)
+	}
+	gotPaths := []string{}
+	for _, repo := range discovered {
+		gotPaths = append(gotPaths, repo.Path)
+	}
+	if wantPaths := []string{repoPath, toolsPath}; !reflect.DeepEqual(gotPaths, wantPaths) {
+		t.Fatalf("discoverRepos() paths = %v, want %v", gotPaths, wantPaths)
+	}
+
+	if _, err := a.scanAndPublish(cfg, &machine, ScanOptions{}); err != nil {
+		t.Fatalf("scanAndPublish failed: %v", err)
+	}
+	if len(machine.Repos) != 2 {
+		t.Fatalf("machine repos = %+v, want two records", machine.Repos)
+	}
+	records := map[string]domain.MachineRepoRecord{}
+	for _, rec := range machine.Repos {
+		records[rec.RepoKey] = rec
+	}
+	tools, ok := records["software/tools"]
+	if !ok {
+		t.Fatalf("machine repos = %+v, want software/tools for the bare repository", machine.Repos)
+	}
+	wantTools := []domain.WorktreeRecord{{
+		Path:                toolsNextPath,
+		Branch:              "next",
+		HeadSHA:             tools.HeadSHA,
+		OperationInProgress: domain.OperationNone,
+	}}
+	if !reflect.DeepEqual(tools.Worktrees, wantTools) {
+		t.Fatalf("tools worktrees = %+v, want %+v", tools.Worktrees, wantTools)
+	}
+	rec := records["software/api"]
+	if rec.RepoKey != "software/api" || rec.HasUntracked {
+		t.Fatalf("main record = %+v, want clean software/api", rec)
+	}
+	want := []domain.WorktreeRecord{{
+		Path:                worktreePath,
+		Branch:              "feature",
+		HeadSHA:             rec.HeadSHA,
+		HasUntracked:        true,
+		OperationInProgress: domain.OperationNone,
+	}}
+	if !reflect.DeepEqual(rec.Worktrees, want) {
+		t.Fatalf("worktrees = %+v, want %+v", rec.Worktrees, want)
+	}
+}
+
+func TestDiscoverReposFollowsCatalogLayout(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	for _, rel := range []string{"github.com/you/api", "gitlab.com/team/web", "you/too-shallow", "github.com/you/api2/nested/deep"} {
+		if err := os.MkdirAll(filepath.Join(root, filepath.FromSlash(rel), ".git"), 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", rel, err)
+		}
+	}
+
+	discovered, err := discoverRepos([]domain.Catalog{{Name: "src", Root: root, Layout: "{host}/{owner}/{repo}"}})
+	if err != nil {
+		t.Fatalf("discoverRepos failed: %v", err)
+	}
+	got := []string{}
+	for _, repo := range discovered {
+		got = append(got, repo.RepoKey)
+	}
+	if want := []string{"src/github.com/you/api", "src/gitlab.com/team/web"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("discovered = %v, want %v", got, want)
+	}
+
+	discovered, err = discoverRepos([]domain.Catalog{{Name: "mixed", Root: root, Layout: "depth:1-3"}})
+	if err != nil {
+		t.Fatalf("discoverRepos failed: %v", err)
+	}
+	got = got[:0]
+	for _, repo := range discovered {
+		got = append(got, repo.RepoKey)
+	}
+	if want := []string{"mixed/github.com/you/api", "mixed/gitlab.com/team/web", "mixed/you/too-shallow"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("discovered = %v, want %v", got, want)
+	}
+}
@@ -11,16 +11,35 @@ import (
 	"bb-project/internal/state"
 )
 
-const schedulerLaunchdLabel = "com.bb-project.sync"
+const (
+	schedulerLaunchdLabel = "com.bb-project.sync"
+
+	schedulerBackendLaunchd = "launchd"
+	schedulerBackendSystemd = "systemd"
+	schedulerBackendCron    = "cron"
+
+	// schedulerNotifyBackendConfig is reported when the scheduled job passes no
+	// --notify-backend and therefore routes through notify.backends.
+	schedulerNotifyBackendConfig = "config"
+)
 
 var (
 	reStartInterval = regexp.MustCompile(`(?s)<key>\s*StartInterval\s*</key>\s*<integer>\s*([0-9]+)\s*</integer>`)
 	reNotifyBackend = regexp.MustCompile(`(?s)<string>\s*--notify-backend\s*</string>\s*<string>\s*([^<]+)\s*</string>`)
 )
 
+type schedulerJob struct {
+	Executable      string
+	IntervalMinutes int
+	NotifyBackend   string
+	StdoutPath      string
+	StderrPath      string
+}
+
 func (a *App) RunSchedulerInstall(opts SchedulerInstallOptions) (int, error) {
-	if !a.supportsScheduler() {
-		return 2, fmt.Errorf("scheduler is only supported on macOS")
+	schedulerBackend, err := a.resolveSchedulerBackend(opts.Backend)
+	if err != nil {
+		return 2, err
 	}
 
 	cfg, err := state.LoadConfig(a.Paths)
@@ -28,9 +47,14 @@ func (a *App) RunSchedulerInstall(opts SchedulerInstallOptions) (int, error) {
 		return 2, err
 	}
 
-	backend, err := a.resolveNotifyBackendWithDefault(opts.NotifyBackend, notifyBackendOSAScript)
-	if err != nil {
-		return 2, err
+	// Without an explicit backend, a configured notify.backends list is left to
+	// the scheduled run so it picks up later config edits.
+	backend := ""
+	if a.notifyBackendOverridden(opts.NotifyBackend) || len(cfg.Notify.Backends) == 0 {
+		backend, err = a.resolveNotifyBackendWithDefault(opts.NotifyBackend, defaultSchedulerNotifyBackend(schedulerBackend))
+		if err != nil {
+			return 2, err
+		}
 	}
 
 	intervalMinutes := cfg.Scheduler.IntervalMinutes
@@ -52,23 +76,67 @@ func (a *App) RunSchedulerInstall(opts SchedulerInstallOptions) (int, error) {
 			return 2, fmt.Errorf("resolve executable path: %w", err)
 		}
 	}
+	if err := os.MkdirAll(a.Paths.LocalStateRoot(), 0o755); err != nil {
+		return 2, fmt.Errorf("create local state directory: %w", err)
+	}
 
+	job := schedulerJob{
+		Executable:      executable,
+		IntervalMinutes: intervalMinutes,
+		NotifyBackend:   backend,
+		StdoutPath:      filepath.Join(a.Paths.LocalStateRoot(), "scheduler-sync.log"),
+		StderrPath:      filepath.Join(a.Paths.LocalStateRoot(), "scheduler-sync.err.log"),
+	}
+
+	switch schedulerBackend {
+	case schedulerBackendSystemd:
+		return a.installSystemdScheduler(job)
+	case schedulerBackendCron:
+		return a.installCronScheduler(job)
+	default:
+		return a.installLaunchdScheduler(job)
+	}
+}
+
+func (a *App) RunSchedulerStatus(backend string) (int, error) {
+	schedulerBackend, err := a.resolveInstalledSchedulerBackend(backend)
+	if err != nil {
+		return 2, err
+	}
+	switch schedulerBackend {
+	case schedulerBackendSystemd:
+		return a.systemdSchedulerStatus()
+	case schedulerBackendCron:
+		return a.cronSchedulerStatus()
+	default:
+		return a.launchdSchedulerStatus()
+	}
+}
+
+func (a *App) RunSchedulerRemove(backend string) (int, error) {
+	schedulerBackend, err := a.resolveInstalledSchedulerBackend(backend)
+	if err != nil {
+		return 2, err
+	}
+	switch schedulerBackend {
+	case schedulerBackendSystemd:
+		return a.removeSystemdScheduler()
+	case schedulerBackendCron:
+		return a.removeCronScheduler()
+	default:
+		return a.removeLaunchdScheduler()
+	}
+}
+
+func (a *App) installLaunchdScheduler(job schedulerJob) (int, error) {
 	plistPath := schedulerPlistPath(a.Paths.Home)
-	stdoutPath := filepath.Join(a.Paths.LocalStateRoot(), "scheduler-sync.log")
-	stderrPath := filepath.Join(a.Paths.LocalStateRoot(), "scheduler-sync.err.log")
-	plist := sampleSchedulerPlist(executable, intervalMinutes*60, backend, stdoutPath, stderrPath)
+	plist := sampleSchedulerPlist(job.Executable, job.IntervalMinutes*60, job.NotifyBackend, job.StdoutPath, job.StderrPath)
 
 	if err := os.MkdirAll(filepath.Dir(plistPath), 0o755); err != nil {
 		return 2, fmt.Errorf("create launch agents directory: %w", err)
 	}
-	if err := os.MkdirAll(a.Paths.LocalStateRoot(), 0o755); err != nil {
-		return 2, fmt.Errorf("create local state directory: %w", err)
-	}
 
-	runCommand := a.RunCommand
-	if runCommand == nil {
-		runCommand = defaultRunCommand
-	}
+	runCommand := a.schedulerRunCommand()
 	_, _ = runCommand("launchctl", "unload", plistPath)
 
 	if err := os.WriteFile(plistPath, []byte(plist), 0o644); err != nil {
@@ -79,20 +147,16 @@ func (a *App) RunSchedulerInstall(opts SchedulerInstallOptions) (int, error) {
 		return 2, fmt.Errorf("load launch agent: %w", err)
 	}
 
-	fmt.Fprintf(a.Stdout, "installed scheduler label=%s interval_minutes=%d notify_backend=%s\n", schedulerLaunchdLabel, intervalMinutes, backend)
+	fmt.Fprintf(a.Stdout, "installed scheduler backend=%s label=%s interval_minutes=%d notify_backend=%s\n", schedulerBackendLaunchd, schedulerLaunchdLabel, job.IntervalMinutes, job.notifyBackendLabel())
 	return 0, nil
 }
 
-func (a *App) RunSchedulerStatus() (int, error) {
-	if !a.supportsScheduler() {
-		return 2, fmt.Errorf("scheduler is only supported on macOS")
-	}
-
+func (a *App) launchdSchedulerStatus() (int, error) {
 	plistPath := schedulerPlistPath(a.Paths.Home)
 	raw, err := os.ReadFile(plistPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			fmt.Fprintf(a.Stdout, "installed=false label=%s path=%s\n", schedulerLaunchdLabel, plistPath)
+			fmt.Fprintf(a.Stdout, "installed=false backend=%s label=%s path=%s\n", schedulerBackendLaunchd, schedulerLaunchdLabel, plistPath)
 			return 0, nil
 		}
 		return 2, err
@@ -101,51 +165,125 @@ func (a *App) RunSchedulerStatus() (int, error) {
 	intervalMinutes := extractSchedulerIntervalMinutes(string(raw))
 	backend := extractSchedulerNotifyBackend(string(raw))
 	if backend == "" {
-		backend = notifyBackendOSAScript
+		backend = schedulerNotifyBackendConfig
 	}
 
 	loaded := false
-	runCommand := a.RunCommand
-	if runCommand == nil {
-		runCommand = defaultRunCommand
-	}
-	if out, err := runCommand("launchctl", "list", schedulerLaunchdLabel); err == nil && strings.Contains(out, schedulerLaunchdLabel) {
+	if out, err := a.schedulerRunCommand()("launchctl", "list", schedulerLaunchdLabel); err == nil && strings.Contains(out, schedulerLaunchdLabel) {
 		loaded = true
 	}
 
-	fmt.Fprintf(a.Stdout, "installed=true loaded=%t label=%s interval_minutes=%d notify_backend=%s path=%s\n", loaded, schedulerLaunchdLabel, intervalMinutes, backend, plistPath)
+	fmt.Fprintf(a.Stdout, "installed=true loaded=%t backend=%s label=%s interval_minutes=%d notify_backend=%s path=%s\n", loaded, schedulerBackendLaunchd, schedulerLaunchdLabel, intervalMinutes, backend, plistPath)
 	return 0, nil
 }
 
-func (a *App) RunSchedulerRemove() (int, error) {
-	if !a.supportsScheduler() {
-		return 2, fmt.Errorf("scheduler is only supported on macOS")
-	}
-
+func (a *App) removeLaunchdScheduler() (int, error) {
 	plistPath := schedulerPlistPath(a.Paths.Home)
 	if _, err := os.Stat(plistPath); os.IsNotExist(err) {
-		fmt.Fprintf(a.Stdout, "removed=false label=%s path=%s\n", schedulerLaunchdLabel, plistPath)
+		fmt.Fprintf(a.Stdout, "removed=false backend=%s label=%s path=%s\n", schedulerBackendLaunchd, schedulerLaunchdLabel, plistPath)
 		return 0, nil
 	}
 
-	runCommand := a.RunCommand
-	if runCommand == nil {
-		runCommand = defaultRunCommand
-	}
-	_, _ = runCommand("launchctl", "unload", plistPath)
+	_, _ = a.schedulerRunCommand()("launchctl", "unload", plistPath)
 	if err := os.Remove(plistPath); err != nil && !os.IsNotExist(err) {
 		return 2, fmt.Errorf("remove launch agent: %w", err)
 	}
-	fmt.Fprintf(a.Stdout, "removed=true label=%s path=%s\n", schedulerLaunchdLabel, plistPath)
+	fmt.Fprintf(a.Stdout, "removed=true backend=%s label=%s path=%s\n", schedulerBackendLaunchd, schedulerLaunchdLabel, plistPath)
 	return 0, nil
 }
 
-func (a *App) supportsScheduler() bool {
+func (a *App) resolveSchedulerBackend(explicit string) (string, error) {
 	goos := "darwin"
 	if a.GOOS != nil {
 		goos = strings.TrimSpace(a.GOOS())
 	}
-	return goos == "darwin"
+	if goos == "windows" {
+		return "", fmt.Errorf("scheduler is not supported on windows")
+	}
+
+	explicit = strings.ToLower(strings.TrimSpace(explicit))
+	switch explicit {
+	case "":
+		switch goos {
+		case "darwin":
+			return schedulerBackendLaunchd, nil
+		case "linux":
+			return schedulerBackendSystemd, nil
+		default:
+			return schedulerBackendCron, nil
+		}
+	case schedulerBackendLaunchd:
+		if goos != "darwin" {
+			return "", fmt.Errorf("scheduler backend %q is only supported on macOS", explicit)
+		}
+		return explicit, nil
+	case schedulerBackendSystemd:
+		if goos != "linux" {
+			return "", fmt.Errorf("scheduler backend %q is only supported on Linux", explicit)
+		}
+		return explicit, nil
+	case schedulerBackendCron:
+		return explicit, nil
+	default:
+		return "", fmt.Errorf("invalid scheduler backend %q (supported: %s, %s, %s)", explicit, schedulerBackendLaunchd, schedulerBackendSystemd, schedulerBackendCron)
+	}
+}
+
+// resolveInstalledSchedulerBackend picks the backend for status/remove. Without
+// an explicit backend it prefers the platform default, but falls back to the
+// cron block when only that one is installed.
+func (a *App) resolveInstalledSchedulerBackend(explicit string) (string, error) {
+	backend, err := a.resolveSchedulerBackend(explicit)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(explicit) != "" || backend == schedulerBackendCron {
+		return backend, nil
+	}
+	if a.platformSchedulerInstalled(backend) {
+		return backend, nil
+	}
+	if installed, err := a.cronSchedulerInstalled(); err == nil && installed {
+		return schedulerBackendCron, nil
+	}
+	return backend, nil
+}
+
+func (a *App) platformSchedulerInstalled(backend string) bool {
+	var path string
+	switch backend {
+	case schedulerBackendSystemd:
+		path = schedulerSystemdTimerPath(a.Paths.Home)
+	default:
+		path = schedulerPlistPath(a.Paths.Home)
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func (j schedulerJob) notifyBackendLabel() string {
+	if j.NotifyBackend == "" {
+		return schedulerNotifyBackendConfig
+	}
+	return j.NotifyBackend
+}
+
+func defaultSchedulerNotifyBackend(schedulerBackend string) string {
+	switch schedulerBackend {
+	case schedulerBackendLaunchd:
+		return notifyBackendOSAScript
+	case schedulerBackendSystemd:
+		return notifyBackendNotifySend
+	default:
+		return notifyBackendStdout
+	}
+}
+
+func (a *App) schedulerRunCommand() func(name string, args ...string) (string, error) {
+	if a.RunCommand == nil {
+		return defaultRunCommand
+	}
+	return a.RunCommand
 }
 
 func schedulerPlistPath(home string) string {
@@ -153,6 +291,10 @@ func schedulerPlistPath(home string) string {
 }
 
 func sampleSchedulerPlist(executable string, intervalSeconds int, backend string, stdoutPath string, stderrPath string) string {
+	notifyBackendArgs := ""
+	if backend != "" {
+		notifyBackendArgs = fmt.Sprintf("\n    <string>--notify-backend</string>\n    <string>%s</string>", backend)
+	}
 	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
 <!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
 <plist version="1.0">
@@ -164,9 +306,7 @@ func sampleSchedulerPlist(executable string, intervalSeconds int, backend string
     <string>%s</string>
     <string>sync</string>
     <string>--notify</string>
-    <string>--quiet</string>
-    <string>--notify-backend</string>
-    <string>%s</string>
+    <string>--quiet</string>%s
     </array>
     <key>RunAtLoad</key>
     <true/>
@@ -178,7 +318,7 @@ func sampleSchedulerPlist(executable string, intervalSeconds int, backend string
     <string>%s</string>
 </dict>
 </plist>
-`, schedulerLaunchdLabel, executable, backend, intervalSeconds, stdoutPath, stderrPath)
+`, schedulerLaunchdLabel, executable, notifyBackendArgs, intervalSeconds, stdoutPath, stderrPath)
 }
 
 func extractSchedulerIntervalMinutes(plist string) int {
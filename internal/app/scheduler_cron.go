@@ -0,0 +1,303 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	schedulerCronBeginMarker = "# BEGIN bb-project"
+	schedulerCronEndMarker   = "# END bb-project"
+)
+
+var (
+	reCronNotifyBackend = regexp.MustCompile(`--notify-backend\s+'?([^\s']+)'?`)
+	reCronEveryN        = regexp.MustCompile(`^\*/([0-9]+)$`)
+)
+
+func (a *App) installCronScheduler(job schedulerJob) (int, error) {
+	schedule, effectiveMinutes := cronScheduleForInterval(job.IntervalMinutes)
+	if effectiveMinutes != job.IntervalMinutes {
+		a.logf("scheduler: cron cannot express %d minute interval exactly; using %d minutes", job.IntervalMinutes, effectiveMinutes)
+	}
+
+	current, err := a.readCrontab()
+	if err != nil {
+		return 2, err
+	}
+	updated := replaceCronBlock(current, sampleSchedulerCronBlock(schedule, job))
+	if err := a.writeCrontab(updated); err != nil {
+		return 2, err
+	}
+
+	fmt.Fprintf(a.Stdout, "installed scheduler backend=%s label=%q interval_minutes=%d notify_backend=%s\n", schedulerBackendCron, schedulerCronBeginMarker, effectiveMinutes, job.notifyBackendLabel())
+	return 0, nil
+}
+
+func (a *App) cronSchedulerStatus() (int, error) {
+	current, err := a.readCrontab()
+	if err != nil {
+		return 2, err
+	}
+	block, ok := extractCronBlock(current)
+	if !ok {
+		fmt.Fprintf(a.Stdout, "installed=false backend=%s label=%q\n", schedulerBackendCron, schedulerCronBeginMarker)
+		return 0, nil
+	}
+
+	intervalMinutes := extractCronIntervalMinutes(block)
+	backend := extractCronNotifyBackend(block)
+	if backend == "" {
+		backend = schedulerNotifyBackendConfig
+	}
+
+	fmt.Fprintf(a.Stdout, "installed=true loaded=true backend=%s label=%q interval_minutes=%d notify_backend=%s\n", schedulerBackendCron, schedulerCronBeginMarker, intervalMinutes, backend)
+	return 0, nil
+}
+
+func (a *App) removeCronScheduler() (int, error) {
+	current, err := a.readCrontab()
+	if err != nil {
+		return 2, err
+	}
+	if _, ok := extractCronBlock(current); !ok {
+		fmt.Fprintf(a.Stdout, "removed=false backend=%s label=%q\n", schedulerBackendCron, schedulerCronBeginMarker)
+		return 0, nil
+	}
+	if err := a.writeCrontab(replaceCronBlock(current, "")); err != nil {
+		return 2, err
+	}
+	fmt.Fprintf(a.Stdout, "removed=true backend=%s label=%q\n", schedulerBackendCron, schedulerCronBeginMarker)
+	return 0, nil
+}
+
+func (a *App) cronSchedulerInstalled() (bool, error) {
+	current, err := a.readCrontab()
+	if err != nil {
+		return false, err
+	}
+	_, ok := extractCronBlock(current)
+	return ok, nil
+}
+
+func (a *App) readCrontab() (string, error) {
+	out, err := a.schedulerRunCommand()("crontab", "-l")
+	if err != nil {
+		// crontab exits non-zero when the user has no crontab yet.
+		if strings.Contains(strings.ToLower(out), "no crontab") {
+			return "", nil
+		}
+		return "", fmt.Errorf("read crontab: %w: %s", err, strings.TrimSpace(out))
+	}
+	return out, nil
+}
+
+func (a *App) writeCrontab(content string) error {
+	if err := os.MkdirAll(a.Paths.LocalStateRoot(), 0o755); err != nil {
+		return fmt.Errorf("create local state directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(a.Paths.LocalStateRoot(), "crontab-*.txt")
+	if err != nil {
+		return fmt.Errorf("write crontab: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() {
+		_ = os.Remove(tmpPath)
+	}()
+	if _, err := tmp.WriteString(content); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("write crontab: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write crontab: %w", err)
+	}
+	if out, err := a.schedulerRunCommand()("crontab", filepath.Clean(tmpPath)); err != nil {
+		return fmt.Errorf("install crontab: %w: %s", err, strings.TrimSpace(out))
+	}
+	return nil
+}
+
+// cronIntervalMinutes are the intervals cron repeats at evenly: divisors of
+// an hour as */N minutes and divisors of a day as */H hours.
+var cronIntervalMinutes = []int{1, 2, 3, 4, 5, 6, 10, 12, 15, 20, 30, 60, 120, 180, 240, 360, 480, 720, 1440}
+
+// cronScheduleForInterval converts an interval into a cron schedule. Cron
+// steps restart at each hour or day, so */45 would fire 45 then 15 minutes
+// apart; intervals are rounded to the nearest even one (the shorter on a tie)
+// and the effective interval is returned alongside.
+func cronScheduleForInterval(intervalMinutes int) (string, int) {
+	if intervalMinutes < 1 {
+		intervalMinutes = 60
+	}
+	distance := func(minutes int) int {
+		return max(minutes-intervalMinutes, intervalMinutes-minutes)
+	}
+	effective := cronIntervalMinutes[0]
+	for _, candidate := range cronIntervalMinutes[1:] {
+		if distance(candidate) < distance(effective) {
+			effective = candidate
+		}
+	}
+	switch {
+	case effective == 1:
+		return "* * * * *", 1
+	case effective < 60:
+		return fmt.Sprintf("*/%d * * * *", effective), effective
+	case effective == 60:
+		return "0 * * * *", 60
+	case effective == 1440:
+		return "0 0 * * *", 1440
+	default:
+		return fmt.Sprintf("0 */%d * * *", effective/60), effective
+	}
+}
+
+func sampleSchedulerCronBlock(schedule string, job schedulerJob) string {
+	notifyBackendArgs := ""
+	if job.NotifyBackend != "" {
+		notifyBackendArgs = " --notify-backend " + cronQuoteArg(job.NotifyBackend)
+	}
+	command := fmt.Sprintf(
+		"%s sync --notify --quiet%s >>%s 2>>%s",
+		cronQuoteArg(job.Executable),
+		notifyBackendArgs,
+		cronQuoteArg(job.StdoutPath),
+		cronQuoteArg(job.StderrPath),
+	)
+	return strings.Join([]string{
+		schedulerCronBeginMarker,
+		"# Managed by bb scheduler; edits inside this block are overwritten.",
+		schedule + " " + command,
+		schedulerCronEndMarker,
+	}, "\n") + "\n"
+}
+
+// cronQuoteArg single-quotes a shell argument and escapes '%', which cron
+// otherwise treats as a newline.
+func cronQuoteArg(value string) string {
+	value = strings.ReplaceAll(value, "%", `\%`)
+	if value != "" && !strings.ContainsAny(value, " \t'\"\\$`;&|<>()*?[]#~") {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// replaceCronBlock swaps the managed block in a crontab for block (or removes
+// it when block is empty), leaving all other lines untouched.
+func replaceCronBlock(crontab string, block string) string {
+	lines := splitCrontabLines(crontab)
+	out := make([]string, 0, len(lines)+4)
+	inBlock := false
+	replaced := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if !inBlock && trimmed == schedulerCronBeginMarker {
+			inBlock = true
+			if block != "" && !replaced {
+				out = append(out, splitCrontabLines(block)...)
+				replaced = true
+			}
+			continue
+		}
+		if inBlock {
+			if trimmed == schedulerCronEndMarker {
+				inBlock = false
+			}
+			continue
+		}
+		out = append(out, line)
+	}
+	if block != "" && !replaced {
+		out = append(out, splitCrontabLines(block)...)
+	}
+	if len(out) == 0 {
+		return ""
+	}
+	return strings.Join(out, "\n") + "\n"
+}
+
+func extractCronBlock(crontab string) (string, bool) {
+	lines := splitCrontabLines(crontab)
+	inBlock := false
+	block := []string{}
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if !inBlock {
+			if trimmed == schedulerCronBeginMarker {
+				inBlock = true
+			}
+			continue
+		}
+		if trimmed == schedulerCronEndMarker {
+			return strings.Join(block, "\n"), true
+		}
+		block = append(block, line)
+	}
+	if inBlock {
+		return strings.Join(block, "\n"), true
+	}
+	return "", false
+}
+
+func splitCrontabLines(crontab string) []string {
+	crontab = strings.TrimRight(crontab, "\n")
+	if crontab == "" {
+		return nil
+	}
+	return strings.Split(crontab, "\n")
+}
+
+func cronScheduleLine(block string) string {
+	for _, line := range splitCrontabLines(block) {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		return trimmed
+	}
+	return ""
+}
+
+func extractCronIntervalMinutes(block string) int {
+	fields := strings.Fields(cronScheduleLine(block))
+	if len(fields) < 5 {
+		return 0
+	}
+	minute, hour := fields[0], fields[1]
+	if hour == "*" {
+		if minute == "*" {
+			return 1
+		}
+		if matches := reCronEveryN.FindStringSubmatch(minute); len(matches) == 2 {
+			n, _ := strconv.Atoi(matches[1])
+			return n
+		}
+		if _, err := strconv.Atoi(minute); err == nil {
+			return 60
+		}
+		return 0
+	}
+	if _, err := strconv.Atoi(minute); err != nil {
+		return 0
+	}
+	if matches := reCronEveryN.FindStringSubmatch(hour); len(matches) == 2 {
+		n, _ := strconv.Atoi(matches[1])
+		return n * 60
+	}
+	if _, err := strconv.Atoi(hour); err == nil {
+		return 24 * 60
+	}
+	return 0
+}
+
+func extractCronNotifyBackend(block string) string {
+	matches := reCronNotifyBackend.FindStringSubmatch(cronScheduleLine(block))
+	if len(matches) != 2 {
+		return ""
+	}
+	return strings.TrimSpace(matches[1])
+}
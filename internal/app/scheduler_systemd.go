@@ -0,0 +1,195 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const schedulerSystemdUnit = "bb-project-sync"
+
+var (
+	reSystemdOnUnitActiveSec = regexp.MustCompile(`(?m)^\s*OnUnitActiveSec\s*=\s*(\S+)\s*$`)
+	reSystemdNotifyBackend   = regexp.MustCompile(`(?m)^\s*ExecStart\s*=.*\s--notify-backend\s+"?([^\s"]+)"?`)
+	reSystemdDuration        = regexp.MustCompile(`^([0-9]+)\s*(s|sec|second|seconds|m|min|minute|minutes|h|hr|hour|hours)?$`)
+)
+
+func (a *App) installSystemdScheduler(job schedulerJob) (int, error) {
+	servicePath := schedulerSystemdServicePath(a.Paths.Home)
+	timerPath := schedulerSystemdTimerPath(a.Paths.Home)
+
+	if err := os.MkdirAll(filepath.Dir(servicePath), 0o755); err != nil {
+		return 2, fmt.Errorf("create systemd user unit directory: %w", err)
+	}
+
+	runCommand := a.schedulerRunCommand()
+	_, _ = runCommand("systemctl", "--user", "disable", "--now", schedulerSystemdUnit+".timer")
+
+	if err := os.WriteFile(servicePath, []byte(sampleSchedulerSystemdService(job)), 0o644); err != nil {
+		return 2, fmt.Errorf("write systemd service: %w", err)
+	}
+	if err := os.WriteFile(timerPath, []byte(sampleSchedulerSystemdTimer(job.IntervalMinutes*60)), 0o644); err != nil {
+		return 2, fmt.Errorf("write systemd timer: %w", err)
+	}
+
+	if _, err := runCommand("systemctl", "--user", "daemon-reload"); err != nil {
+		return 2, fmt.Errorf("reload systemd user units: %w", err)
+	}
+	if out, err := runCommand("systemctl", "--user", "enable", "--now", schedulerSystemdUnit+".timer"); err != nil {
+		return 2, fmt.Errorf("enable systemd timer: %w: %s", err, strings.TrimSpace(out))
+	}
+
+	fmt.Fprintf(a.Stdout, "installed scheduler backend=%s label=%s interval_minutes=%d notify_backend=%s\n", schedulerBackendSystemd, schedulerSystemdUnit+".timer", job.IntervalMinutes, job.notifyBackendLabel())
+	return 0, nil
+}
+
+func (a *App) systemdSchedulerStatus() (int, error) {
+	timerPath := schedulerSystemdTimerPath(a.Paths.Home)
+	timerRaw, err := os.ReadFile(timerPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			fmt.Fprintf(a.Stdout, "installed=false backend=%s label=%s path=%s\n", schedulerBackendSystemd, schedulerSystemdUnit+".timer", timerPath)
+			return 0, nil
+		}
+		return 2, err
+	}
+	serviceRaw, err := os.ReadFile(schedulerSystemdServicePath(a.Paths.Home))
+	if err != nil && !os.IsNotExist(err) {
+		return 2, err
+	}
+
+	intervalMinutes := extractSystemdTimerIntervalMinutes(string(timerRaw))
+	backend := extractSystemdNotifyBackend(string(serviceRaw))
+	if backend == "" {
+		backend = schedulerNotifyBackendConfig
+	}
+
+	loaded := false
+	if out, err := a.schedulerRunCommand()("systemctl", "--user", "is-active", schedulerSystemdUnit+".timer"); err == nil && strings.TrimSpace(out) == "active" {
+		loaded = true
+	}
+
+	fmt.Fprintf(a.Stdout, "installed=true loaded=%t backend=%s label=%s interval_minutes=%d notify_backend=%s path=%s\n", loaded, schedulerBackendSystemd, schedulerSystemdUnit+".timer", intervalMinutes, backend, timerPath)
+	return 0, nil
+}
+
+func (a *App) removeSystemdScheduler() (int, error) {
+	servicePath := schedulerSystemdServicePath(a.Paths.Home)
+	timerPath := schedulerSystemdTimerPath(a.Paths.Home)
+	_, timerErr := os.Stat(timerPath)
+	_, serviceErr := os.Stat(servicePath)
+	if os.IsNotExist(timerErr) && os.IsNotExist(serviceErr) {
+		fmt.Fprintf(a.Stdout, "removed=false backend=%s label=%s path=%s\n", schedulerBackendSystemd, schedulerSystemdUnit+".timer", timerPath)
+		return 0, nil
+	}
+
+	runCommand := a.schedulerRunCommand()
+	_, _ = runCommand("systemctl", "--user", "disable", "--now", schedulerSystemdUnit+".timer")
+	for _, path := range []string{timerPath, servicePath} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return 2, fmt.Errorf("remove systemd unit: %w", err)
+		}
+	}
+	_, _ = runCommand("systemctl", "--user", "daemon-reload")
+	fmt.Fprintf(a.Stdout, "removed=true backend=%s label=%s path=%s\n", schedulerBackendSystemd, schedulerSystemdUnit+".timer", timerPath)
+	return 0, nil
+}
+
+func schedulerSystemdUnitDir(home string) string {
+	return filepath.Join(home, ".config", "systemd", "user")
+}
+
+func schedulerSystemdServicePath(home string) string {
+	return filepath.Join(schedulerSystemdUnitDir(home), schedulerSystemdUnit+".service")
+}
+
+func schedulerSystemdTimerPath(home string) string {
+	return filepath.Join(schedulerSystemdUnitDir(home), schedulerSystemdUnit+".timer")
+}
+
+func sampleSchedulerSystemdService(job schedulerJob) string {
+	notifyBackendArgs := ""
+	if job.NotifyBackend != "" {
+		notifyBackendArgs = " --notify-backend " + systemdQuoteArg(job.NotifyBackend)
+	}
+	return fmt.Sprintf(`[Unit]
+Description=bb-project periodic sync
+
+[Service]
+Type=oneshot
+ExecStart=%s sync --notify --quiet%s
+StandardOutput=append:%s
+StandardError=append:%s
+`, systemdQuoteArg(job.Executable), notifyBackendArgs, systemdEscapePath(job.StdoutPath), systemdEscapePath(job.StderrPath))
+}
+
+func sampleSchedulerSystemdTimer(intervalSeconds int) string {
+	return fmt.Sprintf(`[Unit]
+Description=Run bb-project sync periodically
+
+[Timer]
+OnActiveSec=0
+OnUnitActiveSec=%ds
+Unit=%s.service
+
+[Install]
+WantedBy=timers.target
+`, intervalSeconds, schedulerSystemdUnit)
+}
+
+// systemdQuoteArg quotes an ExecStart argument when it contains characters
+// systemd would otherwise split on or expand.
+func systemdQuoteArg(value string) string {
+	value = strings.ReplaceAll(value, "%", "%%")
+	if !strings.ContainsAny(value, " \t\"'\\") {
+		return value
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + replacer.Replace(value) + `"`
+}
+
+func systemdEscapePath(path string) string {
+	return strings.ReplaceAll(path, "%", "%%")
+}
+
+func extractSystemdTimerIntervalMinutes(unit string) int {
+	matches := reSystemdOnUnitActiveSec.FindStringSubmatch(unit)
+	if len(matches) != 2 {
+		return 0
+	}
+	seconds := parseSystemdDurationSeconds(matches[1])
+	if seconds < 1 {
+		return 0
+	}
+	return seconds / 60
+}
+
+func parseSystemdDurationSeconds(raw string) int {
+	matches := reSystemdDuration.FindStringSubmatch(strings.ToLower(strings.TrimSpace(raw)))
+	if len(matches) != 3 {
+		return 0
+	}
+	value, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return 0
+	}
+	switch matches[2] {
+	case "m", "min", "minute", "minutes":
+		return value * 60
+	case "h", "hr", "hour", "hours":
+		return value * 3600
+	default:
+		return value
+	}
+}
+
+func extractSystemdNotifyBackend(unit string) string {
+	matches := reSystemdNotifyBackend.FindStringSubmatch(unit)
+	if len(matches) != 2 {
+		return ""
+	}
+	return strings.TrimSpace(matches[1])
+}
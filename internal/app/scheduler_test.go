@@ -8,6 +8,7 @@ import (
 	"strings"
 	"testing"
 
+	"bb-project/internal/domain"
 	"bb-project/internal/state"
 )
 
@@ -105,7 +106,7 @@ func TestRunSchedulerStatusReportsInstalled(t *testing.T) {
 		return schedulerLaunchdLabel + "\n", nil
 	}
 
-	code, err := a.RunSchedulerStatus()
+	code, err := a.RunSchedulerStatus("")
 	if err != nil {
 		t.Fatalf("RunSchedulerStatus failed: %v", err)
 	}
@@ -144,7 +145,7 @@ func TestRunSchedulerRemoveRemovesPlist(t *testing.T) {
 		return "", nil
 	}
 
-	code, err := a.RunSchedulerRemove()
+	code, err := a.RunSchedulerRemove("")
 	if err != nil {
 		t.Fatalf("RunSchedulerRemove failed: %v", err)
 	}
@@ -164,9 +165,9 @@ func TestRunSchedulerUnsupportedOS(t *testing.T) {
 
 	paths := state.NewPaths(t.TempDir())
 	a := New(paths, &bytes.Buffer{}, &bytes.Buffer{})
-	a.GOOS = func() string { return "linux" }
+	a.GOOS = func() string { return "windows" }
 
-	code, err := a.RunSchedulerStatus()
+	code, err := a.RunSchedulerStatus("")
 	if err == nil {
 		t.Fatal("expected unsupported OS error")
 	}
@@ -174,3 +175,289 @@ func TestRunSchedulerUnsupportedOS(t *testing.T) {
 		t.Fatalf("exit code = %d, want 2", code)
 	}
 }
+
+func TestRunSchedulerInstallWritesSystemdUnits(t *testing.T) {
+	t.Parallel()
+
+	home := t.TempDir()
+	paths := state.NewPaths(home)
+	cfg := state.DefaultConfig()
+	cfg.Scheduler.IntervalMinutes = 30
+	if err := state.SaveConfig(paths, cfg); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	a := New(paths, &stdout, &bytes.Buffer{})
+	a.GOOS = func() string { return "linux" }
+	a.Getenv = func(string) string { return "" }
+	a.ExecutablePath = func() (string, error) { return "/opt/my tools/bb", nil }
+
+	var calls []string
+	a.RunCommand = func(name string, args ...string) (string, error) {
+		calls = append(calls, strings.Join(append([]string{name}, args...), " "))
+		return "", nil
+	}
+
+	code, err := a.RunSchedulerInstall(SchedulerInstallOptions{})
+	if err != nil {
+		t.Fatalf("RunSchedulerInstall failed: %v", err)
+	}
+	if code != 0 {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+
+	service, err := os.ReadFile(schedulerSystemdServicePath(home))
+	if err != nil {
+		t.Fatalf("read service: %v", err)
+	}
+	serviceText := string(service)
+	if !strings.Contains(serviceText, `ExecStart="/opt/my tools/bb" sync --notify --quiet --notify-backend notify-send`) {
+		t.Fatalf("expected quoted executable and notify-send backend in service, got:\n%s", serviceText)
+	}
+	wantLog := "StandardOutput=append:" + filepath.Join(paths.LocalStateRoot(), "scheduler-sync.log")
+	if !strings.Contains(serviceText, wantLog) {
+		t.Fatalf("expected %q in service, got:\n%s", wantLog, serviceText)
+	}
+
+	timer, err := os.ReadFile(schedulerSystemdTimerPath(home))
+	if err != nil {
+		t.Fatalf("read timer: %v", err)
+	}
+	if got := extractSystemdTimerIntervalMinutes(string(timer)); got != 30 {
+		t.Fatalf("timer interval = %d, want 30", got)
+	}
+
+	wantCall := "systemctl --user enable --now " + schedulerSystemdUnit + ".timer"
+	found := false
+	for _, call := range calls {
+		if call == wantCall {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected %q call, got %v", wantCall, calls)
+	}
+	if !strings.Contains(stdout.String(), "backend=systemd") {
+		t.Fatalf("expected systemd backend in output, got:\n%s", stdout.String())
+	}
+}
+
+func TestRunSchedulerInstallDefersToConfiguredNotifyBackends(t *testing.T) {
+	t.Parallel()
+
+	home := t.TempDir()
+	paths := state.NewPaths(home)
+	cfg := state.DefaultConfig()
+	cfg.Notify.Backends = []domain.NotifyBackendConfig{{Name: notifyBackendNotifySend}, {Name: notifyBackendStdout}}
+	if err := state.SaveConfig(paths, cfg); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	a := New(paths, &stdout, &bytes.Buffer{})
+	a.GOOS = func() string { return "linux" }
+	a.Getenv = func(string) string { return "" }
+	a.ExecutablePath = func() (string, error) { return "/usr/local/bin/bb", nil }
+	a.RunCommand = func(name string, args ...string) (string, error) { return "", nil }
+
+	if _, err := a.RunSchedulerInstall(SchedulerInstallOptions{}); err != nil {
+		t.Fatalf("RunSchedulerInstall failed: %v", err)
+	}
+	service, err := os.ReadFile(schedulerSystemdServicePath(home))
+	if err != nil {
+		t.Fatalf("read service: %v", err)
+	}
+	if strings.Contains(string(service), "--notify-backend") {
+		t.Fatalf("expected no --notify-backend in service, got:\n%s", service)
+	}
+	if !strings.Contains(stdout.String(), "notify_backend=config") {
+		t.Fatalf("expected config notify backend in output, got:\n%s", stdout.String())
+	}
+}
+
+func TestRunSchedulerStatusReportsSystemdTimer(t *testing.T) {
+	t.Parallel()
+
+	home := t.TempDir()
+	paths := state.NewPaths(home)
+	if err := os.MkdirAll(schedulerSystemdUnitDir(home), 0o755); err != nil {
+		t.Fatalf("mkdir unit dir: %v", err)
+	}
+	job := schedulerJob{Executable: "/tmp/bb", IntervalMinutes: 45, NotifyBackend: notifyBackendStdout, StdoutPath: "/tmp/bb.log", StderrPath: "/tmp/bb.err.log"}
+	if err := os.WriteFile(schedulerSystemdServicePath(home), []byte(sampleSchedulerSystemdService(job)), 0o644); err != nil {
+		t.Fatalf("write service: %v", err)
+	}
+	if err := os.WriteFile(schedulerSystemdTimerPath(home), []byte(sampleSchedulerSystemdTimer(45*60)), 0o644); err != nil {
+		t.Fatalf("write timer: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	a := New(paths, &stdout, &bytes.Buffer{})
+	a.GOOS = func() string { return "linux" }
+	a.RunCommand = func(name string, args ...string) (string, error) {
+		return "active\n", nil
+	}
+
+	code, err := a.RunSchedulerStatus("")
+	if err != nil {
+		t.Fatalf("RunSchedulerStatus failed: %v", err)
+	}
+	if code != 0 {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+	out := stdout.String()
+	for _, want := range []string{"installed=true", "loaded=true", "backend=systemd", "interval_minutes=45", "notify_backend=stdout"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestRunSchedulerRemoveRemovesSystemdUnits(t *testing.T) {
+	t.Parallel()
+
+	home := t.TempDir()
+	paths := state.NewPaths(home)
+	if err := os.MkdirAll(schedulerSystemdUnitDir(home), 0o755); err != nil {
+		t.Fatalf("mkdir unit dir: %v", err)
+	}
+	for _, path := range []string{schedulerSystemdServicePath(home), schedulerSystemdTimerPath(home)} {
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatalf("write unit: %v", err)
+		}
+	}
+
+	a := New(paths, &bytes.Buffer{}, &bytes.Buffer{})
+	a.GOOS = func() string { return "linux" }
+	a.RunCommand = func(name string, args ...string) (string, error) { return "", nil }
+
+	code, err := a.RunSchedulerRemove("")
+	if err != nil {
+		t.Fatalf("RunSchedulerRemove failed: %v", err)
+	}
+	if code != 0 {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+	for _, path := range []string{schedulerSystemdServicePath(home), schedulerSystemdTimerPath(home)} {
+		if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("expected %s removal, stat err=%v", path, err)
+		}
+	}
+}
+
+func TestParseSystemdDurationSeconds(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]int{
+		"3600s": 3600,
+		"3600":  3600,
+		"45min": 2700,
+		"2h":    7200,
+		"bogus": 0,
+	}
+	for raw, want := range cases {
+		if got := parseSystemdDurationSeconds(raw); got != want {
+			t.Fatalf("parseSystemdDurationSeconds(%q) = %d, want %d", raw, got, want)
+		}
+	}
+}
+
+func TestRunSchedulerCronInstallStatusRemovePreservesOtherEntries(t *testing.T) {
+	t.Parallel()
+
+	home := t.TempDir()
+	paths := state.NewPaths(home)
+	cfg := state.DefaultConfig()
+	cfg.Scheduler.IntervalMinutes = 15
+	if err := state.SaveConfig(paths, cfg); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	crontab := "MAILTO=\"\"\n0 3 * * * /usr/bin/backup\n"
+	var stdout bytes.Buffer
+	a := New(paths, &stdout, &bytes.Buffer{})
+	a.GOOS = func() string { return "linux" }
+	a.Getenv = func(string) string { return "" }
+	a.ExecutablePath = func() (string, error) { return "/usr/local/bin/bb", nil }
+	a.RunCommand = func(name string, args ...string) (string, error) {
+		if name != "crontab" {
+			t.Fatalf("unexpected command %s %v", name, args)
+		}
+		if len(args) == 1 && args[0] == "-l" {
+			return crontab, nil
+		}
+		raw, err := os.ReadFile(args[0])
+		if err != nil {
+			t.Fatalf("read crontab input: %v", err)
+		}
+		crontab = string(raw)
+		return "", nil
+	}
+
+	if code, err := a.RunSchedulerInstall(SchedulerInstallOptions{Backend: schedulerBackendCron}); err != nil || code != 0 {
+		t.Fatalf("install code=%d err=%v", code, err)
+	}
+	if !strings.Contains(crontab, "0 3 * * * /usr/bin/backup") {
+		t.Fatalf("expected unrelated entry to be preserved, got:\n%s", crontab)
+	}
+	if !strings.Contains(crontab, "*/15 * * * * /usr/local/bin/bb sync --notify --quiet --notify-backend stdout") {
+		t.Fatalf("expected managed entry, got:\n%s", crontab)
+	}
+
+	// Reinstalling replaces the block instead of appending a second one.
+	if code, err := a.RunSchedulerInstall(SchedulerInstallOptions{Backend: schedulerBackendCron}); err != nil || code != 0 {
+		t.Fatalf("reinstall code=%d err=%v", code, err)
+	}
+	if got := strings.Count(crontab, schedulerCronBeginMarker); got != 1 {
+		t.Fatalf("begin markers = %d, want 1:\n%s", got, crontab)
+	}
+
+	stdout.Reset()
+	if code, err := a.RunSchedulerStatus(""); err != nil || code != 0 {
+		t.Fatalf("status code=%d err=%v", code, err)
+	}
+	for _, want := range []string{"installed=true", "backend=cron", "interval_minutes=15", "notify_backend=stdout"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Fatalf("expected %q in status output, got:\n%s", want, stdout.String())
+		}
+	}
+
+	if code, err := a.RunSchedulerRemove(schedulerBackendCron); err != nil || code != 0 {
+		t.Fatalf("remove code=%d err=%v", code, err)
+	}
+	if crontab != "MAILTO=\"\"\n0 3 * * * /usr/bin/backup\n" {
+		t.Fatalf("crontab after remove = %q", crontab)
+	}
+}
+
+func TestCronScheduleForInterval(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		minutes       int
+		wantSchedule  string
+		wantEffective int
+	}{
+		{minutes: 1, wantSchedule: "* * * * *", wantEffective: 1},
+		{minutes: 20, wantSchedule: "*/20 * * * *", wantEffective: 20},
+		{minutes: 60, wantSchedule: "0 * * * *", wantEffective: 60},
+		{minutes: 45, wantSchedule: "*/30 * * * *", wantEffective: 30},
+		{minutes: 50, wantSchedule: "0 * * * *", wantEffective: 60},
+		{minutes: 90, wantSchedule: "0 * * * *", wantEffective: 60},
+		{minutes: 150, wantSchedule: "0 */2 * * *", wantEffective: 120},
+		{minutes: 300, wantSchedule: "0 */4 * * *", wantEffective: 240},
+		{minutes: 450, wantSchedule: "0 */8 * * *", wantEffective: 480},
+		{minutes: 3000, wantSchedule: "0 0 * * *", wantEffective: 1440},
+	}
+	for _, tc := range cases {
+		schedule, effective := cronScheduleForInterval(tc.minutes)
+		if schedule != tc.wantSchedule || effective != tc.wantEffective {
+			t.Fatalf("cronScheduleForInterval(%d) = (%q, %d), want (%q, %d)", tc.minutes, schedule, effective, tc.wantSchedule, tc.wantEffective)
+		}
+		block := sampleSchedulerCronBlock(schedule, schedulerJob{Executable: "/tmp/bb", NotifyBackend: notifyBackendStdout})
+		if got := extractCronIntervalMinutes(block); got != tc.wantEffective {
+			t.Fatalf("extractCronIntervalMinutes(%q) = %d, want %d", schedule, got, tc.wantEffective)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"bb-project/internal/domain"
+	"bb-project/internal/state"
+)
+
+// stateConflictReportWindow is how long resolved conflict copies keep showing
+// up in `bb doctor`.
+const stateConflictReportWindow = 7 * 24 * time.Hour
+
+// resolveStateConflictCopiesLocked folds sync-tool conflict copies of shared
+// state files back into their originals. Callers must hold the global lock.
+func (a *App) resolveStateConflictCopiesLocked() error {
+	now := a.Now()
+	resolutions, err := state.ResolveConflictCopies(a.Paths, now)
+	if len(resolutions) > 0 {
+		for _, r := range resolutions {
+			a.logf("state: resolved conflict copy %s for %s (%s)", r.ConflictCopy, r.Target, r.Action)
+		}
+		if logErr := state.AppendConflictLog(a.Paths, resolutions, now, stateConflictReportWindow); logErr != nil && err == nil {
+			err = logErr
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("resolve state conflict copies: %w", err)
+	}
+	return nil
+}
+
+// recentStateConflictResolutions returns logged resolutions still inside the
+// doctor report window.
+func (a *App) recentStateConflictResolutions() ([]domain.StateConflictResolution, error) {
+	log, err := state.LoadConflictLog(a.Paths)
+	if err != nil {
+		return nil, err
+	}
+	now := a.Now()
+	out := make([]domain.StateConflictResolution, 0, len(log.Resolutions))
+	for _, r := range log.Resolutions {
+		if now.Sub(r.ResolvedAt) > stateConflictReportWindow {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+func stateConflictResolutionMessage(r domain.StateConflictResolution) string {
+	return fmt.Sprintf("resolved sync conflict copy %s for %s (%s)", r.ConflictCopy, r.Target, r.Action)
+}
+
+func (a *App) reportStateConflictResolutions() error {
+	resolutions, err := a.recentStateConflictResolutions()
+	if err != nil {
+		return err
+	}
+	for _, r := range resolutions {
+		fmt.Fprintf(a.Stdout, "info: %s, archived to %s\n", stateConflictResolutionMessage(r), r.ArchivedTo)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"bb-project/internal/domain"
+	"bb-project/internal/state"
+)
+
+func TestRunDoctorResolvesAndReportsStateConflictCopies(t *testing.T) {
+	home := t.TempDir()
+	paths := state.NewPaths(home)
+	now := time.Date(2026, 2, 15, 12, 0, 0, 0, time.UTC)
+	t.Setenv("BB_MACHINE_ID", "machine-a")
+
+	if err := state.SaveConfig(paths, state.DefaultConfig()); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	machine := state.BootstrapMachine("machine-a", "host-a", now)
+	machine.LastScanAt = now
+	machine.Catalogs = []domain.Catalog{{Name: "software", Root: filepath.Join(home, "software")}}
+	machine.DefaultCatalog = "software"
+	machine.LastScanCatalogs = []string{"software"}
+	if err := state.SaveMachine(paths, machine); err != nil {
+		t.Fatalf("save machine: %v", err)
+	}
+	if err := state.SaveRepoMetadata(paths, domain.RepoMetadataFile{
+		Version:          1,
+		RepoKey:          "software/api",
+		Name:             "api",
+		PreviousRepoKeys: []string{"old/api"},
+	}); err != nil {
+		t.Fatalf("save repo metadata: %v", err)
+	}
+	copyName := "software__api.sync-conflict-20260215-115900-ABCDEFG.yaml"
+	if err := state.SaveYAML(filepath.Join(paths.RepoDir(), copyName), domain.RepoMetadataFile{
+		Version:          1,
+		RepoKey:          "software/api",
+		Name:             "api",
+		PreviousRepoKeys: []string{"legacy/api"},
+	}); err != nil {
+		t.Fatalf("save conflict copy: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	a := New(paths, &stdout, &bytes.Buffer{})
+	a.Now = func() time.Time { return now }
+	a.Hostname = func() (string, error) { return "host-a", nil }
+
+	code, err := a.RunDoctor(DoctorOptions{})
+	if err != nil {
+		t.Fatalf("RunDoctor: %v", err)
+	}
+	if code != 0 {
+		t.Fatalf("exit code = %d, want 0; stdout=%s", code, stdout.String())
+	}
+	want := "info: resolved sync conflict copy " + copyName + " for repos/software__api.yaml (merged)"
+	if !strings.Contains(stdout.String(), want) {
+		t.Fatalf("stdout missing %q:\n%s", want, stdout.String())
+	}
+	if _, err := os.Stat(filepath.Join(paths.RepoDir(), copyName)); !os.IsNotExist(err) {
+		t.Fatalf("conflict copy still in repos/, stat err = %v", err)
+	}
+	metas, err := state.LoadAllRepoMetadata(paths)
+	if err != nil {
+		t.Fatalf("load metadata: %v", err)
+	}
+	if len(metas) != 1 || strings.Join(metas[0].PreviousRepoKeys, ",") != "legacy/api,old/api" {
+		t.Fatalf("metadata = %+v", metas)
+	}
+
+	// The resolution stays visible in later runs inside the report window.
+	stdout.Reset()
+	a.Now = func() time.Time { return now.Add(time.Hour) }
+	if _, err := a.RunDoctor(DoctorOptions{JSON: true}); err != nil {
+		t.Fatalf("RunDoctor json: %v", err)
+	}
+	var report doctorJSONReport
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("decode report: %v\n%s", err, stdout.String())
+	}
+	if !report.OK || len(report.Findings) != 1 {
+		t.Fatalf("report = %+v", report)
+	}
+	finding := report.Findings[0]
+	if finding.Kind != doctorFindingStateConflict || finding.Severity != doctorSeverityInfo || finding.ConflictCopy != copyName || finding.Action != domain.StateConflictMerged {
+		t.Fatalf("finding = %+v", finding)
+	}
+}
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"bb-project/internal/state"
+)
+
+// RunStateMigrate upgrades every state file to the schema versions this
+// binary writes. Files written by a newer bb block the whole run so shared
+// state is never half-migrated.
+func (a *App) RunStateMigrate(opts StateMigrateOptions) (int, error) {
+	a.logf("state migrate: acquiring global lock")
+	lock, err := state.AcquireLock(a.Paths)
+	if err != nil {
+		return 2, err
+	}
+	defer func() {
+		_ = lock.Release()
+		a.logf("state migrate: released global lock")
+	}()
+
+	plans, err := state.PlanMigrations(a.Paths)
+	if err != nil {
+		return 2, err
+	}
+	tooNew := 0
+	for _, plan := range plans {
+		label := a.stateFileLabel(plan.Path)
+		if plan.TooNew {
+			tooNew++
+			fmt.Fprintf(a.Stdout, "too new: %s (%s v%d, this bb supports v%d)\n", label, plan.Kind, plan.FromVersion, plan.ToVersion)
+			continue
+		}
+		verb := "migrate"
+		if opts.DryRun {
+			verb = "would migrate"
+		}
+		fmt.Fprintf(a.Stdout, "%s: %s (%s v%d -> v%d)\n", verb, label, plan.Kind, plan.FromVersion, plan.ToVersion)
+		for _, step := range plan.Steps {
+			fmt.Fprintf(a.Stdout, "  - %s\n", step)
+		}
+	}
+
+	if tooNew > 0 {
+		fmt.Fprintf(a.Stdout, "%d state file(s) were written by a newer bb; upgrade bb before migrating\n", tooNew)
+		return 1, nil
+	}
+	if len(plans) == 0 {
+		fmt.Fprintln(a.Stdout, "state files are up to date")
+		return 0, nil
+	}
+	if opts.DryRun {
+		fmt.Fprintf(a.Stdout, "dry run: %d file(s) would be migrated\n", len(plans))
+		return 0, nil
+	}
+	if err := state.ApplyMigrations(a.Paths, plans); err != nil {
+		return 2, err
+	}
+	a.logf("state migrate: migrated %d file(s)", len(plans))
+	fmt.Fprintf(a.Stdout, "migrated %d file(s)\n", len(plans))
+	return 0, nil
+}
+
+// stateFileLabel shows state files relative to their root when possible.
+func (a *App) stateFileLabel(path string) string {
+	for _, root := range []string{a.Paths.ConfigRoot(), a.Paths.LocalStateRoot()} {
+		if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return path
+}
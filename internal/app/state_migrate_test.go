@@ -0,0 +1,87 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bb-project/internal/state"
+)
+
+func TestRunStateMigrateDryRunThenApply(t *testing.T) {
+	t.Parallel()
+
+	paths := state.NewPaths(t.TempDir())
+	if err := state.EnsureDir(paths.RepoDir()); err != nil {
+		t.Fatalf("EnsureDir: %v", err)
+	}
+	repoPath := filepath.Join(paths.RepoDir(), "software__api.yaml")
+	legacy := "repo_key: software/api\nname: api\n"
+	if err := os.WriteFile(repoPath, []byte(legacy), 0o644); err != nil {
+		t.Fatalf("write repo: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	a := New(paths, &stdout, &bytes.Buffer{})
+	code, err := a.RunStateMigrate(StateMigrateOptions{DryRun: true})
+	if err != nil || code != 0 {
+		t.Fatalf("dry run = (%d, %v), want (0, nil)", code, err)
+	}
+	out := stdout.String()
+	for _, want := range []string{
+		"would migrate: repos/software__api.yaml (repo v0 -> v1)",
+		"  - v0 -> v1: add schema version",
+		"dry run: 1 file(s) would be migrated",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("stdout missing %q:\n%s", want, out)
+		}
+	}
+	if raw, _ := os.ReadFile(repoPath); string(raw) != legacy {
+		t.Fatalf("dry run rewrote file: %s", raw)
+	}
+
+	stdout.Reset()
+	code, err = a.RunStateMigrate(StateMigrateOptions{})
+	if err != nil || code != 0 {
+		t.Fatalf("apply = (%d, %v), want (0, nil)", code, err)
+	}
+	if !strings.Contains(stdout.String(), "migrated 1 file(s)") {
+		t.Fatalf("stdout = %s", stdout.String())
+	}
+
+	stdout.Reset()
+	if _, err := a.RunStateMigrate(StateMigrateOptions{DryRun: true}); err != nil {
+		t.Fatalf("second dry run: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "state files are up to date") {
+		t.Fatalf("stdout = %s", stdout.String())
+	}
+}
+
+func TestRunStateMigrateReportsNewerFiles(t *testing.T) {
+	t.Parallel()
+
+	paths := state.NewPaths(t.TempDir())
+	if err := state.EnsureDir(paths.MachineDir()); err != nil {
+		t.Fatalf("EnsureDir: %v", err)
+	}
+	if err := os.WriteFile(paths.MachinePath("mac-b"), []byte("version: 5\nmachine_id: mac-b\n"), 0o644); err != nil {
+		t.Fatalf("write machine: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	a := New(paths, &stdout, &bytes.Buffer{})
+	code, err := a.RunStateMigrate(StateMigrateOptions{})
+	if err != nil {
+		t.Fatalf("RunStateMigrate: %v", err)
+	}
+	if code != 1 {
+		t.Fatalf("exit code = %d, want 1", code)
+	}
+	if !strings.Contains(stdout.String(), "too new: machines/mac-b.yaml (machine v5, this bb supports v1)") {
+		t.Fatalf("stdout = %s", stdout.String())
+	}
+}
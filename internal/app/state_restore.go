@@ -0,0 +1,78 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"bb-project/internal/state"
+)
+
+// RunStateRestore lists the backups of a state file, or replaces the file with
+// one of them when opts.Backup is set.
+func (a *App) RunStateRestore(opts StateRestoreOptions) (int, error) {
+	path, _, err := state.ResolveStateFile(a.Paths, opts.File)
+	if err != nil {
+		return 2, err
+	}
+	label := a.stateFileLabel(path)
+
+	if strings.TrimSpace(opts.Backup) == "" {
+		backups, err := state.ListBackups(a.Paths, path)
+		if err != nil {
+			return 2, err
+		}
+		if len(backups) == 0 {
+			fmt.Fprintf(a.Stdout, "no backups of %s\n", label)
+			return 1, nil
+		}
+		fmt.Fprintf(a.Stdout, "backups of %s (newest first):\n", label)
+		for _, backup := range backups {
+			validity := "ok"
+			if !backup.Valid {
+				validity = "invalid"
+			}
+			fmt.Fprintf(a.Stdout, "  %s  %s  %s\n", backup.ID, backup.CreatedAt.Local().Format("2006-01-02 15:04:05"), validity)
+		}
+		return 0, nil
+	}
+
+	a.logf("state restore: acquiring global lock")
+	lock, err := state.AcquireLock(a.Paths)
+	if err != nil {
+		return 2, err
+	}
+	defer func() {
+		_ = lock.Release()
+		a.logf("state restore: released global lock")
+	}()
+
+	restored, err := state.RestoreBackup(a.Paths, path, strings.TrimSpace(opts.Backup))
+	if err != nil {
+		return 2, err
+	}
+	a.logf("state restore: restored %s from backup %s", path, restored.ID)
+	fmt.Fprintf(a.Stdout, "restored %s from backup %s\n", label, restored.ID)
+	return 0, nil
+}
+
+// stateBackupFallbackMessage describes a state file that loads are serving
+// from a backup.
+func (a *App) stateBackupFallbackMessage(fallback state.BackupFallback) string {
+	return fmt.Sprintf("%s does not parse; bb is reading backup %s instead", a.stateFileLabel(fallback.Path), fallback.Backup.ID)
+}
+
+func (a *App) stateBackupFallbackRemediation(fallback state.BackupFallback) string {
+	return fmt.Sprintf("run `bb state restore %s --backup %s`, or repair the file; the next save otherwise replaces it with the backup contents", a.stateFileLabel(fallback.Path), fallback.Backup.ID)
+}
+
+func (a *App) reportStateBackupFallbacks() (int, error) {
+	fallbacks, err := state.BackupFallbacks(a.Paths)
+	if err != nil {
+		return 0, err
+	}
+	for _, fallback := range fallbacks {
+		fmt.Fprintf(a.Stdout, "warning: %s\n", a.stateBackupFallbackMessage(fallback))
+		fmt.Fprintf(a.Stdout, "warning: %s\n", a.stateBackupFallbackRemediation(fallback))
+	}
+	return len(fallbacks), nil
+}
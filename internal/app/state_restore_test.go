@@ -0,0 +1,49 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"bb-project/internal/state"
+)
+
+func TestRunStateRestoreListsThenRestores(t *testing.T) {
+	t.Parallel()
+
+	paths := state.NewPaths(t.TempDir())
+	for _, hostname := range []string{"host-old", "host-new"} {
+		m := state.BootstrapMachine("mac-a", hostname, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+		if err := state.SaveMachine(paths, m); err != nil {
+			t.Fatalf("SaveMachine: %v", err)
+		}
+	}
+	if err := os.WriteFile(paths.MachinePath("mac-a"), []byte("machine_id: [\n"), 0o644); err != nil {
+		t.Fatalf("corrupt machine: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	a := New(paths, &stdout, &bytes.Buffer{})
+	code, err := a.RunStateRestore(StateRestoreOptions{File: "machines/mac-a.yaml"})
+	if err != nil || code != 0 {
+		t.Fatalf("list = (%d, %v), want (0, nil)", code, err)
+	}
+	if out := stdout.String(); !strings.Contains(out, "backups of machines/mac-a.yaml (newest first):") || !strings.Contains(out, "  ok") {
+		t.Fatalf("stdout = %s", out)
+	}
+
+	stdout.Reset()
+	code, err = a.RunStateRestore(StateRestoreOptions{File: "machines/mac-a.yaml", Backup: "latest"})
+	if err != nil || code != 0 {
+		t.Fatalf("restore = (%d, %v), want (0, nil)", code, err)
+	}
+	if !strings.HasPrefix(stdout.String(), "restored machines/mac-a.yaml from backup ") {
+		t.Fatalf("stdout = %s", stdout.String())
+	}
+	machine, err := state.LoadMachine(paths, "mac-a")
+	if err != nil || machine.Hostname != "host-old" {
+		t.Fatalf("machine = %+v, %v; want restored host-old", machine, err)
+	}
+}
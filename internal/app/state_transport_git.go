@@ -0,0 +1,288 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"bb-project/internal/domain"
+	"bb-project/internal/state"
+)
+
+const (
+	defaultStateTransportGitBranch = "main"
+	stateTransportGitRemote        = "origin"
+	stateTransportGitPushAttempts  = 3
+	// stateTransportGitMaxRebaseSteps bounds conflict resolution so a rebase
+	// that keeps stopping cannot loop forever.
+	stateTransportGitMaxRebaseSteps = 1000
+)
+
+// stateTransportGitIgnore keeps everything except the shared state out of the
+// state repository; config.yaml stays machine-local.
+const stateTransportGitIgnore = `/*
+!/.gitignore
+!/machines/
+!/repos/
+`
+
+func validateStateTransport(transport domain.StateTransport) error {
+	switch transport.Mode {
+	case domain.StateTransportExternal:
+		return nil
+	case domain.StateTransportGit:
+		if strings.TrimSpace(transport.Git.Remote) == "" {
+			return errors.New("state_transport.git.remote is required when state_transport.mode is git")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported state_transport.mode %q (want external or git)", transport.Mode)
+	}
+}
+
+func stateTransportGitBranch(cfg domain.ConfigFile) string {
+	if branch := strings.TrimSpace(cfg.StateTransport.Git.Branch); branch != "" {
+		return branch
+	}
+	return defaultStateTransportGitBranch
+}
+
+// pullStateTransport brings the shared state up to date before observing.
+// Local edits are committed first and rebased onto the remote branch.
+func (a *App) pullStateTransport(cfg domain.ConfigFile, machineID string) error {
+	if cfg.StateTransport.Mode != domain.StateTransportGit {
+		return nil
+	}
+	if err := a.ensureStateRepo(cfg); err != nil {
+		return err
+	}
+	if err := a.commitStateRepo(machineID); err != nil {
+		return err
+	}
+	return a.rebaseStateRepo(cfg, machineID)
+}
+
+// pushStateTransport publishes committed state. A rejected push is retried
+// after another pull so concurrent machines converge.
+func (a *App) pushStateTransport(cfg domain.ConfigFile, machineID string) error {
+	if cfg.StateTransport.Mode != domain.StateTransportGit {
+		return nil
+	}
+	if err := a.ensureStateRepo(cfg); err != nil {
+		return err
+	}
+	if err := a.commitStateRepo(machineID); err != nil {
+		return err
+	}
+	dir := a.Paths.ConfigRoot()
+	branch := stateTransportGitBranch(cfg)
+	if _, err := a.Git.RunGit(dir, "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		return nil
+	}
+
+	var pushErr error
+	for attempt := 1; attempt <= stateTransportGitPushAttempts; attempt++ {
+		_, pushErr = a.Git.RunGit(dir, "push", stateTransportGitRemote, "HEAD:refs/heads/"+branch)
+		if pushErr == nil {
+			a.logf("state transport: pushed %s", branch)
+			return nil
+		}
+		a.logf("state transport: push attempt %d failed, pulling before retry: %v", attempt, pushErr)
+		if err := a.rebaseStateRepo(cfg, machineID); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("push state repository: %w", pushErr)
+}
+
+func (a *App) ensureStateRepo(cfg domain.ConfigFile) error {
+	dir := a.Paths.ConfigRoot()
+	remote := strings.TrimSpace(cfg.StateTransport.Git.Remote)
+	if _, err := os.Stat(filepath.Join(dir, ".git")); errors.Is(err, os.ErrNotExist) {
+		a.logf("state transport: initializing state repository in %s", dir)
+		if err := state.EnsureDir(dir); err != nil {
+			return err
+		}
+		if _, err := a.Git.RunGit(dir, "init", "-b", stateTransportGitBranch(cfg)); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	ignorePath := filepath.Join(dir, ".gitignore")
+	if current, err := os.ReadFile(ignorePath); err != nil || string(current) != stateTransportGitIgnore {
+		if err := os.WriteFile(ignorePath, []byte(stateTransportGitIgnore), 0o644); err != nil {
+			return err
+		}
+	}
+
+	existing, err := a.Git.RunGit(dir, "remote", "get-url", stateTransportGitRemote)
+	if err != nil {
+		_, err = a.Git.RunGit(dir, "remote", "add", stateTransportGitRemote, remote)
+		return err
+	}
+	if existing != remote {
+		_, err = a.Git.RunGit(dir, "remote", "set-url", stateTransportGitRemote, remote)
+		return err
+	}
+	return nil
+}
+
+func (a *App) commitStateRepo(machineID string) error {
+	dir := a.Paths.ConfigRoot()
+	// git add rejects pathspecs that match nothing, so only name directories
+	// that exist.
+	pathspecs := []string{".gitignore"}
+	for _, sub := range []string{state.MachineDirName, state.RepoDirName} {
+		if _, err := os.Stat(filepath.Join(dir, sub)); err == nil {
+			pathspecs = append(pathspecs, sub)
+		}
+	}
+	if _, err := a.Git.RunGit(dir, append([]string{"add", "-A", "--"}, pathspecs...)...); err != nil {
+		return err
+	}
+	if _, err := a.Git.RunGit(dir, "diff", "--cached", "--quiet"); err == nil {
+		return nil
+	}
+	_, err := a.Git.RunGitNoHooks(dir, "commit", "--quiet", "-m", "bb: update state from "+machineID)
+	return err
+}
+
+func (a *App) rebaseStateRepo(cfg domain.ConfigFile, machineID string) error {
+	dir := a.Paths.ConfigRoot()
+	branch := stateTransportGitBranch(cfg)
+	heads, err := a.Git.RunGit(dir, "ls-remote", "--heads", stateTransportGitRemote, "refs/heads/"+branch)
+	if err != nil {
+		return fmt.Errorf("query state repository: %w", err)
+	}
+	if strings.TrimSpace(heads) == "" {
+		a.logf("state transport: remote branch %s does not exist yet", branch)
+		return nil
+	}
+	if _, err := a.Git.RunGit(dir, "fetch", "--quiet", stateTransportGitRemote, branch); err != nil {
+		return fmt.Errorf("fetch state repository: %w", err)
+	}
+	upstream := stateTransportGitRemote + "/" + branch
+	if _, err := a.Git.RunGit(dir, "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		_, err := a.Git.RunGit(dir, "checkout", "--quiet", "-B", branch, upstream)
+		return err
+	}
+
+	_, rebaseErr := a.Git.RunGitNoHooks(dir, "rebase", "--quiet", upstream)
+	for step := 0; rebaseErr != nil; step++ {
+		if step >= stateTransportGitMaxRebaseSteps {
+			_ = a.Git.RebaseAbort(dir)
+			return fmt.Errorf("rebase state repository: too many conflicting commits")
+		}
+		conflicted, err := a.Git.RunGit(dir, "diff", "--name-only", "--diff-filter=U")
+		if err != nil || strings.TrimSpace(conflicted) == "" {
+			_ = a.Git.RebaseAbort(dir)
+			return fmt.Errorf("rebase state repository: %w", rebaseErr)
+		}
+		for _, file := range strings.Split(conflicted, "\n") {
+			if err := a.resolveStateConflict(dir, strings.TrimSpace(file), machineID); err != nil {
+				_ = a.Git.RebaseAbort(dir)
+				return fmt.Errorf("resolve state conflict in %s: %w", file, err)
+			}
+		}
+		if _, err := a.Git.RunGit(dir, "diff", "--cached", "--quiet"); err == nil {
+			_, rebaseErr = a.Git.RunGitNoHooks(dir, "rebase", "--skip")
+			continue
+		}
+		_, rebaseErr = a.Git.RunGitNoHooks(dir, "-c", "core.editor=true", "rebase", "--continue")
+	}
+	a.logf("state transport: rebased onto %s", upstream)
+	return nil
+}
+
+// resolveStateConflict settles one conflicted file during a rebase. While
+// rebasing, stage 2 is the remote branch and stage 3 is the local commit being
+// replayed. The local machine's own file keeps the local copy, other machine
+// files keep the remote copy, and repo metadata is merged field by field.
+func (a *App) resolveStateConflict(dir, file, machineID string) error {
+	if file == "" {
+		return nil
+	}
+	base, _ := a.stateConflictStage(dir, 1, file)
+	remote, hasRemote := a.stateConflictStage(dir, 2, file)
+	local, hasLocal := a.stateConflictStage(dir, 3, file)
+
+	var resolved string
+	var keep bool
+	switch {
+	case path.Dir(file) == state.MachineDirName:
+		if strings.TrimSuffix(path.Base(file), ".yaml") == machineID {
+			resolved, keep = local, hasLocal
+		} else {
+			resolved, keep = remote, hasRemote
+		}
+	case path.Dir(file) == state.RepoDirName && hasLocal && hasRemote:
+		merged, err := mergeRepoMetadataYAML(base, local, remote)
+		if err != nil {
+			return err
+		}
+		resolved, keep = merged, true
+	default:
+		// Delete/modify on a repo file, or anything else: keep whichever side
+		// still has content, preferring the remote.
+		if hasRemote {
+			resolved, keep = remote, true
+		} else {
+			resolved, keep = local, hasLocal
+		}
+	}
+
+	full := filepath.Join(dir, filepath.FromSlash(file))
+	if !keep {
+		_, err := a.Git.RunGit(dir, "rm", "--quiet", "--", file)
+		return err
+	}
+	if err := state.WriteStateFile(a.Paths, full, []byte(resolved)); err != nil {
+		return err
+	}
+	_, err := a.Git.RunGit(dir, "add", "--", file)
+	return err
+}
+
+func (a *App) stateConflictStage(dir string, stage int, file string) (string, bool) {
+	out, err := a.Git.RunGit(dir, "show", fmt.Sprintf(":%d:%s", stage, file))
+	if err != nil {
+		return "", false
+	}
+	return out + "\n", true
+}
+
+// mergeRepoMetadataYAML merges the three stages of a repo metadata file. When
+// either side was written by a newer bb, that side is kept verbatim: decoding
+// it into the current struct would drop the fields this bb does not know.
+func mergeRepoMetadataYAML(base, local, remote string) (string, error) {
+	var baseMeta, localMeta, remoteMeta domain.RepoMetadataFile
+	for _, item := range []struct {
+		raw string
+		out *domain.RepoMetadataFile
+	}{{base, &baseMeta}, {local, &localMeta}, {remote, &remoteMeta}} {
+		if strings.TrimSpace(item.raw) == "" {
+			continue
+		}
+		if err := yaml.Unmarshal([]byte(item.raw), item.out); err != nil {
+			return "", err
+		}
+	}
+	if supported := state.SchemaVersion(state.FileKindRepo); localMeta.Version > supported || remoteMeta.Version > supported {
+		if localMeta.Version > remoteMeta.Version {
+			return local, nil
+		}
+		return remote, nil
+	}
+	encoded, err := yaml.Marshal(domain.MergeRepoMetadata(baseMeta, localMeta, remoteMeta))
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
@@ -0,0 +1,230 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"bb-project/internal/domain"
+	"bb-project/internal/state"
+)
+
+func newGitStateTransportTestApp(t *testing.T, remote string, machineID string, now time.Time) (*App, state.Paths, domain.ConfigFile) {
+	t.Helper()
+
+	paths := state.NewPaths(t.TempDir())
+	cfg := state.DefaultConfig()
+	cfg.StateTransport = domain.StateTransport{
+		Mode: domain.StateTransportGit,
+		Git:  domain.StateTransportGitRepo{Remote: remote},
+	}
+	if err := state.SaveConfig(paths, cfg); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	if err := state.SaveMachine(paths, state.BootstrapMachine(machineID, machineID, now)); err != nil {
+		t.Fatalf("save machine: %v", err)
+	}
+	a := New(paths, &bytes.Buffer{}, &bytes.Buffer{})
+	a.Now = func() time.Time { return now }
+	return a, paths, cfg
+}
+
+func newGitStateTransportRemote(t *testing.T) string {
+	t.Helper()
+
+	remote := filepath.Join(t.TempDir(), "state.git")
+	a := New(state.NewPaths(t.TempDir()), &bytes.Buffer{}, &bytes.Buffer{})
+	if _, err := a.Git.RunGit(filepath.Dir(remote), "init", "--bare", "-b", "main", remote); err != nil {
+		t.Fatalf("init bare remote: %v", err)
+	}
+	return remote
+}
+
+func syncGitStateTransport(t *testing.T, a *App, cfg domain.ConfigFile, machineID string) {
+	t.Helper()
+
+	if err := a.pullStateTransport(cfg, machineID); err != nil {
+		t.Fatalf("pull state for %s: %v", machineID, err)
+	}
+	if err := a.pushStateTransport(cfg, machineID); err != nil {
+		t.Fatalf("push state for %s: %v", machineID, err)
+	}
+}
+
+func TestGitStateTransportExchangesMachineFiles(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 18, 9, 0, 0, 0, time.UTC)
+	remote := newGitStateTransportRemote(t)
+	appA, pathsA, cfgA := newGitStateTransportTestApp(t, remote, "machine-a", now)
+	appB, pathsB, cfgB := newGitStateTransportTestApp(t, remote, "machine-b", now)
+
+	syncGitStateTransport(t, appA, cfgA, "machine-a")
+	syncGitStateTransport(t, appB, cfgB, "machine-b")
+	syncGitStateTransport(t, appA, cfgA, "machine-a")
+
+	for _, paths := range []state.Paths{pathsA, pathsB} {
+		machines, err := state.LoadAllMachineFiles(paths)
+		if err != nil {
+			t.Fatalf("load machines: %v", err)
+		}
+		if len(machines) != 2 {
+			t.Fatalf("machines in %s = %d, want 2", paths.Home, len(machines))
+		}
+	}
+
+	tracked, err := appA.Git.RunGit(pathsA.ConfigRoot(), "ls-files")
+	if err != nil {
+		t.Fatalf("ls-files: %v", err)
+	}
+	if strings.Contains(tracked, state.ConfigFileName) {
+		t.Fatalf("config.yaml must stay machine-local, tracked:\n%s", tracked)
+	}
+}
+
+func TestGitStateTransportResolvesConflicts(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 18, 9, 0, 0, 0, time.UTC)
+	remote := newGitStateTransportRemote(t)
+	appA, pathsA, cfgA := newGitStateTransportTestApp(t, remote, "machine-a", now)
+	appB, pathsB, cfgB := newGitStateTransportTestApp(t, remote, "machine-b", now)
+
+	base := domain.RepoMetadataFile{
+		RepoKey:          "software/api",
+		Name:             "api",
+		OriginURL:        "git@github.com:you/api.git",
+		Visibility:       domain.VisibilityPrivate,
+		PreferredCatalog: "software",
+		AutoPush:         domain.AutoPushModeDisabled,
+		PreviousRepoKeys: []string{"old/api"},
+	}
+	if err := state.SaveRepoMetadata(pathsA, base); err != nil {
+		t.Fatalf("save metadata: %v", err)
+	}
+	syncGitStateTransport(t, appA, cfgA, "machine-a")
+	syncGitStateTransport(t, appB, cfgB, "machine-b")
+	syncGitStateTransport(t, appA, cfgA, "machine-a")
+
+	// Concurrent edits: A changes auto_push and its own machine file; B changes
+	// visibility and scribbles on A's file; both record a previous key, which
+	// makes the repo file conflict textually.
+	editA := base
+	editA.AutoPush = domain.AutoPushModeEnabled
+	editA.PreviousRepoKeys = []string{"old/api", "legacy/api"}
+	if err := state.SaveRepoMetadata(pathsA, editA); err != nil {
+		t.Fatalf("save metadata A: %v", err)
+	}
+	machineA := state.BootstrapMachine("machine-a", "host-a-renamed", now.Add(time.Minute))
+	if err := state.SaveMachine(pathsA, machineA); err != nil {
+		t.Fatalf("save machine A: %v", err)
+	}
+	syncGitStateTransport(t, appA, cfgA, "machine-a")
+
+	editB := base
+	editB.Visibility = domain.VisibilityPublic
+	editB.PreviousRepoKeys = []string{"old/api", "moved/api"}
+	if err := state.SaveRepoMetadata(pathsB, editB); err != nil {
+		t.Fatalf("save metadata B: %v", err)
+	}
+	if err := state.SaveMachine(pathsB, state.BootstrapMachine("machine-a", "stale-copy", now)); err != nil {
+		t.Fatalf("save stale machine A copy: %v", err)
+	}
+	syncGitStateTransport(t, appB, cfgB, "machine-b")
+	syncGitStateTransport(t, appA, cfgA, "machine-a")
+
+	for _, paths := range []state.Paths{pathsA, pathsB} {
+		got, err := state.LoadRepoMetadata(paths, "software/api")
+		if err != nil {
+			t.Fatalf("load metadata: %v", err)
+		}
+		if got.AutoPush != domain.AutoPushModeEnabled || got.Visibility != domain.VisibilityPublic {
+			t.Fatalf("field merge lost an edit in %s: %#v", paths.Home, got)
+		}
+		if want := []string{"legacy/api", "moved/api", "old/api"}; !reflect.DeepEqual(got.PreviousRepoKeys, want) {
+			t.Fatalf("previous_repo_keys = %v, want %v", got.PreviousRepoKeys, want)
+		}
+		machine, err := state.LoadMachine(paths, "machine-a")
+		if err != nil {
+			t.Fatalf("load machine-a: %v", err)
+		}
+		if machine.Hostname != "host-a-renamed" {
+			t.Fatalf("machine-a hostname in %s = %q, want owner's copy", paths.Home, machine.Hostname)
+		}
+	}
+}
+
+func TestGitStateTransportKeepsNewerRepoMetadataVerbatim(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 18, 9, 0, 0, 0, time.UTC)
+	remote := newGitStateTransportRemote(t)
+	appA, pathsA, cfgA := newGitStateTransportTestApp(t, remote, "machine-a", now)
+	appB, pathsB, cfgB := newGitStateTransportTestApp(t, remote, "machine-b", now)
+
+	base := domain.RepoMetadataFile{RepoKey: "software/api", Name: "api", OriginURL: "git@github.com:you/api.git"}
+	if err := state.SaveRepoMetadata(pathsA, base); err != nil {
+		t.Fatalf("save metadata: %v", err)
+	}
+	syncGitStateTransport(t, appA, cfgA, "machine-a")
+	syncGitStateTransport(t, appB, cfgB, "machine-b")
+
+	editA := base
+	editA.AutoPush = domain.AutoPushModeEnabled
+	if err := state.SaveRepoMetadata(pathsA, editA); err != nil {
+		t.Fatalf("save metadata A: %v", err)
+	}
+	// B runs a newer bb that writes repo files at version 2 with a field this
+	// bb does not know.
+	newer := "version: 2\nrepo_key: software/api\nname: api\norigin_url: git@github.com:you/api.git\nauto_push: disabled\nfuture_field: keep-me\n"
+	if err := os.WriteFile(state.RepoMetaPath(pathsB, "software/api"), []byte(newer), 0o644); err != nil {
+		t.Fatalf("write newer metadata B: %v", err)
+	}
+	syncGitStateTransport(t, appB, cfgB, "machine-b")
+	syncGitStateTransport(t, appA, cfgA, "machine-a")
+
+	got, err := os.ReadFile(state.RepoMetaPath(pathsA, "software/api"))
+	if err != nil {
+		t.Fatalf("read metadata A: %v", err)
+	}
+	if string(got) != newer {
+		t.Fatalf("metadata after rebase = %q, want the version 2 file verbatim", got)
+	}
+}
+
+func TestRunSyncPushesStateWithGitTransport(t *testing.T) {
+	now := time.Date(2026, 2, 18, 9, 0, 0, 0, time.UTC)
+	t.Setenv("BB_MACHINE_ID", "machine-a")
+	remote := newGitStateTransportRemote(t)
+	a, _, _ := newGitStateTransportTestApp(t, remote, "machine-a", now)
+	a.Hostname = func() (string, error) { return "machine-a", nil }
+
+	if _, err := a.runSync(SyncOptions{}); err != nil {
+		t.Fatalf("runSync failed: %v", err)
+	}
+	tree, err := a.Git.RunGit(remote, "ls-tree", "-r", "--name-only", "main")
+	if err != nil {
+		t.Fatalf("ls-tree remote: %v", err)
+	}
+	if !strings.Contains(tree, "machines/machine-a.yaml") {
+		t.Fatalf("expected machine file on remote, got:\n%s", tree)
+	}
+}
+
+func TestValidateStateTransport(t *testing.T) {
+	t.Parallel()
+
+	if err := validateStateTransport(domain.StateTransport{Mode: domain.StateTransportExternal}); err != nil {
+		t.Fatalf("external: %v", err)
+	}
+	if err := validateStateTransport(domain.StateTransport{Mode: domain.StateTransportGit}); err == nil || !strings.Contains(err.Error(), "state_transport.git.remote is required") {
+		t.Fatalf("git without remote: %v", err)
+	}
+	if err := validateStateTransport(domain.StateTransport{Mode: "syncthing"}); err == nil {
+		t.Fatal("expected unknown mode to be rejected")
+	}
+}
@@ -0,0 +1,304 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"bb-project/internal/domain"
+	"bb-project/internal/state"
+)
+
+// statusFleetJSONSchemaVersion versions `bb status --all-machines --json`; see
+// docs/schema/bb-status-fleet.v1.schema.json.
+const statusFleetJSONSchemaVersion = 1
+
+const statusFleetShortSHALength = 7
+
+type statusFleetJSONReport struct {
+	SchemaVersion  int                      `json:"schema_version"`
+	LocalMachineID string                   `json:"local_machine_id"`
+	Machines       []statusFleetJSONMachine `json:"machines"`
+	Repos          []statusFleetJSONRepo    `json:"repos"`
+}
+
+type statusFleetJSONMachine struct {
+	MachineID  string     `json:"machine_id"`
+	Hostname   string     `json:"hostname"`
+	LastScanAt *time.Time `json:"last_scan_at"`
+	UpdatedAt  *time.Time `json:"updated_at"`
+}
+
+type statusFleetJSONRepo struct {
+	RepoKey         string                `json:"repo_key"`
+	WinnerMachineID string                `json:"winner_machine_id"`
+	Machines        []statusFleetJSONCell `json:"machines"`
+}
+
+type statusFleetJSONCell struct {
+	MachineID         string                    `json:"machine_id"`
+	Branch            string                    `json:"branch"`
+	HeadSHA           string                    `json:"head_sha"`
+	Ahead             int                       `json:"ahead"`
+	Behind            int                       `json:"behind"`
+	Syncable          bool                      `json:"syncable"`
+	UnsyncableReasons []domain.UnsyncableReason `json:"unsyncable_reasons"`
+	ObservedAt        *time.Time                `json:"observed_at"`
+	Winner            bool                      `json:"winner"`
+}
+
+// statusFleet is the repo_key x machine matrix shared by the table and JSON
+// renderers. Machines are ordered by hostname; rows by repo_key.
+type statusFleet struct {
+	machines []domain.MachineFile
+	rows     []statusFleetRow
+}
+
+type statusFleetRow struct {
+	repoKey string
+	winner  string
+	cells   map[string]domain.MachineRepoRecord
+}
+
+func (a *App) runStatusAllMachines(local domain.MachineFile, opts StatusOptions) (int, error) {
+	allowed := map[string]struct{}{}
+	if len(opts.IncludeCatalogs) > 0 {
+		selected, err := domain.SelectCatalogs(local, opts.IncludeCatalogs)
+		if err != nil {
+			return 2, err
+		}
+		for _, c := range selected {
+			allowed[c.Name] = struct{}{}
+		}
+	}
+
+	machines, err := state.LoadAllMachineFiles(a.Paths)
+	if err != nil {
+		return 2, err
+	}
+	metas, err := state.LoadAllRepoMetadata(a.Paths)
+	if err != nil {
+		return 2, err
+	}
+	policies := make(map[string]domain.RepoMetadataFile, len(metas))
+	for _, meta := range metas {
+		policies[meta.RepoKey] = meta
+	}
+	fleet := buildStatusFleet(mergeLocalMachine(machines, local), allowed, func(repoKey string) domain.WinnerPolicy {
+		meta, ok := policies[repoKey]
+		if !ok {
+			meta = domain.RepoMetadataFile{RepoKey: repoKey}
+		}
+		return a.repoWinnerPolicy(local, meta)
+	})
+
+	if opts.JSON {
+		if err := a.writeStatusFleetJSON(local.MachineID, fleet); err != nil {
+			return 2, err
+		}
+		return 0, nil
+	}
+	writeStatusFleetTable(a.Stdout, fleet)
+	a.logf("status: reported %d repo(s) across %d machine(s)", len(fleet.rows), len(fleet.machines))
+	return 0, nil
+}
+
+// mergeLocalMachine replaces the on-disk copy of the local machine with the
+// in-memory one so a freshly bootstrapped machine still gets a column.
+func mergeLocalMachine(machines []domain.MachineFile, local domain.MachineFile) []domain.MachineFile {
+	out := make([]domain.MachineFile, 0, len(machines)+1)
+	replaced := false
+	for _, m := range machines {
+		if m.MachineID == local.MachineID {
+			out = append(out, local)
+			replaced = true
+			continue
+		}
+		out = append(out, m)
+	}
+	if !replaced {
+		out = append(out, local)
+	}
+	return out
+}
+
+// buildStatusFleet builds the fleet matrix. policyFor, when non-nil,
+// supplies the winner policy used to mark each row's winner, as sync does.
+func buildStatusFleet(
+	machines []domain.MachineFile,
+	allowedCatalogs map[string]struct{},
+	policyFor func(repoKey string) domain.WinnerPolicy,
+) statusFleet {
+	sorted := append([]domain.MachineFile(nil), machines...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		hi, hj := statusFleetMachineLabel(sorted[i]), statusFleetMachineLabel(sorted[j])
+		if hi != hj {
+			return hi < hj
+		}
+		return sorted[i].MachineID < sorted[j].MachineID
+	})
+
+	rowsByKey := map[string]*statusFleetRow{}
+	for _, m := range sorted {
+		for _, rec := range m.Repos {
+			repoKey := strings.TrimSpace(rec.RepoKey)
+			if repoKey == "" {
+				continue
+			}
+			if len(allowedCatalogs) > 0 {
+				catalog, _, _, err := domain.ParseRepoKey(repoKey)
+				if err != nil {
+					continue
+				}
+				if _, ok := allowedCatalogs[catalog]; !ok {
+					continue
+				}
+			}
+			row, ok := rowsByKey[repoKey]
+			if !ok {
+				row = &statusFleetRow{repoKey: repoKey, cells: map[string]domain.MachineRepoRecord{}}
+				rowsByKey[repoKey] = row
+			}
+			row.cells[m.MachineID] = rec
+		}
+	}
+
+	fleet := statusFleet{machines: sorted, rows: make([]statusFleetRow, 0, len(rowsByKey))}
+	for repoKey, row := range rowsByKey {
+		var policy domain.WinnerPolicy
+		if policyFor != nil {
+			policy = policyFor(repoKey)
+		}
+		if selection, ok := selectWinnerForRepo(sorted, repoKey, policy); ok {
+			row.winner = selection.Winner.MachineID
+		}
+		fleet.rows = append(fleet.rows, *row)
+	}
+	sort.Slice(fleet.rows, func(i, j int) bool { return fleet.rows[i].repoKey < fleet.rows[j].repoKey })
+	return fleet
+}
+
+func statusFleetMachineLabel(m domain.MachineFile) string {
+	if hostname := strings.TrimSpace(m.Hostname); hostname != "" {
+		return hostname
+	}
+	return m.MachineID
+}
+
+func writeStatusFleetTable(out anyWriter, fleet statusFleet) {
+	header := make([]string, 0, len(fleet.machines)+1)
+	header = append(header, "REPO")
+	for _, m := range fleet.machines {
+		header = append(header, statusFleetMachineLabel(m))
+	}
+	table := [][]string{header}
+	for _, row := range fleet.rows {
+		line := make([]string, 0, len(fleet.machines)+1)
+		line = append(line, row.repoKey)
+		for _, m := range fleet.machines {
+			rec, ok := row.cells[m.MachineID]
+			if !ok {
+				line = append(line, "  -")
+				continue
+			}
+			line = append(line, formatStatusFleetCell(rec, row.winner == m.MachineID))
+		}
+		table = append(table, line)
+	}
+
+	widths := make([]int, len(header))
+	for _, line := range table {
+		for i, cell := range line {
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+	for _, line := range table {
+		var b strings.Builder
+		for i, cell := range line {
+			if i == len(line)-1 {
+				b.WriteString(cell)
+				break
+			}
+			b.WriteString(cell)
+			b.WriteString(strings.Repeat(" ", widths[i]-len(cell)+2))
+		}
+		fmt.Fprintln(out, strings.TrimRight(b.String(), " "))
+	}
+	fmt.Fprintln(out, "* = machine sync would pick as winner")
+}
+
+// formatStatusFleetCell renders "<mark> <branch> <sha> +ahead/-behind <state>".
+func formatStatusFleetCell(rec domain.MachineRepoRecord, winner bool) string {
+	mark := " "
+	if winner {
+		mark = "*"
+	}
+	syncState := "ok"
+	if !rec.Syncable {
+		syncState = "unsyncable"
+	}
+	return fmt.Sprintf("%s %s %s +%d/-%d %s", mark, valueOrDash(rec.Branch), valueOrDash(shortSHA(rec.HeadSHA)), rec.Ahead, rec.Behind, syncState)
+}
+
+func shortSHA(sha string) string {
+	sha = strings.TrimSpace(sha)
+	if len(sha) > statusFleetShortSHALength {
+		return sha[:statusFleetShortSHALength]
+	}
+	return sha
+}
+
+func (a *App) writeStatusFleetJSON(localMachineID string, fleet statusFleet) error {
+	report := statusFleetJSONReport{
+		SchemaVersion:  statusFleetJSONSchemaVersion,
+		LocalMachineID: localMachineID,
+		Machines:       make([]statusFleetJSONMachine, 0, len(fleet.machines)),
+		Repos:          make([]statusFleetJSONRepo, 0, len(fleet.rows)),
+	}
+	for _, m := range fleet.machines {
+		report.Machines = append(report.Machines, statusFleetJSONMachine{
+			MachineID:  m.MachineID,
+			Hostname:   m.Hostname,
+			LastScanAt: optionalTime(m.LastScanAt),
+			UpdatedAt:  optionalTime(m.UpdatedAt),
+		})
+	}
+	for _, row := range fleet.rows {
+		repo := statusFleetJSONRepo{
+			RepoKey:         row.repoKey,
+			WinnerMachineID: row.winner,
+			Machines:        []statusFleetJSONCell{},
+		}
+		for _, m := range fleet.machines {
+			rec, ok := row.cells[m.MachineID]
+			if !ok {
+				continue
+			}
+			reasons := rec.UnsyncableReasons
+			if reasons == nil {
+				reasons = []domain.UnsyncableReason{}
+			}
+			repo.Machines = append(repo.Machines, statusFleetJSONCell{
+				MachineID:         m.MachineID,
+				Branch:            rec.Branch,
+				HeadSHA:           rec.HeadSHA,
+				Ahead:             rec.Ahead,
+				Behind:            rec.Behind,
+				Syncable:          rec.Syncable,
+				UnsyncableReasons: reasons,
+				ObservedAt:        optionalTime(rec.ObservedAt),
+				Winner:            row.winner == m.MachineID,
+			})
+		}
+		report.Repos = append(report.Repos, repo)
+	}
+
+	encoded, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode status json: %w", err)
+	}
+	_, err = fmt.Fprintln(a.Stdout, string(encoded))
+	return err
+}
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"bb-project/internal/domain"
+	"bb-project/internal/state"
+)
+
+func TestRunStatusAllMachinesShowsMatrixWithWinner(t *testing.T) {
+	home := t.TempDir()
+	paths := state.NewPaths(home)
+	now := time.Date(2026, 2, 15, 12, 0, 0, 0, time.UTC)
+	t.Setenv("BB_MACHINE_ID", "machine-a")
+
+	if err := state.SaveConfig(paths, state.DefaultConfig()); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	local := state.BootstrapMachine("machine-a", "laptop", now)
+	local.Catalogs = []domain.Catalog{
+		{Name: "software", Root: filepath.Join(home, "software")},
+		{Name: "references", Root: filepath.Join(home, "references")},
+	}
+	local.Repos = []domain.MachineRepoRecord{
+		{RepoKey: "software/api", Catalog: "software", Branch: "main", HeadSHA: "1111111aaaa", Behind: 2, Syncable: true, ObservedAt: now.Add(-time.Hour)},
+		{RepoKey: "references/docs", Catalog: "references", Branch: "main", HeadSHA: "3333333", Syncable: true, ObservedAt: now},
+	}
+	if err := state.SaveMachine(paths, local); err != nil {
+		t.Fatalf("save machine: %v", err)
+	}
+	remote := state.BootstrapMachine("machine-b", "desktop", now)
+	remote.Repos = []domain.MachineRepoRecord{
+		{RepoKey: "software/api", Catalog: "software", Branch: "main", HeadSHA: "2222222bbbb", Syncable: true, ObservedAt: now},
+		{RepoKey: "software/web", Catalog: "software", Branch: "dev", HeadSHA: "4444444", Syncable: false, UnsyncableReasons: []domain.UnsyncableReason{domain.ReasonDirtyTracked}, ObservedAt: now},
+	}
+	if err := state.SaveMachine(paths, remote); err != nil {
+		t.Fatalf("save machine: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	a := New(paths, &stdout, &bytes.Buffer{})
+	a.Now = func() time.Time { return now }
+	a.Hostname = func() (string, error) { return "laptop", nil }
+
+	code, err := a.RunStatus(StatusOptions{AllMachines: true, IncludeCatalogs: []string{"software"}})
+	if err != nil {
+		t.Fatalf("RunStatus failed: %v", err)
+	}
+	if code != 0 {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected header, 2 rows and legend, got:\n%s", stdout.String())
+	}
+	if fields := strings.Fields(lines[0]); !reflect.DeepEqual(fields, []string{"REPO", "desktop", "laptop"}) {
+		t.Fatalf("header = %v, want machines ordered by hostname", fields)
+	}
+	if !strings.Contains(lines[1], "software/api") || !strings.Contains(lines[1], "* main 2222222 +0/-0 ok") || !strings.Contains(lines[1], "  main 1111111 +0/-2 ok") {
+		t.Fatalf("unexpected api row: %q", lines[1])
+	}
+	if !strings.Contains(lines[2], "software/web") || !strings.Contains(lines[2], "dev 4444444 +0/-0 unsyncable") || !strings.HasSuffix(lines[2], "-") {
+		t.Fatalf("unexpected web row: %q", lines[2])
+	}
+	if strings.Contains(stdout.String(), "references/docs") {
+		t.Fatalf("expected catalog filter to drop references/docs:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if _, err := a.RunStatus(StatusOptions{AllMachines: true, JSON: true}); err != nil {
+		t.Fatalf("RunStatus json failed: %v", err)
+	}
+	var report statusFleetJSONReport
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("decode fleet json: %v\n%s", err, stdout.String())
+	}
+	if report.SchemaVersion != statusFleetJSONSchemaVersion || report.LocalMachineID != "machine-a" {
+		t.Fatalf("unexpected header: %#v", report)
+	}
+	if len(report.Machines) != 2 || report.Machines[0].MachineID != "machine-b" {
+		t.Fatalf("unexpected machines: %#v", report.Machines)
+	}
+	if len(report.Repos) != 3 {
+		t.Fatalf("repos = %d, want 3", len(report.Repos))
+	}
+	api := report.Repos[1]
+	if api.RepoKey != "software/api" || api.WinnerMachineID != "machine-b" || len(api.Machines) != 2 {
+		t.Fatalf("unexpected api row: %#v", api)
+	}
+	if !api.Machines[0].Winner || api.Machines[1].Winner || api.Machines[1].Behind != 2 {
+		t.Fatalf("unexpected api cells: %#v", api.Machines)
+	}
+	web := report.Repos[2]
+	if web.WinnerMachineID != "" || len(web.Machines) != 1 || web.Machines[0].UnsyncableReasons[0] != domain.ReasonDirtyTracked {
+		t.Fatalf("unexpected web row: %#v", web)
+	}
+}
+
+func TestStatusFleetJSONSchemaMatchesStructs(t *testing.T) {
+	t.Parallel()
+
+	assertJSONSchemaMatchesStructs(t, "bb-status-fleet.v1.schema.json", map[string]reflect.Type{
+		"":        reflect.TypeOf(statusFleetJSONReport{}),
+		"machine": reflect.TypeOf(statusFleetJSONMachine{}),
+		"repo":    reflect.TypeOf(statusFleetJSONRepo{}),
+		"cell":    reflect.TypeOf(statusFleetJSONCell{}),
+	})
+}
@@ -0,0 +1,186 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"bb-project/internal/domain"
+	"bb-project/internal/state"
+)
+
+// statusJSONSchemaVersion is bumped whenever a field is removed or changes
+// meaning in `bb status --json`. Adding fields keeps the version. The matching
+// JSON Schema lives in docs/schema/bb-status.v1.schema.json.
+const statusJSONSchemaVersion = 1
+
+type statusJSONReport struct {
+	SchemaVersion    int              `json:"schema_version"`
+	MachineID        string           `json:"machine_id"`
+	Hostname         string           `json:"hostname"`
+	LastScanAt       *time.Time       `json:"last_scan_at"`
+	LastScanCatalogs []string         `json:"last_scan_catalogs"`
+	UpdatedAt        *time.Time       `json:"updated_at"`
+	Repos            []statusJSONRepo `json:"repos"`
+}
+
+type statusJSONRepo struct {
+	RepoKey             string                    `json:"repo_key"`
+	Name                string                    `json:"name"`
+	Catalog             string                    `json:"catalog"`
+	Path                string                    `json:"path"`
+	OriginURL           string                    `json:"origin_url"`
+	Branch              string                    `json:"branch"`
+	HeadSHA             string                    `json:"head_sha"`
+	Upstream            string                    `json:"upstream"`
+	RemoteHeadSHA       string                    `json:"remote_head_sha"`
+	Ahead               int                       `json:"ahead"`
+	Behind              int                       `json:"behind"`
+	Diverged            bool                      `json:"diverged"`
+	HasDirtyTracked     bool                      `json:"has_dirty_tracked"`
+	HasUntracked        bool                      `json:"has_untracked"`
+	OperationInProgress domain.Operation          `json:"operation_in_progress"`
+	Syncable            bool                      `json:"syncable"`
+	UnsyncableReasons   []domain.UnsyncableReason `json:"unsyncable_reasons"`
+	StateHash           string                    `json:"state_hash"`
+	ObservedAt          *time.Time                `json:"observed_at"`
+	ExpectedRepoKey     string                    `json:"expected_repo_key"`
+	ExpectedCatalog     string                    `json:"expected_catalog"`
+	ExpectedPath        string                    `json:"expected_path"`
+	Worktrees           []statusJSONWorktree      `json:"worktrees"`
+	Metadata            *statusJSONRepoMetadata   `json:"metadata,omitempty"`
+}
+
+type statusJSONWorktree struct {
+	Path                string           `json:"path"`
+	Branch              string           `json:"branch"`
+	HeadSHA             string           `json:"head_sha"`
+	HasDirtyTracked     bool             `json:"has_dirty_tracked"`
+	HasUntracked        bool             `json:"has_untracked"`
+	OperationInProgress domain.Operation `json:"operation_in_progress"`
+}
+
+type statusJSONRepoMetadata struct {
+	Visibility               domain.Visibility   `json:"visibility"`
+	AutoPush                 domain.AutoPushMode `json:"auto_push"`
+	PushAccess               domain.PushAccess   `json:"push_access"`
+	PushAccessManualOverride bool                `json:"push_access_manual_override"`
+	PreferredCatalog         string              `json:"preferred_catalog"`
+	PreferredRemote          string              `json:"preferred_remote"`
+	BranchFollowEnabled      bool                `json:"branch_follow_enabled"`
+	PinnedBranch             string              `json:"pinned_branch"`
+	PrimaryMachineID         string              `json:"primary_machine_id"`
+	PreviousRepoKeys         []string            `json:"previous_repo_keys"`
+}
+
+func (a *App) writeStatusJSON(machine domain.MachineFile, repos []domain.MachineRepoRecord, withMetadata bool) error {
+	var metas map[string]domain.RepoMetadataFile
+	if withMetadata {
+		all, err := state.LoadAllRepoMetadata(a.Paths)
+		if err != nil {
+			return err
+		}
+		metas = make(map[string]domain.RepoMetadataFile, len(all))
+		for _, meta := range all {
+			metas[meta.RepoKey] = meta
+		}
+	}
+
+	report := statusJSONReport{
+		SchemaVersion:    statusJSONSchemaVersion,
+		MachineID:        machine.MachineID,
+		Hostname:         machine.Hostname,
+		LastScanAt:       optionalTime(machine.LastScanAt),
+		LastScanCatalogs: nonNilStrings(machine.LastScanCatalogs),
+		UpdatedAt:        optionalTime(machine.UpdatedAt),
+		Repos:            make([]statusJSONRepo, 0, len(repos)),
+	}
+	for _, rec := range repos {
+		repo := statusJSONRepoFromRecord(rec)
+		if meta, ok := metas[rec.RepoKey]; ok && rec.RepoKey != "" {
+			repo.Metadata = statusJSONRepoMetadataFrom(meta)
+		}
+		report.Repos = append(report.Repos, repo)
+	}
+
+	encoded, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode status json: %w", err)
+	}
+	_, err = fmt.Fprintln(a.Stdout, string(encoded))
+	return err
+}
+
+func statusJSONRepoFromRecord(rec domain.MachineRepoRecord) statusJSONRepo {
+	reasons := rec.UnsyncableReasons
+	if reasons == nil {
+		reasons = []domain.UnsyncableReason{}
+	}
+	worktrees := make([]statusJSONWorktree, 0, len(rec.Worktrees))
+	for _, wt := range rec.Worktrees {
+		worktrees = append(worktrees, statusJSONWorktree{
+			Path:                wt.Path,
+			Branch:              wt.Branch,
+			HeadSHA:             wt.HeadSHA,
+			HasDirtyTracked:     wt.HasDirtyTracked,
+			HasUntracked:        wt.HasUntracked,
+			OperationInProgress: wt.OperationInProgress,
+		})
+	}
+	return statusJSONRepo{
+		RepoKey:             rec.RepoKey,
+		Name:                rec.Name,
+		Catalog:             rec.Catalog,
+		Path:                rec.Path,
+		OriginURL:           rec.OriginURL,
+		Branch:              rec.Branch,
+		HeadSHA:             rec.HeadSHA,
+		Upstream:            rec.Upstream,
+		RemoteHeadSHA:       rec.RemoteHeadSHA,
+		Ahead:               rec.Ahead,
+		Behind:              rec.Behind,
+		Diverged:            rec.Diverged,
+		HasDirtyTracked:     rec.HasDirtyTracked,
+		HasUntracked:        rec.HasUntracked,
+		OperationInProgress: rec.OperationInProgress,
+		Syncable:            rec.Syncable,
+		UnsyncableReasons:   reasons,
+		StateHash:           rec.StateHash,
+		ObservedAt:          optionalTime(rec.ObservedAt),
+		ExpectedRepoKey:     rec.ExpectedRepoKey,
+		ExpectedCatalog:     rec.ExpectedCatalog,
+		ExpectedPath:        rec.ExpectedPath,
+		Worktrees:           worktrees,
+	}
+}
+
+func statusJSONRepoMetadataFrom(meta domain.RepoMetadataFile) *statusJSONRepoMetadata {
+	return &statusJSONRepoMetadata{
+		Visibility:               meta.Visibility,
+		AutoPush:                 meta.AutoPush,
+		PushAccess:               domain.NormalizePushAccess(meta.PushAccess),
+		PushAccessManualOverride: meta.PushAccessManualOverride,
+		PreferredCatalog:         meta.PreferredCatalog,
+		PreferredRemote:          meta.PreferredRemote,
+		BranchFollowEnabled:      meta.BranchFollowEnabled,
+		PinnedBranch:             meta.PinnedBranch,
+		PrimaryMachineID:         meta.PrimaryMachineID,
+		PreviousRepoKeys:         nonNilStrings(meta.PreviousRepoKeys),
+	}
+}
+
+// optionalTime maps the zero time to JSON null instead of year 0001.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,201 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"bb-project/internal/domain"
+	"bb-project/internal/state"
+)
+
+func TestRunStatusJSONEmitsFullRecords(t *testing.T) {
+	home := t.TempDir()
+	paths := state.NewPaths(home)
+	now := time.Date(2026, 2, 15, 12, 0, 0, 0, time.UTC)
+	t.Setenv("BB_MACHINE_ID", "machine-a")
+
+	if err := state.SaveConfig(paths, state.DefaultConfig()); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	machine := state.BootstrapMachine("machine-a", "host-a", now)
+	machine.Catalogs = []domain.Catalog{
+		{Name: "software", Root: filepath.Join(home, "software")},
+		{Name: "references", Root: filepath.Join(home, "references")},
+	}
+	machine.Repos = []domain.MachineRepoRecord{
+		{
+			RepoKey:             "software/api",
+			Name:                "api",
+			Catalog:             "software",
+			Path:                filepath.Join(home, "software", "api"),
+			OriginURL:           "git@github.com:you/api.git",
+			Branch:              "main",
+			HeadSHA:             "abc123",
+			Upstream:            "origin/main",
+			Ahead:               2,
+			Behind:              1,
+			Diverged:            true,
+			HasDirtyTracked:     true,
+			OperationInProgress: domain.OperationRebase,
+			UnsyncableReasons:   []domain.UnsyncableReason{domain.ReasonDiverged},
+			ObservedAt:          now,
+			ExpectedCatalog:     "references",
+		},
+		{RepoKey: "references/docs", Name: "docs", Catalog: "references", Syncable: true},
+	}
+	if err := state.SaveMachine(paths, machine); err != nil {
+		t.Fatalf("save machine: %v", err)
+	}
+	if err := state.SaveRepoMetadata(paths, domain.RepoMetadataFile{
+		Version:    1,
+		RepoKey:    "software/api",
+		Name:       "api",
+		Visibility: domain.VisibilityPrivate,
+		AutoPush:   domain.AutoPushModeEnabled,
+		PushAccess: domain.PushAccessReadWrite,
+	}); err != nil {
+		t.Fatalf("save repo metadata: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	a := New(paths, &stdout, &bytes.Buffer{})
+	a.Now = func() time.Time { return now }
+	a.Hostname = func() (string, error) { return "host-a", nil }
+
+	code, err := a.RunStatus(StatusOptions{JSON: true, WithMetadata: true, IncludeCatalogs: []string{"software"}})
+	if err != nil {
+		t.Fatalf("RunStatus failed: %v", err)
+	}
+	if code != 0 {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+
+	var report statusJSONReport
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("decode status json: %v\n%s", err, stdout.String())
+	}
+	if report.SchemaVersion != statusJSONSchemaVersion || report.MachineID != "machine-a" {
+		t.Fatalf("unexpected report header: %#v", report)
+	}
+	if len(report.Repos) != 1 {
+		t.Fatalf("repos = %d, want 1 (catalog filter)", len(report.Repos))
+	}
+	got := report.Repos[0]
+	if got.Ahead != 2 || got.Behind != 1 || !got.Diverged || !got.HasDirtyTracked {
+		t.Fatalf("unexpected counters/flags: %#v", got)
+	}
+	if got.OperationInProgress != domain.OperationRebase || got.ExpectedCatalog != "references" || got.OriginURL == "" {
+		t.Fatalf("unexpected fields: %#v", got)
+	}
+	if got.ObservedAt == nil || !got.ObservedAt.Equal(now) {
+		t.Fatalf("observed_at = %v, want %v", got.ObservedAt, now)
+	}
+	if got.Metadata == nil || got.Metadata.Visibility != domain.VisibilityPrivate || got.Metadata.AutoPush != domain.AutoPushModeEnabled {
+		t.Fatalf("unexpected metadata: %#v", got.Metadata)
+	}
+}
+
+func TestRunStatusJSONOmitsMetadataByDefault(t *testing.T) {
+	home := t.TempDir()
+	paths := state.NewPaths(home)
+	now := time.Date(2026, 2, 15, 12, 0, 0, 0, time.UTC)
+	t.Setenv("BB_MACHINE_ID", "machine-a")
+
+	if err := state.SaveConfig(paths, state.DefaultConfig()); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	machine := state.BootstrapMachine("machine-a", "host-a", now)
+	machine.Catalogs = []domain.Catalog{{Name: "software", Root: filepath.Join(home, "software")}}
+	machine.Repos = []domain.MachineRepoRecord{{RepoKey: "software/api", Name: "api", Catalog: "software"}}
+	if err := state.SaveMachine(paths, machine); err != nil {
+		t.Fatalf("save machine: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	a := New(paths, &stdout, &bytes.Buffer{})
+	a.Now = func() time.Time { return now }
+	a.Hostname = func() (string, error) { return "host-a", nil }
+	if _, err := a.RunStatus(StatusOptions{JSON: true}); err != nil {
+		t.Fatalf("RunStatus failed: %v", err)
+	}
+
+	out := stdout.String()
+	if strings.Contains(out, `"metadata"`) {
+		t.Fatalf("did not expect metadata without --metadata:\n%s", out)
+	}
+	if !strings.Contains(out, `"unsyncable_reasons": []`) {
+		t.Fatalf("expected empty reasons array:\n%s", out)
+	}
+	if !strings.Contains(out, `"observed_at": null`) {
+		t.Fatalf("expected null observed_at for zero time:\n%s", out)
+	}
+}
+
+func TestStatusJSONSchemaMatchesStructs(t *testing.T) {
+	t.Parallel()
+
+	assertJSONSchemaMatchesStructs(t, "bb-status.v1.schema.json", map[string]reflect.Type{
+		"":              reflect.TypeOf(statusJSONReport{}),
+		"repo":          reflect.TypeOf(statusJSONRepo{}),
+		"repo_metadata": reflect.TypeOf(statusJSONRepoMetadata{}),
+		"worktree":      reflect.TypeOf(statusJSONWorktree{}),
+	})
+}
+
+// assertJSONSchemaMatchesStructs keeps a docs/schema file in sync with the
+// encoder structs: every emitted key must be declared, and the schema's
+// required list must match the keys that are always emitted. The "" entry is
+// the root object; other entries name $defs.
+func assertJSONSchemaMatchesStructs(t *testing.T, file string, types map[string]reflect.Type) {
+	t.Helper()
+
+	raw, err := os.ReadFile(filepath.Join("..", "..", "docs", "schema", file))
+	if err != nil {
+		t.Fatalf("read schema: %v", err)
+	}
+	type object struct {
+		Required   []string       `json:"required"`
+		Properties map[string]any `json:"properties"`
+	}
+	var schema struct {
+		object
+		Defs map[string]object `json:"$defs"`
+	}
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		t.Fatalf("decode schema: %v", err)
+	}
+
+	for name, typ := range types {
+		obj := schema.object
+		if name != "" {
+			def, ok := schema.Defs[name]
+			if !ok {
+				t.Fatalf("%s: missing $defs/%s", file, name)
+			}
+			obj = def
+		}
+		emitted := []string{}
+		for i := 0; i < typ.NumField(); i++ {
+			tag := strings.Split(typ.Field(i).Tag.Get("json"), ",")
+			if _, ok := obj.Properties[tag[0]]; !ok {
+				t.Fatalf("%s %q: field %q missing from schema properties", file, name, tag[0])
+			}
+			if len(tag) == 1 {
+				emitted = append(emitted, tag[0])
+			}
+		}
+		sort.Strings(emitted)
+		want := append([]string(nil), obj.Required...)
+		sort.Strings(want)
+		if !reflect.DeepEqual(emitted, want) {
+			t.Fatalf("%s %q: schema required = %v, encoder always emits %v", file, name, want, emitted)
+		}
+	}
+}
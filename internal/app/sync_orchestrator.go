@@ -20,11 +20,20 @@ func (a *App) runSync(opts SyncOptions) (int, error) {
 		a.logf("sync: released global lock")
 	}()
 
+	if err := a.resolveStateConflictCopiesLocked(); err != nil {
+		return 2, err
+	}
 	cfg, machine, err := a.loadContext()
 	if err != nil {
 		return 2, err
 	}
 	a.logf("sync: start push=%t notify=%t dry-run=%t", opts.Push, opts.Notify, opts.DryRun)
+	if err := a.pullStateTransport(cfg, machine.MachineID); err != nil {
+		return 2, err
+	}
+	if err := state.SnapshotStateFiles(a.Paths); err != nil {
+		a.logf("warning: failed to back up state files: %v", err)
+	}
 
 	selectedCatalogs, selectedCatalogMap, err := selectSyncCatalogs(a.Paths, machine, opts.IncludeCatalogs)
 	if err != nil {
@@ -39,28 +48,50 @@ func (a *App) runSync(opts SyncOptions) (int, error) {
 	}
 	machine.Repos = localRecords
 	machine.UpdatedAt = a.Now()
-	if err := persistMachineRecords(a.Paths, &machine, previous, a.Now); err != nil {
-		return 2, err
+	if !opts.DryRun {
+		if err := persistMachineRecords(a.Paths, &machine, previous, a.Now); err != nil {
+			return 2, err
+		}
+		a.logf("sync: published local observations")
 	}
-	a.logf("sync: published local observations")
 
 	machines, repoMetas, err := loadSyncReconcileInputs(a.Paths)
 	if err != nil {
 		return 2, err
 	}
-	if err := a.ensureFromWinners(cfg, &machine, machines, repoMetas, selectedCatalogMap, transitionedToSyncable, opts); err != nil {
+	if opts.DryRun {
+		// Dry runs leave the machine file alone, so winners are picked
+		// against this run's observations rather than the published ones.
+		machines = replaceMachineFile(machines, machine)
+	}
+	plans, err := a.ensureFromWinners(cfg, &machine, machines, repoMetas, selectedCatalogMap, transitionedToSyncable, opts)
+	if err != nil {
 		return 2, err
 	}
 	a.logf("sync: winner reconciliation completed")
+	if opts.DryRun {
+		if opts.JSON {
+			if err := a.writeSyncPlanJSON(machine.MachineID, plans, true); err != nil {
+				return 2, err
+			}
+		} else {
+			writeSyncPlanTable(a.Stdout, plans)
+		}
+	}
 
-	if err := persistMachineRecords(a.Paths, &machine, previous, a.Now); err != nil {
-		return 2, err
+	if !opts.DryRun {
+		if err := persistMachineRecords(a.Paths, &machine, previous, a.Now); err != nil {
+			return 2, err
+		}
+		a.logf("sync: published post-reconciliation observations")
+		if err := a.pushStateTransport(cfg, machine.MachineID); err != nil {
+			return 2, err
+		}
 	}
-	a.logf("sync: published post-reconciliation observations")
 
 	if opts.Notify {
 		a.logf("sync: processing notifications")
-		if err := a.notifyUnsyncable(cfg, machine.Repos, opts.NotifyBackend); err != nil {
+		if err := a.notifyUnsyncable(cfg, machine.MachineID, machine.Repos, transitionedToSyncable, opts.NotifyBackend); err != nil {
 			return 2, err
 		}
 	}
@@ -157,6 +188,18 @@ func loadSyncReconcileInputs(paths state.Paths) ([]domain.MachineFile, []domain.
 	return machines, repoMetas, nil
 }
 
+// replaceMachineFile returns machines with the file for machine.MachineID
+// swapped for machine, adding it when it has not been published yet.
+func replaceMachineFile(machines []domain.MachineFile, machine domain.MachineFile) []domain.MachineFile {
+	out := make([]domain.MachineFile, 0, len(machines)+1)
+	for _, m := range machines {
+		if m.MachineID != machine.MachineID {
+			out = append(out, m)
+		}
+	}
+	return append(out, machine)
+}
+
 func anyUnsyncableInSelectedCatalogs(repos []domain.MachineRepoRecord, selectedCatalogMap map[string]domain.Catalog) bool {
 	for _, rec := range repos {
 		if _, ok := selectedCatalogMap[rec.Catalog]; !ok {
@@ -9,22 +9,20 @@ import (
 	"bb-project/internal/state"
 )
 
-func (a *App) notifyUnsyncable(cfg domain.ConfigFile, repos []domain.MachineRepoRecord, backendOverride string) error {
+// notifyRoute is one configured backend plus its reason filters.
+type notifyRoute struct {
+	backend        string
+	sender         notifySender
+	reasons        map[domain.UnsyncableReason]struct{}
+	excludeReasons map[domain.UnsyncableReason]struct{}
+}
+
+func (a *App) notifyUnsyncable(cfg domain.ConfigFile, machineID string, repos []domain.MachineRepoRecord, transitionedToSyncable map[string]bool, backendOverride string) error {
 	if !cfg.Notify.Enabled {
 		a.logf("notify: disabled in config")
 		return nil
 	}
-	backendName, err := a.resolveNotifyBackend(backendOverride)
-	if err != nil {
-		return err
-	}
-	factory := a.NewNotifySender
-	if factory == nil {
-		factory = func(name string) (notifySender, error) {
-			return newNotifySender(name, a.Stdout, a.RunCommand)
-		}
-	}
-	sender, err := factory(backendName)
+	routes, err := a.resolveNotifyRoutes(cfg, backendOverride)
 	if err != nil {
 		return err
 	}
@@ -37,48 +35,238 @@ func (a *App) notifyUnsyncable(cfg domain.ConfigFile, repos []domain.MachineRepo
 	throttleWindow := time.Duration(cfg.Notify.ThrottleMinutes) * time.Minute
 	for _, rec := range repos {
 		if rec.Syncable {
+			if transitionedToSyncable[repoRecordIdentityKey(rec)] {
+				a.notifyRecovered(cfg, machineID, rec, routes, &cache)
+			}
 			continue
 		}
 		if len(rec.UnsyncableReasons) > 0 && !domain.HasBlockingUnsyncableReason(rec.UnsyncableReasons) {
 			continue
 		}
-		fingerprint := unsyncableFingerprint(rec.UnsyncableReasons)
-		cacheKey := notifyCacheKey(rec)
+		// Entries written before per-backend keys existed seed every backend's
+		// dedupe state once, then get replaced by the backend-scoped keys.
+		legacyKey := notifyCacheKey(rec)
+		legacyEntry, hasLegacy := cache.LastSent[legacyKey]
+		for _, route := range routes {
+			reasons, ok := route.filterReasons(rec.UnsyncableReasons)
+			if !ok {
+				a.logf("notify: backend %s filtered %s", route.backend, rec.Name)
+				continue
+			}
+			fingerprint := unsyncableFingerprint(reasons)
+			cacheKey := notifyLastSentCacheKey(route.backend, rec)
+			entry, ok := cache.LastSent[cacheKey]
+			if !ok && hasLegacy {
+				entry, ok = legacyEntry, true
+			}
+			if ok && entry.Fingerprint == fingerprint && cfg.Notify.Dedupe {
+				a.logf("notify: backend %s deduped %s (%s)", route.backend, rec.Name, fingerprint)
+				cache.LastSent[cacheKey] = entry
+				continue
+			}
+			if ok && throttleWindow > 0 {
+				elapsed := now.Sub(entry.SentAt)
+				if elapsed >= 0 && elapsed < throttleWindow {
+					a.logf("notify: backend %s throttled %s (%s), remaining=%s", route.backend, rec.Name, fingerprint, throttleWindow-elapsed)
+					cache.LastSent[cacheKey] = entry
+					continue
+				}
+			}
+			msg := notifyMessage{
+				MachineID:   machineID,
+				Repo:        rec,
+				Fingerprint: fingerprint,
+			}
+			if err := route.sender.Send(msg); err != nil {
+				failureKey := notifyFailureCacheKey(route.backend, rec)
+				a.logf("notify: backend %s failed for %s: %v", route.backend, rec.Name, err)
+				cache.DeliveryFailures[failureKey] = domain.NotifyDeliveryFailure{
+					Backend:     route.backend,
+					RepoKey:     strings.TrimSpace(rec.RepoKey),
+					RepoName:    strings.TrimSpace(rec.Name),
+					RepoPath:    strings.TrimSpace(rec.Path),
+					Fingerprint: fingerprint,
+					Error:       err.Error(),
+					FailedAt:    now,
+				}
+				continue
+			}
+			a.logf("notify: backend %s emitted for %s (%s)", route.backend, rec.Name, fingerprint)
+			cache.LastSent[cacheKey] = domain.NotifyCacheEntry{Fingerprint: fingerprint, SentAt: now}
+			delete(cache.DeliveryFailures, notifyFailureCacheKey(route.backend, rec))
+		}
+		if hasLegacy {
+			delete(cache.LastSent, legacyKey)
+		}
+	}
+	return state.SaveNotifyCache(a.Paths, cache)
+}
+
+// notifyRecovered prunes dedupe/throttle and failure state for a repo that
+// became syncable again, so a later regression notifies afresh. With
+// notify.recovered enabled, each backend that had notified the repo also gets a
+// resolution message. Delivery is best effort: failures are logged only.
+func (a *App) notifyRecovered(cfg domain.ConfigFile, machineID string, rec domain.MachineRepoRecord, routes []notifyRoute, cache *domain.NotifyCacheFile) {
+	legacyKey := notifyCacheKey(rec)
+	legacyEntry, hasLegacy := cache.LastSent[legacyKey]
+	delete(cache.LastSent, legacyKey)
+	for _, route := range routes {
+		cacheKey := notifyLastSentCacheKey(route.backend, rec)
 		entry, ok := cache.LastSent[cacheKey]
-		if ok && entry.Fingerprint == fingerprint && cfg.Notify.Dedupe {
-			a.logf("notify: deduped %s (%s)", rec.Name, fingerprint)
+		if !ok && hasLegacy {
+			entry, ok = legacyEntry, true
+		}
+		delete(cache.LastSent, cacheKey)
+		delete(cache.DeliveryFailures, notifyFailureCacheKey(route.backend, rec))
+		if !ok {
 			continue
 		}
-		if ok && throttleWindow > 0 {
-			elapsed := now.Sub(entry.SentAt)
-			if elapsed >= 0 && elapsed < throttleWindow {
-				a.logf("notify: throttled %s (%s), remaining=%s", rec.Name, fingerprint, throttleWindow-elapsed)
-				continue
-			}
+		if !cfg.Notify.Recovered {
+			a.logf("notify: backend %s cleared %s (recovered)", route.backend, rec.Name)
+			continue
 		}
 		msg := notifyMessage{
+			MachineID:   machineID,
 			Repo:        rec,
-			Fingerprint: fingerprint,
-		}
-		if err := sender.Send(msg); err != nil {
-			failureKey := notifyFailureCacheKey(backendName, rec)
-			a.logf("notify: backend %s failed for %s: %v", backendName, rec.Name, err)
-			cache.DeliveryFailures[failureKey] = domain.NotifyDeliveryFailure{
-				Backend:     backendName,
-				RepoKey:     strings.TrimSpace(rec.RepoKey),
-				RepoName:    strings.TrimSpace(rec.Name),
-				RepoPath:    strings.TrimSpace(rec.Path),
-				Fingerprint: fingerprint,
-				Error:       err.Error(),
-				FailedAt:    now,
-			}
+			Fingerprint: entry.Fingerprint,
+			Recovered:   true,
+		}
+		if err := route.sender.Send(msg); err != nil {
+			a.logf("notify: backend %s failed recovered notification for %s: %v", route.backend, rec.Name, err)
 			continue
 		}
-		a.logf("notify: backend %s emitted for %s (%s)", backendName, rec.Name, fingerprint)
-		cache.LastSent[cacheKey] = domain.NotifyCacheEntry{Fingerprint: fingerprint, SentAt: now}
-		delete(cache.DeliveryFailures, notifyFailureCacheKey(backendName, rec))
+		a.logf("notify: backend %s emitted recovered for %s (%s)", route.backend, rec.Name, entry.Fingerprint)
 	}
-	return state.SaveNotifyCache(a.Paths, cache)
+	// Backends dropped from the config get no message, but their entries
+	// would still dedupe the repo if they were configured again.
+	pruneNotifyCacheEntries(cache, rec)
+}
+
+// pruneNotifyCacheEntries drops every backend's dedupe/throttle and failure
+// entries for rec and reports whether any existed.
+func pruneNotifyCacheEntries(cache *domain.NotifyCacheFile, rec domain.MachineRepoRecord) bool {
+	legacyKey := notifyCacheKey(rec)
+	_, pruned := cache.LastSent[legacyKey]
+	delete(cache.LastSent, legacyKey)
+	suffix := "|" + legacyKey
+	for key := range cache.LastSent {
+		if strings.HasSuffix(key, suffix) {
+			delete(cache.LastSent, key)
+			pruned = true
+		}
+	}
+	for key := range cache.DeliveryFailures {
+		if strings.HasSuffix(key, suffix) {
+			delete(cache.DeliveryFailures, key)
+			pruned = true
+		}
+	}
+	return pruned
+}
+
+// pruneNotifyCacheForRecovered clears the notify cache entries of repos a scan
+// saw turn syncable. Only sync sends notifications, so without this a scan
+// that records the recovery first would leave the entry deduping the next
+// regression. No recovered message is sent for such repos.
+func (a *App) pruneNotifyCacheForRecovered(repos []domain.MachineRepoRecord, transitionedToSyncable map[string]bool) {
+	if len(transitionedToSyncable) == 0 {
+		return
+	}
+	cache, err := state.LoadNotifyCache(a.Paths)
+	if err != nil {
+		a.logf("warning: failed to load notify cache: %v", err)
+		return
+	}
+	changed := false
+	for _, rec := range repos {
+		if transitionedToSyncable[repoRecordIdentityKey(rec)] && pruneNotifyCacheEntries(&cache, rec) {
+			a.logf("notify: cleared %s (recovered during scan)", rec.Name)
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+	if err := state.SaveNotifyCache(a.Paths, cache); err != nil {
+		a.logf("warning: failed to write notify cache: %v", err)
+	}
+}
+
+// resolveNotifyRoutes builds the backends for this run. An explicit override
+// (flag or BB_NOTIFY_BACKEND) selects a single unfiltered backend; otherwise
+// notify.backends is used, falling back to stdout.
+func (a *App) resolveNotifyRoutes(cfg domain.ConfigFile, backendOverride string) ([]notifyRoute, error) {
+	factory := a.NewNotifySender
+	if factory == nil {
+		factory = func(name string, notifyCfg domain.NotifyConfig) (notifySender, error) {
+			return newNotifySender(name, notifyCfg, a.Stdout, a.RunCommand)
+		}
+	}
+
+	configs := cfg.Notify.Backends
+	if a.notifyBackendOverridden(backendOverride) || len(configs) == 0 {
+		backendName, err := a.resolveNotifyBackend(backendOverride)
+		if err != nil {
+			return nil, err
+		}
+		configs = []domain.NotifyBackendConfig{{Name: backendName}}
+	}
+
+	routes := make([]notifyRoute, 0, len(configs))
+	for _, backendCfg := range configs {
+		backendName, err := normalizeNotifyBackend(backendCfg.Name)
+		if err != nil {
+			return nil, err
+		}
+		sender, err := factory(backendName, cfg.Notify)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, notifyRoute{
+			backend:        backendName,
+			sender:         sender,
+			reasons:        unsyncableReasonSet(backendCfg.Reasons),
+			excludeReasons: unsyncableReasonSet(backendCfg.ExcludeReasons),
+		})
+	}
+	return routes, nil
+}
+
+// filterReasons returns the reasons this route should see for a record and
+// whether the record should reach the backend at all.
+func (r notifyRoute) filterReasons(reasons []domain.UnsyncableReason) ([]domain.UnsyncableReason, bool) {
+	if len(r.reasons) == 0 && len(r.excludeReasons) == 0 {
+		return reasons, true
+	}
+	filtered := make([]domain.UnsyncableReason, 0, len(reasons))
+	matched := len(r.reasons) == 0
+	for _, reason := range reasons {
+		if _, excluded := r.excludeReasons[reason]; excluded {
+			continue
+		}
+		if _, included := r.reasons[reason]; included {
+			matched = true
+		}
+		filtered = append(filtered, reason)
+	}
+	if !matched {
+		return nil, false
+	}
+	if len(reasons) > 0 && len(filtered) == 0 {
+		return nil, false
+	}
+	return filtered, true
+}
+
+func unsyncableReasonSet(reasons []domain.UnsyncableReason) map[domain.UnsyncableReason]struct{} {
+	if len(reasons) == 0 {
+		return nil
+	}
+	set := make(map[domain.UnsyncableReason]struct{}, len(reasons))
+	for _, reason := range reasons {
+		set[reason] = struct{}{}
+	}
+	return set
 }
 
 func notifyCacheKey(rec domain.MachineRepoRecord) string {
@@ -1,14 +1,43 @@
 package app
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"bb-project/internal/domain"
 	"bb-project/internal/gitx"
 )
 
+// reconcileJob is the git work planned for one repo during winner
+// reconciliation. Jobs touch disjoint repo paths, so they run concurrently;
+// their outcomes are applied to the machine file in plan order.
+type reconcileJob struct {
+	meta          domain.RepoMetadataFile
+	winner        domain.MachineRepoRecordWithMachine
+	winnerReason  string
+	targetCatalog domain.Catalog
+	targetPath    string
+	repoName      string
+	// localIdx is the index of the existing local record in machine.Repos,
+	// or -1 when the local copy has to be ensured at targetPath.
+	localIdx int
+	local    domain.MachineRepoRecord
+}
+
+// reconcileOutcome is what a reconcileJob changes in the machine file.
+type reconcileOutcome struct {
+	logs []string
+	plan syncRepoPlan
+	// record replaces machine.Repos[localIdx], or is appended for new copies.
+	record    *domain.MachineRepoRecord
+	synthetic domain.UnsyncableReason
+	err       error
+}
+
 func (a *App) ensureFromWinners(
 	cfg domain.ConfigFile,
 	machine *domain.MachineFile,
@@ -17,13 +46,18 @@ func (a *App) ensureFromWinners(
 	selectedCatalogMap map[string]domain.Catalog,
 	transitionedToSyncable map[string]bool,
 	opts SyncOptions,
-) error {
+) ([]syncRepoPlan, error) {
 	a.logf("sync: reconciling %d repo metadata entries", len(repoMetas))
 	moveIndex, err := buildRepoMoveIndex(repoMetas)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	// plans holds one entry per repo in metadata order; jobs fill theirs in
+	// once they ran.
+	var plans []syncRepoPlan
+	jobPlanIdx := []int{}
 	warnedUnmappedCatalogs := map[string]bool{}
+	jobs := make([]reconcileJob, 0, len(repoMetas))
 	for _, meta := range repoMetas {
 		if _, historical := moveIndex[strings.TrimSpace(meta.RepoKey)]; historical {
 			continue
@@ -46,6 +80,7 @@ func (a *App) ensureFromWinners(
 					)
 					warnedUnmappedCatalogs[keyCatalog] = true
 				}
+				plans = append(plans, skippedSyncRepoPlan(meta.RepoKey, fmt.Sprintf("catalog %s is not mapped on this machine", keyCatalog)))
 			}
 			staleMatches := findLocalMatchesByRepoKeys(machine.Repos, meta.PreviousRepoKeys, selectedCatalogMap)
 			for _, idx := range staleMatches {
@@ -73,6 +108,7 @@ func (a *App) ensureFromWinners(
 					false,
 				)
 			}
+			plans = append(plans, skippedSyncRepoPlan(meta.RepoKey, fmt.Sprintf("repo moved to %s; run bb fix to move the local copy", targetPath)))
 			continue
 		}
 		if len(matches) == 0 && len(staleMatches) == 0 && len(meta.PreviousRepoKeys) > 0 {
@@ -80,129 +116,286 @@ func (a *App) ensureFromWinners(
 			// treat it as a no-op and avoid synthesizing clone_required.
 			continue
 		}
+		if len(matches) == 0 && catalogExcludes(targetCatalog, keyRelativePath) {
+			plans = append(plans, skippedSyncRepoPlan(meta.RepoKey, "excluded by catalog exclude rules"))
+			continue
+		}
 
-		winner, ok := selectWinnerForRepo(allMachines, meta.RepoKey)
+		policy := a.repoWinnerPolicy(*machine, meta)
+		selection, ok := selectWinnerForRepo(allMachines, meta.RepoKey, policy)
 		if !ok {
 			a.logf("sync: no syncable winner for %s", meta.RepoKey)
+			plans = append(plans, skippedSyncRepoPlan(meta.RepoKey, "no machine has a syncable copy"))
 			continue
 		}
-		if winner.MachineID == machine.MachineID && len(matches) == 1 {
-			if remoteWinner, ok := selectWinnerForRepoExcluding(allMachines, meta.RepoKey, machine.MachineID); ok {
+		if primary := strings.TrimSpace(meta.PrimaryMachineID); primary != "" && selection.Reason != domain.WinnerReasonPrimaryMachine {
+			a.logf("sync: primary machine %s has no syncable record for %s; selecting by history", primary, meta.RepoKey)
+		}
+		winner, winnerReason := selection.Winner, describeWinner(selection)
+		if winner.MachineID == machine.MachineID && len(matches) == 1 && selection.Reason != domain.WinnerReasonPrimaryMachine {
+			if remote, ok := selectWinnerForRepoExcluding(allMachines, meta.RepoKey, machine.MachineID, policy); ok {
 				key := repoRecordIdentityKey(machine.Repos[matches[0]])
-				if transitionedToSyncable[key] && machine.Repos[matches[0]].Branch != remoteWinner.Record.Branch {
-					winner = remoteWinner
+				if transitionedToSyncable[key] && machine.Repos[matches[0]].Branch != remote.Winner.Record.Branch {
+					winner = remote.Winner
+					winnerReason = "local copy just became syncable on another branch; following remote winner (" + describeWinner(remote) + ")"
 				}
 			}
 		}
 
-		a.logf("sync: repo %s winner=%s branch=%s target=%s", meta.RepoKey, winner.MachineID, winner.Record.Branch, targetPath)
+		job := reconcileJob{
+			meta:          meta,
+			winner:        winner,
+			winnerReason:  winnerReason,
+			targetCatalog: targetCatalog,
+			targetPath:    targetPath,
+			repoName:      keyRepoName,
+			localIdx:      -1,
+		}
+		if len(matches) == 1 {
+			job.localIdx = matches[0]
+			job.local = machine.Repos[matches[0]]
+		}
+		jobs = append(jobs, job)
+		jobPlanIdx = append(jobPlanIdx, len(plans))
+		plans = append(plans, syncRepoPlan{})
+	}
 
-		pathConflictReason, err := validateTargetPath(a.Git, targetPath, meta.OriginURL, meta.PreferredRemote)
-		if err != nil {
-			return err
+	outcomes := a.runReconcileJobs(cfg, jobs, selectedCatalogMap, opts)
+	for i, outcome := range outcomes {
+		for _, line := range outcome.logs {
+			a.logf("%s", line)
 		}
-		if pathConflictReason != "" {
-			a.logf("sync: path conflict at %s: %s", targetPath, pathConflictReason)
-			a.addOrUpdateSyntheticUnsyncable(machine, meta, targetCatalog.Name, targetPath, keyRepoName, pathConflictReason)
-			continue
+		if outcome.err != nil {
+			return nil, outcome.err
+		}
+		plans[jobPlanIdx[i]] = outcome.plan
+		job := jobs[i]
+		switch {
+		case outcome.synthetic != "":
+			a.addOrUpdateSyntheticUnsyncable(machine, job.meta, job.targetCatalog.Name, job.targetPath, job.repoName, outcome.synthetic)
+		case outcome.record == nil:
+		case job.localIdx >= 0:
+			machine.Repos[job.localIdx] = *outcome.record
+		default:
+			machine.Repos = append(machine.Repos, *outcome.record)
 		}
+	}
 
-		if len(matches) == 1 {
-			idx := matches[0]
-			local := machine.Repos[idx]
-			if !local.Syncable {
-				continue
-			}
-			if opts.DryRun {
-				continue
-			}
+	return plans, nil
+}
 
-			if local.Branch != winner.Record.Branch {
-				a.logf("sync: checking out branch %s in %s", winner.Record.Branch, local.Path)
-				if err := a.Git.CheckoutWithPreferredRemote(local.Path, winner.Record.Branch, meta.PreferredRemote); err != nil {
-					machine.Repos[idx].Syncable = false
-					machine.Repos[idx].UnsyncableReasons = appendUniqueReasons(machine.Repos[idx].UnsyncableReasons, domain.ReasonCheckoutFailed)
-					machine.Repos[idx].StateHash = domain.ComputeStateHash(machine.Repos[idx])
-					continue
-				}
+// runReconcileJobs runs jobs on a bounded worker pool and returns their
+// outcomes indexed like jobs.
+func (a *App) runReconcileJobs(
+	cfg domain.ConfigFile,
+	jobs []reconcileJob,
+	selectedCatalogMap map[string]domain.Catalog,
+	opts SyncOptions,
+) []reconcileOutcome {
+	outcomes := make([]reconcileOutcome, len(jobs))
+	workerCount := scanWorkerCount(len(jobs))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < workerCount; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range next {
+				outcomes[idx] = a.reconcileRepo(cfg, jobs[idx], selectedCatalogMap, opts)
 			}
+		}()
+	}
+	for idx := range jobs {
+		next <- idx
+	}
+	close(next)
+	wg.Wait()
+	return outcomes
+}
 
-			if cfg.Sync.FetchPrune {
-				a.logf("sync: fetch --prune %s before pull", local.Path)
-				_ = a.Git.FetchPrune(local.Path)
-			}
-			a.logf("sync: pull --ff-only %s", local.Path)
-			if err := a.Git.PullFFOnly(local.Path); err != nil {
-				machine.Repos[idx].Syncable = false
-				machine.Repos[idx].UnsyncableReasons = appendUniqueReasons(machine.Repos[idx].UnsyncableReasons, domain.ReasonPullFailed)
-				machine.Repos[idx].StateHash = domain.ComputeStateHash(machine.Repos[idx])
-				continue
-			}
+// reconcileRepo plans how to bring one repo in line with its winner and, for
+// real runs, executes that plan. It must not touch the machine file;
+// everything it changes is returned in the outcome.
+func (a *App) reconcileRepo(
+	cfg domain.ConfigFile,
+	job reconcileJob,
+	selectedCatalogMap map[string]domain.Catalog,
+	opts SyncOptions,
+) (outcome reconcileOutcome) {
+	logf := func(format string, args ...any) {
+		outcome.logs = append(outcome.logs, fmt.Sprintf(format, args...))
+	}
+	meta, winner := job.meta, job.winner
+
+	logf(
+		"sync: repo %s winner=%s branch=%s target=%s (%s)",
+		meta.RepoKey,
+		winner.MachineID,
+		winner.Record.Branch,
+		job.targetPath,
+		job.winnerReason,
+	)
+
+	plan, err := a.planReconcileRepo(cfg, job)
+	outcome.plan = plan
+	if err != nil {
+		outcome.err = err
+		return outcome
+	}
+	if plan.Skip != "" {
+		logf("sync: skipping %s: %s", meta.RepoKey, plan.Skip)
+		if !opts.DryRun {
+			outcome.synthetic = plan.UnsyncableReason
+		}
+		return outcome
+	}
+	if opts.DryRun {
+		return outcome
+	}
 
-			catalog := domain.Catalog{Name: local.Catalog}
-			if selected, ok := selectedCatalogMap[local.Catalog]; ok {
-				catalog = selected
+	local := job.local
+	fail := func(reason domain.UnsyncableReason) reconcileOutcome {
+		if !plan.LocalCopy {
+			outcome.synthetic = reason
+			return outcome
+		}
+		local.Syncable = false
+		local.UnsyncableReasons = appendUniqueReasons(local.UnsyncableReasons, reason)
+		local.StateHash = domain.ComputeStateHash(local)
+		outcome.record = &local
+		return outcome
+	}
+	for _, step := range plan.Steps {
+		logf("sync: %s in %s", step.Command, step.Path)
+		if reason, err := a.runSyncStep(step); err != nil {
+			if reason == "" {
+				outcome.err = err
+				return outcome
 			}
-			updated, err := a.observeRepo(cfg, discoveredRepo{
-				Catalog: catalog,
-				Path:    local.Path,
-				Name:    local.Name,
-				RepoKey: meta.RepoKey,
-			}, opts.Push)
-			if err != nil {
-				return err
+			return fail(reason)
+		}
+		if step.Action == syncStepClone {
+			if reason := a.verifyClonedRepo(meta, step.Path); reason != "" {
+				return fail(reason)
 			}
-			machine.Repos[idx] = updated
-			continue
 		}
+	}
 
-		if opts.DryRun {
-			continue
-		}
-		a.logf("sync: ensuring local copy at %s", targetPath)
-		if err := a.ensureLocalCopy(
-			cfg,
-			machine,
-			meta,
-			winner,
-			targetCatalog,
-			targetPath,
-			keyRepoName,
-			opts,
-			targetCatalog.AllowsAutoCloneOnSync(),
-		); err != nil {
-			return err
+	repo := discoveredRepo{
+		Catalog: job.targetCatalog,
+		Path:    plan.TargetPath,
+		Name:    job.repoName,
+		RepoKey: meta.RepoKey,
+	}
+	if plan.LocalCopy {
+		repo.Catalog = domain.Catalog{Name: local.Catalog}
+		if selected, ok := selectedCatalogMap[local.Catalog]; ok {
+			repo.Catalog = selected
 		}
+		repo.Name = local.Name
 	}
+	updated, err := a.observeRepoLogged(cfg, repo, opts.Push, logf)
+	if err != nil {
+		outcome.err = err
+		return outcome
+	}
+	outcome.record = &updated
+	return outcome
+}
 
-	return nil
+// selectWinnerForRepo picks the winner among every machine's record of
+// repoKey under policy; see domain.SelectWinnerWithPolicy.
+func selectWinnerForRepo(all []domain.MachineFile, repoKey string, policy domain.WinnerPolicy) (domain.WinnerSelection, bool) {
+	return selectWinnerForRepoExcluding(all, repoKey, "", policy)
 }
 
-func selectWinnerForRepo(all []domain.MachineFile, repoKey string) (domain.MachineRepoRecordWithMachine, bool) {
+func selectWinnerForRepoExcluding(
+	all []domain.MachineFile,
+	repoKey string,
+	excludedMachineID string,
+	policy domain.WinnerPolicy,
+) (domain.WinnerSelection, bool) {
 	records := make([]domain.MachineRepoRecordWithMachine, 0)
 	for _, m := range all {
+		if excludedMachineID != "" && m.MachineID == excludedMachineID {
+			continue
+		}
 		for _, rec := range m.Repos {
 			if rec.RepoKey == repoKey {
 				records = append(records, domain.MachineRepoRecordWithMachine{MachineID: m.MachineID, Record: rec})
 			}
 		}
 	}
-	return domain.SelectWinner(records)
+	return domain.SelectWinnerWithPolicy(records, policy)
 }
 
-func selectWinnerForRepoExcluding(all []domain.MachineFile, repoKey string, excludedMachineID string) (domain.MachineRepoRecordWithMachine, bool) {
-	records := make([]domain.MachineRepoRecordWithMachine, 0)
-	for _, m := range all {
-		if m.MachineID == excludedMachineID {
-			continue
-		}
-		for _, rec := range m.Repos {
-			if rec.RepoKey == repoKey {
-				records = append(records, domain.MachineRepoRecordWithMachine{MachineID: m.MachineID, Record: rec})
-			}
+// repoWinnerPolicy returns the winner policy of meta as seen from machine.
+func (a *App) repoWinnerPolicy(machine domain.MachineFile, meta domain.RepoMetadataFile) domain.WinnerPolicy {
+	return domain.WinnerPolicy{
+		PrimaryMachineID: meta.PrimaryMachineID,
+		IsAncestor:       a.localAncestry(machine, meta.RepoKey),
+	}
+}
+
+// reconcileBranch returns the branch a local copy should be on: the winner's
+// branch, or the pinned branch when branch following is disabled. Without a
+// pin the copy keeps currentBranch; new copies start on the winner's branch.
+func reconcileBranch(meta domain.RepoMetadataFile, winner domain.MachineRepoRecordWithMachine, currentBranch string) string {
+	if meta.BranchFollowEnabled {
+		return winner.Record.Branch
+	}
+	if pinned := strings.TrimSpace(meta.PinnedBranch); pinned != "" {
+		return pinned
+	}
+	if currentBranch != "" {
+		return currentBranch
+	}
+	return winner.Record.Branch
+}
+
+// localAncestry answers ancestry questions from the objects of machine's
+// local clone of repoKey. It returns nil when the machine has no clone, which
+// leaves winner selection to timestamps.
+func (a *App) localAncestry(machine domain.MachineFile, repoKey string) domain.AncestryFunc {
+	path := ""
+	for _, rec := range machine.Repos {
+		if rec.RepoKey == repoKey && strings.TrimSpace(rec.Path) != "" {
+			path = rec.Path
+			break
 		}
 	}
-	return domain.SelectWinner(records)
+	if path == "" {
+		return nil
+	}
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		return nil
+	}
+	return func(ancestor, descendant string) (bool, bool) {
+		ok, err := a.Git.IsAncestor(path, ancestor, descendant)
+		return ok, err == nil
+	}
+}
+
+// describeWinner explains a winner selection for reconcile logs.
+func describeWinner(selection domain.WinnerSelection) string {
+	winner := selection.Winner
+	var why string
+	switch selection.Reason {
+	case domain.WinnerReasonOnlyCandidate:
+		why = "only syncable record"
+	case domain.WinnerReasonPrimaryMachine:
+		why = "primary machine policy"
+	case domain.WinnerReasonDescendant:
+		why = fmt.Sprintf("head %s descends from more recently observed heads", shortSHA(winner.Record.HeadSHA))
+	case domain.WinnerReasonMachineIDTieBreak:
+		why = fmt.Sprintf("observed_at tie at %s broken by machine id", winner.Record.ObservedAt.UTC().Format(time.RFC3339))
+	default:
+		why = fmt.Sprintf("newest observation at %s", winner.Record.ObservedAt.UTC().Format(time.RFC3339))
+	}
+	if len(selection.Superseded) > 0 {
+		why += fmt.Sprintf("; superseded ancestors on %s", strings.Join(selection.Superseded, ", "))
+	}
+	return why
 }
 
 func findLocalMatches(records []domain.MachineRepoRecord, repoKey string, selected map[string]domain.Catalog) []int {
@@ -283,93 +476,6 @@ func validateTargetPath(g gitx.Runner, targetPath string, expectedOriginURL stri
 	return "", nil
 }
 
-func (a *App) ensureLocalCopy(
-	cfg domain.ConfigFile,
-	machine *domain.MachineFile,
-	meta domain.RepoMetadataFile,
-	winner domain.MachineRepoRecordWithMachine,
-	targetCatalog domain.Catalog,
-	targetPath string,
-	repoName string,
-	opts SyncOptions,
-	allowClone bool,
-) error {
-	if info, err := os.Stat(targetPath); os.IsNotExist(err) {
-		if !allowClone {
-			a.addOrUpdateSyntheticUnsyncable(machine, meta, targetCatalog.Name, targetPath, repoName, domain.ReasonCloneRequired)
-			return nil
-		}
-		a.logf("sync: cloning %s into %s", winner.Record.OriginURL, targetPath)
-		if err := a.Git.Clone(winner.Record.OriginURL, targetPath); err != nil {
-			a.addOrUpdateSyntheticUnsyncable(machine, meta, targetCatalog.Name, targetPath, repoName, domain.ReasonCheckoutFailed)
-			return nil
-		}
-		if err := a.Git.EnsureBranchWithPreferredRemote(targetPath, winner.Record.Branch, meta.PreferredRemote); err != nil {
-			a.addOrUpdateSyntheticUnsyncable(machine, meta, targetCatalog.Name, targetPath, repoName, domain.ReasonCheckoutFailed)
-			return nil
-		}
-	} else if err != nil {
-		return err
-	} else if info.IsDir() {
-		entries, err := os.ReadDir(targetPath)
-		if err != nil {
-			return err
-		}
-		if len(entries) == 0 {
-			if !allowClone {
-				a.addOrUpdateSyntheticUnsyncable(machine, meta, targetCatalog.Name, targetPath, repoName, domain.ReasonCloneRequired)
-				return nil
-			}
-			a.logf("sync: cloning into empty directory %s", targetPath)
-			if err := a.Git.Clone(winner.Record.OriginURL, targetPath); err != nil {
-				a.addOrUpdateSyntheticUnsyncable(machine, meta, targetCatalog.Name, targetPath, repoName, domain.ReasonCheckoutFailed)
-				return nil
-			}
-			if err := a.Git.EnsureBranchWithPreferredRemote(targetPath, winner.Record.Branch, meta.PreferredRemote); err != nil {
-				a.addOrUpdateSyntheticUnsyncable(machine, meta, targetCatalog.Name, targetPath, repoName, domain.ReasonCheckoutFailed)
-				return nil
-			}
-		}
-	}
-
-	if !a.Git.IsGitRepo(targetPath) {
-		a.addOrUpdateSyntheticUnsyncable(machine, meta, targetCatalog.Name, targetPath, repoName, domain.ReasonTargetPathNonRepo)
-		return nil
-	}
-	origin, _ := a.Git.RepoOriginWithPreferredRemote(targetPath, meta.PreferredRemote)
-	matches, _ := originsMatchNormalized(origin, meta.OriginURL)
-	if !matches {
-		a.addOrUpdateSyntheticUnsyncable(machine, meta, targetCatalog.Name, targetPath, repoName, domain.ReasonTargetPathRepoMismatch)
-		return nil
-	}
-
-	if err := a.Git.EnsureBranchWithPreferredRemote(targetPath, winner.Record.Branch, meta.PreferredRemote); err != nil {
-		a.addOrUpdateSyntheticUnsyncable(machine, meta, targetCatalog.Name, targetPath, repoName, domain.ReasonCheckoutFailed)
-		return nil
-	}
-	if cfg.Sync.FetchPrune {
-		a.logf("sync: fetch --prune %s", targetPath)
-		_ = a.Git.FetchPrune(targetPath)
-	}
-	a.logf("sync: pull --ff-only %s", targetPath)
-	if err := a.Git.PullFFOnly(targetPath); err != nil {
-		a.addOrUpdateSyntheticUnsyncable(machine, meta, targetCatalog.Name, targetPath, repoName, domain.ReasonPullFailed)
-		return nil
-	}
-
-	rec, err := a.observeRepo(cfg, discoveredRepo{
-		Catalog: targetCatalog,
-		Path:    targetPath,
-		Name:    repoName,
-		RepoKey: meta.RepoKey,
-	}, opts.Push)
-	if err != nil {
-		return err
-	}
-	machine.Repos = append(machine.Repos, rec)
-	return nil
-}
-
 func (a *App) addOrUpdateSyntheticUnsyncable(machine *domain.MachineFile, meta domain.RepoMetadataFile, catalog, targetPath string, repoName string, reason domain.UnsyncableReason) {
 	for i := range machine.Repos {
 		if machine.Repos[i].RepoKey == meta.RepoKey && machine.Repos[i].Path == targetPath {
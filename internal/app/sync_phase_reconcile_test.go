@@ -1,9 +1,13 @@
 package app
 
 import (
+	"bytes"
+	"fmt"
 	"io"
 	"path/filepath"
+	"reflect"
 	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -66,7 +70,7 @@ func TestEnsureFromWinnersMarksCatalogMismatchForPreviousRepoKey(t *testing.T) {
 		"references": machine.Catalogs[1],
 	}
 
-	err := app.ensureFromWinners(
+	_, err := app.ensureFromWinners(
 		domain.ConfigFile{},
 		&machine,
 		allMachines,
@@ -134,7 +138,7 @@ func TestEnsureFromWinnersSkipsCloneRequiredForMovedRepoWhenNeverCloned(t *testi
 
 	selectedCatalogMap := map[string]domain.Catalog{"references": machine.Catalogs[0]}
 
-	err := app.ensureFromWinners(
+	_, err := app.ensureFromWinners(
 		domain.ConfigFile{},
 		&machine,
 		allMachines,
@@ -193,7 +197,7 @@ func TestEnsureFromWinnersMarksCatalogNotMappedWhenMoveTargetCatalogMissing(t *t
 		}},
 	}}
 
-	err := app.ensureFromWinners(
+	_, err := app.ensureFromWinners(
 		domain.ConfigFile{},
 		&machine,
 		allMachines,
@@ -220,3 +224,255 @@ func TestEnsureFromWinnersMarksCatalogNotMappedWhenMoveTargetCatalogMissing(t *t
 		t.Fatalf("expected_catalog = %q, want references", rec.ExpectedCatalog)
 	}
 }
+
+func TestEnsureFromWinnersAppliesConcurrentOutcomesInMetadataOrder(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, time.February, 17, 11, 15, 0, 0, time.UTC)
+	home := t.TempDir()
+	paths := state.NewPaths(home)
+	var stderr bytes.Buffer
+	app := New(paths, io.Discard, &stderr)
+	app.SetVerbose(true)
+	app.Now = func() time.Time { return now }
+
+	softwareRoot := filepath.Join(home, "software")
+	machine := state.BootstrapMachine("local", "local", now)
+	machine.DefaultCatalog = "software"
+	machine.Catalogs = []domain.Catalog{{Name: "software", Root: softwareRoot, RepoPathDepth: 1}}
+
+	remote := domain.MachineFile{MachineID: "remote"}
+	var metas []domain.RepoMetadataFile
+	var wantKeys []string
+	for i := 0; i < 40; i++ {
+		name := fmt.Sprintf("repo-%02d", i)
+		repoKey := "software/" + name
+		origin := "https://github.com/you/" + name + ".git"
+		metas = append(metas, domain.RepoMetadataFile{RepoKey: repoKey, Name: name, OriginURL: origin})
+		remote.Repos = append(remote.Repos, domain.MachineRepoRecord{
+			RepoKey:   repoKey,
+			Name:      name,
+			Catalog:   "software",
+			Path:      "/remote/software/" + name,
+			OriginURL: origin,
+			Branch:    "main",
+			Syncable:  true,
+		})
+		wantKeys = append(wantKeys, repoKey)
+	}
+
+	_, err := app.ensureFromWinners(
+		domain.ConfigFile{},
+		&machine,
+		[]domain.MachineFile{remote},
+		metas,
+		map[string]domain.Catalog{"software": machine.Catalogs[0]},
+		nil,
+		SyncOptions{},
+	)
+	if err != nil {
+		t.Fatalf("ensureFromWinners error: %v", err)
+	}
+
+	var gotKeys []string
+	for _, rec := range machine.Repos {
+		gotKeys = append(gotKeys, rec.RepoKey)
+		if !slices.Equal(rec.UnsyncableReasons, []domain.UnsyncableReason{domain.ReasonCloneRequired}) {
+			t.Fatalf("%s reasons = %v, want clone_required", rec.RepoKey, rec.UnsyncableReasons)
+		}
+	}
+	if !slices.Equal(gotKeys, wantKeys) {
+		t.Fatalf("machine repos = %v, want metadata order %v", gotKeys, wantKeys)
+	}
+
+	var winnerLines []string
+	for _, line := range strings.Split(stderr.String(), "\n") {
+		if strings.HasPrefix(line, "bb: sync: repo ") {
+			winnerLines = append(winnerLines, strings.Fields(line)[3])
+		}
+	}
+	if !slices.Equal(winnerLines, wantKeys) {
+		t.Fatalf("winner log order = %v, want %v", winnerLines, wantKeys)
+	}
+}
+
+func TestEnsureFromWinnersHonorsPrimaryMachine(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, time.February, 17, 11, 15, 0, 0, time.UTC)
+	home := t.TempDir()
+	paths := state.NewPaths(home)
+	var stderr bytes.Buffer
+	app := New(paths, io.Discard, &stderr)
+	app.SetVerbose(true)
+	app.Now = func() time.Time { return now }
+
+	machine := state.BootstrapMachine("local", "local", now)
+	machine.DefaultCatalog = "software"
+	machine.Catalogs = []domain.Catalog{{Name: "software", Root: filepath.Join(home, "software"), RepoPathDepth: 1}}
+
+	origin := "https://github.com/you/api.git"
+	record := func(branch string, observedAt time.Time) domain.MachineRepoRecord {
+		return domain.MachineRepoRecord{
+			RepoKey:    "software/api",
+			Name:       "api",
+			Catalog:    "software",
+			Path:       "/remote/software/api",
+			OriginURL:  origin,
+			Branch:     branch,
+			Syncable:   true,
+			ObservedAt: observedAt,
+		}
+	}
+	laptop := domain.MachineFile{MachineID: "laptop", Repos: []domain.MachineRepoRecord{record("feature", now)}}
+	desktop := domain.MachineFile{MachineID: "desktop", Repos: []domain.MachineRepoRecord{record("main", now.Add(-time.Hour))}}
+	meta := domain.RepoMetadataFile{RepoKey: "software/api", Name: "api", OriginURL: origin, PrimaryMachineID: "desktop"}
+
+	_, err := app.ensureFromWinners(
+		domain.ConfigFile{},
+		&machine,
+		[]domain.MachineFile{laptop, desktop},
+		[]domain.RepoMetadataFile{meta},
+		map[string]domain.Catalog{"software": machine.Catalogs[0]},
+		nil,
+		SyncOptions{},
+	)
+	if err != nil {
+		t.Fatalf("ensureFromWinners error: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "winner=desktop branch=main") || !strings.Contains(stderr.String(), "primary machine policy") {
+		t.Fatalf("expected primary machine to win, logs:\n%s", stderr.String())
+	}
+}
+
+func TestReconcileBranch(t *testing.T) {
+	t.Parallel()
+
+	winner := domain.MachineRepoRecordWithMachine{MachineID: "m1", Record: domain.MachineRepoRecord{Branch: "feature"}}
+	tests := []struct {
+		name    string
+		meta    domain.RepoMetadataFile
+		current string
+		want    string
+	}{
+		{name: "follow winner", meta: domain.RepoMetadataFile{BranchFollowEnabled: true}, current: "main", want: "feature"},
+		{name: "pinned", meta: domain.RepoMetadataFile{PinnedBranch: "main"}, current: "feature", want: "main"},
+		{name: "follow disabled keeps current", meta: domain.RepoMetadataFile{}, current: "topic", want: "topic"},
+		{name: "follow disabled new copy", meta: domain.RepoMetadataFile{}, want: "feature"},
+	}
+	for _, tt := range tests {
+		if got := reconcileBranch(tt.meta, winner, tt.current); got != tt.want {
+			t.Fatalf("%s: reconcileBranch() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEnsureFromWinnersDryRunPlansTheStepsARealRunExecutes(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, time.February, 17, 11, 15, 0, 0, time.UTC)
+	home := t.TempDir()
+	paths := state.NewPaths(home)
+	var stderr bytes.Buffer
+	app := New(paths, io.Discard, &stderr)
+	app.SetVerbose(true)
+	app.Now = func() time.Time { return now }
+
+	remotePath := setupCloneTestRemote(t, filepath.Join(home, "remotes"), "you", "api")
+	workPath := filepath.Join(home, "remotes", "you", "api-work")
+	for _, args := range [][]string{{"checkout", "-b", "feature"}, {"push", "-u", "origin", "feature"}} {
+		if _, err := app.Git.RunGit(workPath, args...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+	softwareRoot := filepath.Join(home, "software")
+	localPath := filepath.Join(softwareRoot, "api")
+	if _, err := app.Git.RunGit(home, "clone", remotePath, localPath); err != nil {
+		t.Fatalf("clone local copy: %v", err)
+	}
+
+	machine := state.BootstrapMachine("local", "local", now)
+	machine.DefaultCatalog = "software"
+	machine.Catalogs = []domain.Catalog{{Name: "software", Root: softwareRoot, RepoPathDepth: 1}}
+	machine.Repos = []domain.MachineRepoRecord{{
+		RepoKey:    "software/api",
+		Name:       "api",
+		Catalog:    "software",
+		Path:       localPath,
+		OriginURL:  remotePath,
+		Branch:     "main",
+		Syncable:   true,
+		ObservedAt: now.Add(-time.Hour),
+	}}
+	laptop := domain.MachineFile{MachineID: "laptop", Repos: []domain.MachineRepoRecord{
+		{RepoKey: "software/api", Name: "api", Catalog: "software", OriginURL: remotePath, Branch: "feature", Syncable: true, ObservedAt: now},
+		{RepoKey: "software/web", Name: "web", Catalog: "software", OriginURL: "https://github.com/you/web.git", Branch: "main", Syncable: true, ObservedAt: now},
+	}}
+	metas := []domain.RepoMetadataFile{
+		{RepoKey: "software/api", Name: "api", OriginURL: remotePath, BranchFollowEnabled: true},
+		{RepoKey: "software/web", Name: "web", OriginURL: "https://github.com/you/web.git", BranchFollowEnabled: true},
+	}
+	reconcile := func(opts SyncOptions) []syncRepoPlan {
+		t.Helper()
+		plans, err := app.ensureFromWinners(
+			domain.ConfigFile{},
+			&machine,
+			[]domain.MachineFile{machine, laptop},
+			metas,
+			map[string]domain.Catalog{"software": machine.Catalogs[0]},
+			nil,
+			opts,
+		)
+		if err != nil {
+			t.Fatalf("ensureFromWinners error: %v", err)
+		}
+		return plans
+	}
+
+	plans := reconcile(SyncOptions{DryRun: true})
+	if len(plans) != 2 {
+		t.Fatalf("plans = %+v, want api and web", plans)
+	}
+	var actions []syncStepAction
+	for _, step := range plans[0].Steps {
+		actions = append(actions, step.Action)
+	}
+	if !slices.Equal(actions, []syncStepAction{syncStepCheckout, syncStepPullFFOnly}) || plans[0].Steps[0].Branch != "feature" {
+		t.Fatalf("api plan = %+v, want checkout feature then pull", plans[0])
+	}
+	if plans[1].Skip == "" || plans[1].UnsyncableReason != domain.ReasonCloneRequired {
+		t.Fatalf("web plan = %+v, want clone_required skip", plans[1])
+	}
+	if branch, _ := app.Git.CurrentBranch(localPath); branch != "main" {
+		t.Fatalf("dry run switched branch to %q", branch)
+	}
+
+	var table bytes.Buffer
+	writeSyncPlanTable(&table, plans)
+	for _, want := range []string{"software/api  laptop/feature  checkout", "pull_ff_only", "software/web  laptop/main     skip", "dry run: 2 step(s) across 1 repo(s), skipped=1"} {
+		if !strings.Contains(table.String(), want) {
+			t.Fatalf("plan table missing %q:\n%s", want, table.String())
+		}
+	}
+
+	realPlans := reconcile(SyncOptions{})
+	if !reflect.DeepEqual(realPlans[0], plans[0]) {
+		t.Fatalf("real run api plan = %+v, want dry-run plan %+v", realPlans[0], plans[0])
+	}
+	if branch, _ := app.Git.CurrentBranch(localPath); branch != "feature" {
+		t.Fatalf("branch after sync = %q, want feature", branch)
+	}
+	if machine.Repos[0].Branch != "feature" || !machine.Repos[0].Syncable {
+		t.Fatalf("api record = %+v, want syncable on feature", machine.Repos[0])
+	}
+}
+
+func TestSyncPlanJSONSchemaMatchesStructs(t *testing.T) {
+	t.Parallel()
+
+	assertJSONSchemaMatchesStructs(t, "bb-sync-plan.v1.schema.json", map[string]reflect.Type{
+		"":     reflect.TypeOf(syncPlanJSONReport{}),
+		"repo": reflect.TypeOf(syncRepoPlan{}),
+		"step": reflect.TypeOf(syncStep{}),
+	})
+}
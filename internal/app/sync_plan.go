@@ -0,0 +1,268 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"bb-project/internal/domain"
+)
+
+// syncPlanJSONSchemaVersion versions `bb sync --dry-run --json`; see
+// docs/schema/bb-sync-plan.v1.schema.json.
+const syncPlanJSONSchemaVersion = 1
+
+type syncStepAction string
+
+const (
+	// syncStepCheckout switches an existing local copy to another branch.
+	syncStepCheckout syncStepAction = "checkout"
+	syncStepClone    syncStepAction = "clone"
+	// syncStepEnsureBranch checks out (creating and tracking if needed) the
+	// branch in a new or adopted local copy.
+	syncStepEnsureBranch syncStepAction = "ensure_branch"
+	syncStepFetchPrune   syncStepAction = "fetch_prune"
+	syncStepPullFFOnly   syncStepAction = "pull_ff_only"
+)
+
+// syncStep is one write-side git operation. Dry runs print steps; real runs
+// execute the same steps through runSyncStep.
+type syncStep struct {
+	Action  syncStepAction `json:"action"`
+	Path    string         `json:"path"`
+	Branch  string         `json:"branch,omitempty"`
+	Remote  string         `json:"remote,omitempty"`
+	URL     string         `json:"url,omitempty"`
+	Command string         `json:"command"`
+}
+
+// syncRepoPlan is what sync does for one repo. Skip is set when no step runs;
+// UnsyncableReason is then recorded on the machine file, if set, on real runs.
+type syncRepoPlan struct {
+	RepoKey          string                  `json:"repo_key"`
+	WinnerMachineID  string                  `json:"winner_machine_id,omitempty"`
+	WinnerBranch     string                  `json:"winner_branch,omitempty"`
+	WinnerReason     string                  `json:"winner_reason,omitempty"`
+	TargetPath       string                  `json:"target_path,omitempty"`
+	LocalCopy        bool                    `json:"local_copy"`
+	Steps            []syncStep              `json:"steps"`
+	Skip             string                  `json:"skip,omitempty"`
+	UnsyncableReason domain.UnsyncableReason `json:"unsyncable_reason,omitempty"`
+}
+
+type syncPlanJSONReport struct {
+	SchemaVersion int            `json:"schema_version"`
+	MachineID     string         `json:"machine_id"`
+	DryRun        bool           `json:"dry_run"`
+	Repos         []syncRepoPlan `json:"repos"`
+}
+
+func skippedSyncRepoPlan(repoKey string, skip string) syncRepoPlan {
+	return syncRepoPlan{RepoKey: repoKey, Steps: []syncStep{}, Skip: skip}
+}
+
+func newSyncStep(action syncStepAction, path string, branch string, remote string, url string) syncStep {
+	step := syncStep{Action: action, Path: path, Branch: branch, Remote: remote, URL: url}
+	switch action {
+	case syncStepCheckout, syncStepEnsureBranch:
+		step.Command = "git checkout " + branch
+	case syncStepClone:
+		step.Command = fmt.Sprintf("git clone %s %s", url, path)
+	case syncStepFetchPrune:
+		step.Command = "git fetch --prune"
+	case syncStepPullFFOnly:
+		step.Command = "git pull --ff-only"
+	}
+	return step
+}
+
+// planReconcileRepo decides, without writing anything, which steps bring the
+// local copy of job's repo in line with its winner.
+func (a *App) planReconcileRepo(cfg domain.ConfigFile, job reconcileJob) (syncRepoPlan, error) {
+	meta, winner, targetPath := job.meta, job.winner, job.targetPath
+	plan := syncRepoPlan{
+		RepoKey:         meta.RepoKey,
+		WinnerMachineID: winner.MachineID,
+		WinnerBranch:    winner.Record.Branch,
+		WinnerReason:    job.winnerReason,
+		TargetPath:      targetPath,
+		LocalCopy:       job.localIdx >= 0,
+		Steps:           []syncStep{},
+	}
+
+	pathConflictReason, err := validateTargetPath(a.Git, targetPath, meta.OriginURL, meta.PreferredRemote)
+	if err != nil {
+		return plan, err
+	}
+	if pathConflictReason != "" {
+		plan.Skip = fmt.Sprintf("path conflict at %s", targetPath)
+		plan.UnsyncableReason = pathConflictReason
+		return plan, nil
+	}
+
+	path := targetPath
+	if plan.LocalCopy {
+		local := job.local
+		if !local.Syncable {
+			plan.Skip = "local copy is unsyncable: " + joinUnsyncableReasons(local.UnsyncableReasons)
+			return plan, nil
+		}
+		path = local.Path
+		plan.TargetPath = path
+		if branch := reconcileBranch(meta, winner, local.Branch); local.Branch != branch {
+			// git refuses to check out a branch another worktree has out.
+			for _, wt := range local.Worktrees {
+				if wt.Branch == branch {
+					plan.Skip = fmt.Sprintf("branch %s is checked out in worktree %s", branch, wt.Path)
+					plan.UnsyncableReason = domain.ReasonCheckoutFailed
+					return plan, nil
+				}
+			}
+			plan.Steps = append(plan.Steps, newSyncStep(syncStepCheckout, path, branch, meta.PreferredRemote, ""))
+		}
+	} else {
+		needsClone, err := targetNeedsClone(targetPath)
+		if err != nil {
+			return plan, err
+		}
+		if needsClone {
+			if !job.targetCatalog.AllowsAutoCloneOnSync() {
+				plan.Skip = fmt.Sprintf("clone required; auto_clone_on_sync is off for catalog %s", job.targetCatalog.Name)
+				plan.UnsyncableReason = domain.ReasonCloneRequired
+				return plan, nil
+			}
+			plan.Steps = append(plan.Steps, newSyncStep(syncStepClone, path, "", "", winner.Record.OriginURL))
+		}
+		plan.Steps = append(plan.Steps, newSyncStep(syncStepEnsureBranch, path, reconcileBranch(meta, winner, ""), meta.PreferredRemote, ""))
+	}
+	if cfg.Sync.FetchPrune {
+		plan.Steps = append(plan.Steps, newSyncStep(syncStepFetchPrune, path, "", "", ""))
+	}
+	plan.Steps = append(plan.Steps, newSyncStep(syncStepPullFFOnly, path, "", "", ""))
+	return plan, nil
+}
+
+// targetNeedsClone reports whether targetPath is missing or an empty
+// directory. Other non-repo paths are caught by validateTargetPath first.
+func targetNeedsClone(targetPath string) (bool, error) {
+	info, err := os.Stat(targetPath)
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !info.IsDir() {
+		return false, nil
+	}
+	entries, err := os.ReadDir(targetPath)
+	if err != nil {
+		return false, err
+	}
+	return len(entries) == 0, nil
+}
+
+// runSyncStep executes one plan step. It returns the unsyncable reason a
+// failure maps to; fetch failures are not fatal and map to none.
+func (a *App) runSyncStep(step syncStep) (domain.UnsyncableReason, error) {
+	var err error
+	reason := domain.ReasonCheckoutFailed
+	switch step.Action {
+	case syncStepCheckout:
+		err = a.Git.CheckoutWithPreferredRemote(step.Path, step.Branch, step.Remote)
+	case syncStepEnsureBranch:
+		err = a.Git.EnsureBranchWithPreferredRemote(step.Path, step.Branch, step.Remote)
+	case syncStepClone:
+		err = a.Git.Clone(step.URL, step.Path)
+	case syncStepFetchPrune:
+		_ = a.Git.FetchPrune(step.Path)
+		return "", nil
+	case syncStepPullFFOnly:
+		err = a.Git.PullFFOnly(step.Path)
+		reason = domain.ReasonPullFailed
+	default:
+		return "", fmt.Errorf("unknown sync step %q", step.Action)
+	}
+	if err != nil {
+		return reason, err
+	}
+	return "", nil
+}
+
+// verifyClonedRepo checks that a fresh clone is a repo of the expected origin.
+func (a *App) verifyClonedRepo(meta domain.RepoMetadataFile, path string) domain.UnsyncableReason {
+	if !a.Git.IsGitRepo(path) {
+		return domain.ReasonTargetPathNonRepo
+	}
+	origin, _ := a.Git.RepoOriginWithPreferredRemote(path, meta.PreferredRemote)
+	if matches, _ := originsMatchNormalized(origin, meta.OriginURL); !matches {
+		return domain.ReasonTargetPathRepoMismatch
+	}
+	return ""
+}
+
+// writeSyncPlanTable prints one row per plan step, or a single skip row.
+func writeSyncPlanTable(out anyWriter, plans []syncRepoPlan) {
+	table := [][]string{{"REPO", "WINNER", "STEP", "DETAIL"}}
+	steps, skipped := 0, 0
+	for _, plan := range plans {
+		winner := "-"
+		if plan.WinnerMachineID != "" {
+			winner = plan.WinnerMachineID + "/" + plan.WinnerBranch
+		}
+		if plan.Skip != "" {
+			skipped++
+			detail := plan.Skip
+			if plan.UnsyncableReason != "" {
+				detail += fmt.Sprintf(" (%s)", plan.UnsyncableReason)
+			}
+			table = append(table, []string{plan.RepoKey, winner, "skip", detail})
+			continue
+		}
+		for i, step := range plan.Steps {
+			repo := plan.RepoKey
+			if i > 0 {
+				repo, winner = "", ""
+			}
+			steps++
+			table = append(table, []string{repo, winner, string(step.Action), fmt.Sprintf("%s (%s)", step.Command, step.Path)})
+		}
+	}
+
+	widths := make([]int, len(table[0]))
+	for _, line := range table {
+		for i, cell := range line {
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+	for _, line := range table {
+		var b strings.Builder
+		for i, cell := range line {
+			b.WriteString(cell)
+			if i < len(line)-1 {
+				b.WriteString(strings.Repeat(" ", widths[i]-len(cell)+2))
+			}
+		}
+		fmt.Fprintln(out, strings.TrimRight(b.String(), " "))
+	}
+	fmt.Fprintf(out, "dry run: %d step(s) across %d repo(s), skipped=%d\n", steps, len(plans)-skipped, skipped)
+}
+
+func (a *App) writeSyncPlanJSON(machineID string, plans []syncRepoPlan, dryRun bool) error {
+	if plans == nil {
+		plans = []syncRepoPlan{}
+	}
+	report := syncPlanJSONReport{
+		SchemaVersion: syncPlanJSONSchemaVersion,
+		MachineID:     machineID,
+		DryRun:        dryRun,
+		Repos:         plans,
+	}
+	encoded, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode sync plan json: %w", err)
+	}
+	_, err = fmt.Fprintln(a.Stdout, string(encoded))
+	return err
+}
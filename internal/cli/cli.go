@@ -30,21 +30,25 @@ type appRunner interface {
 	RunFix(opts app.FixOptions) (int, error)
 	RunDiff(project string, args []string) (int, error)
 	RunOperate(project string, args []string) (int, error)
-	RunStatus(jsonOut bool, include []string) (int, error)
-	RunDoctor(include []string) (int, error)
+	RunStatus(opts app.StatusOptions) (int, error)
+	RunDoctor(opts app.DoctorOptions) (int, error)
 	RunEnsure(include []string) (int, error)
 	RunSchedulerInstall(opts app.SchedulerInstallOptions) (int, error)
-	RunSchedulerStatus() (int, error)
-	RunSchedulerRemove() (int, error)
+	RunSchedulerStatus(backend string) (int, error)
+	RunSchedulerRemove(backend string) (int, error)
 	RunRepoPolicy(repoSelector string, autoPushMode domain.AutoPushMode) (int, error)
 	RunRepoPreferredRemote(repoSelector string, preferredRemote string) (int, error)
+	RunRepoPrimary(opts app.RepoPrimaryOptions) (int, error)
+	RunRepoBranch(opts app.RepoBranchOptions) (int, error)
 	RunRepoPushAccessSet(repoSelector string, pushAccess string) (int, error)
 	RunRepoPushAccessRefresh(repoSelector string) (int, error)
 	RunRepoMove(opts app.RepoMoveOptions) (int, error)
-	RunCatalogAdd(name, root string) (int, error)
+	RunCatalogAdd(name, root, layout string) (int, error)
 	RunCatalogRM(name string) (int, error)
 	RunCatalogDefault(name string) (int, error)
 	RunCatalogList() (int, error)
+	RunStateMigrate(opts app.StateMigrateOptions) (int, error)
+	RunStateRestore(opts app.StateRestoreOptions) (int, error)
 	RunConfig() error
 }
 
@@ -189,6 +193,7 @@ func newRootCommand(runtime *runtimeState) *cobra.Command {
 		newSchedulerCommand(runtime),
 		newRepoCommand(runtime),
 		newCatalogCommand(runtime),
+		newStateCommand(runtime),
 		newConfigCommand(runtime),
 	)
 	cmd.AddCommand(newCompletionCommand(runtime, cmd))
@@ -393,6 +398,7 @@ func exactArgsWithCommandHint(n int) cobra.PositionalArgs {
 
 func newScanCommand(runtime *runtimeState) *cobra.Command {
 	var includeCatalogs []string
+	var full bool
 
 	cmd := &cobra.Command{
 		Use:   "scan",
@@ -403,12 +409,13 @@ func newScanCommand(runtime *runtimeState) *cobra.Command {
 			if err != nil {
 				return withExitCode(2, err)
 			}
-			code, err := runner.RunScan(app.ScanOptions{IncludeCatalogs: includeCatalogs})
+			code, err := runner.RunScan(app.ScanOptions{IncludeCatalogs: includeCatalogs, Full: full})
 			return withExitCode(code, err)
 		},
 	}
 
 	cmd.Flags().StringArrayVar(&includeCatalogs, "include-catalog", nil, "Limit scope to selected catalogs (repeatable).")
+	cmd.Flags().BoolVar(&full, "full", false, "Ignore the scan cache and observe every repository.")
 
 	return cmd
 }
@@ -419,12 +426,16 @@ func newSyncCommand(runtime *runtimeState) *cobra.Command {
 	var notify bool
 	var notifyBackend string
 	var dryRun bool
+	var jsonOut bool
 
 	cmd := &cobra.Command{
 		Use:   "sync",
 		Short: "Run observe, publish, and reconcile flow.",
 		Args:  cobra.NoArgs,
 		RunE: func(_ *cobra.Command, _ []string) error {
+			if jsonOut && !dryRun {
+				return withExitCode(2, errors.New("--json requires --dry-run"))
+			}
 			runner, err := runtime.appRunner()
 			if err != nil {
 				return withExitCode(2, err)
@@ -435,6 +446,7 @@ func newSyncCommand(runtime *runtimeState) *cobra.Command {
 				Notify:          notify,
 				NotifyBackend:   notifyBackend,
 				DryRun:          dryRun,
+				JSON:            jsonOut,
 			})
 			return withExitCode(code, err)
 		},
@@ -443,8 +455,9 @@ func newSyncCommand(runtime *runtimeState) *cobra.Command {
 	cmd.Flags().StringArrayVar(&includeCatalogs, "include-catalog", nil, "Limit scope to selected catalogs (repeatable).")
 	cmd.Flags().BoolVar(&push, "push", false, "Allow pushing ahead commits when policy blocks by default.")
 	cmd.Flags().BoolVar(&notify, "notify", false, "Emit notifications for unsyncable repositories.")
-	cmd.Flags().StringVar(&notifyBackend, "notify-backend", "", "Notification backend override (stdout|osascript).")
-	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show reconcile decisions without write-side sync actions.")
+	cmd.Flags().StringVar(&notifyBackend, "notify-backend", "", "Notification backend override (stdout|osascript|notify-send|dbus|webhook).")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the reconcile plan without write-side sync actions.")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print the --dry-run plan as JSON.")
 
 	return cmd
 }
@@ -452,6 +465,8 @@ func newSyncCommand(runtime *runtimeState) *cobra.Command {
 func newStatusCommand(runtime *runtimeState) *cobra.Command {
 	var includeCatalogs []string
 	var jsonOut bool
+	var withMetadata bool
+	var allMachines bool
 
 	cmd := &cobra.Command{
 		Use:   "status",
@@ -462,12 +477,25 @@ func newStatusCommand(runtime *runtimeState) *cobra.Command {
 			if err != nil {
 				return withExitCode(2, err)
 			}
-			code, err := runner.RunStatus(jsonOut, includeCatalogs)
+			if withMetadata && !jsonOut {
+				return withExitCode(2, errors.New("--metadata requires --json"))
+			}
+			if withMetadata && allMachines {
+				return withExitCode(2, errors.New("--metadata and --all-machines are mutually exclusive"))
+			}
+			code, err := runner.RunStatus(app.StatusOptions{
+				IncludeCatalogs: includeCatalogs,
+				JSON:            jsonOut,
+				WithMetadata:    withMetadata,
+				AllMachines:     allMachines,
+			})
 			return withExitCode(code, err)
 		},
 	}
 
-	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print machine and repository state as JSON.")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print machine and repository state as JSON (schema_version 1).")
+	cmd.Flags().BoolVar(&allMachines, "all-machines", false, "Show a repo x machine matrix from every machine's last observations; * marks the sync winner.")
+	cmd.Flags().BoolVar(&withMetadata, "metadata", false, "Merge shared repo metadata (visibility, auto_push, push_access) into --json output.")
 	cmd.Flags().StringArrayVar(&includeCatalogs, "include-catalog", nil, "Limit scope to selected catalogs (repeatable).")
 
 	return cmd
@@ -481,15 +509,44 @@ func newFixCommand(runtime *runtimeState) *cobra.Command {
 	var returnToOriginalSync bool
 	var syncStrategy string
 	var noRefresh bool
+	var all bool
+	var action string
+	var dryRun bool
 
 	cmd := &cobra.Command{
 		Use:   "fix [project] [action]",
 		Short: "Inspect repositories and apply context-aware fixes.",
-		Args:  cobra.MaximumNArgs(2),
+		Long: strings.TrimSpace(`
+Inspect repositories and apply context-aware fixes.
+
+Without arguments, fix opens the interactive TUI. With a project it prints the
+eligible actions, and with a project and an action it applies that action.
+
+With --all --action <name>, fix applies one action to every repository in the
+selected catalogs where it is eligible. The execution plan for each repository
+is printed first; --dry-run stops there. A per-repository summary follows, and
+the exit code is 1 if any repository failed.
+`),
+		Args: cobra.MaximumNArgs(2),
 		RunE: func(_ *cobra.Command, args []string) error {
 			if strings.TrimSpace(message) != "" && aiMessage {
 				return withExitCode(2, errors.New("--message and --ai-message are mutually exclusive"))
 			}
+			if all {
+				if len(args) > 0 {
+					return withExitCode(2, errors.New("--all does not accept a project or action argument; use --action"))
+				}
+				if strings.TrimSpace(action) == "" {
+					return withExitCode(2, errors.New("--all requires --action"))
+				}
+			} else {
+				if strings.TrimSpace(action) != "" {
+					return withExitCode(2, errors.New("--action requires --all"))
+				}
+				if dryRun {
+					return withExitCode(2, errors.New("--dry-run requires --all"))
+				}
+			}
 
 			strategy, err := app.ParseFixSyncStrategy(syncStrategy)
 			if err != nil {
@@ -508,6 +565,9 @@ func newFixCommand(runtime *runtimeState) *cobra.Command {
 				ReturnToOriginalBranchAndSync: returnToOriginalSync,
 				SyncStrategy:                  strategy,
 				NoRefresh:                     noRefresh,
+				All:                           all,
+				Action:                        strings.TrimSpace(action),
+				DryRun:                        dryRun,
 			}
 			if len(args) > 0 {
 				opts.Project = args[0]
@@ -527,6 +587,9 @@ func newFixCommand(runtime *runtimeState) *cobra.Command {
 	cmd.Flags().BoolVar(&returnToOriginalSync, "return-to-original-sync", false, "After publish-new-branch, switch back to the original branch and run pull --ff-only.")
 	cmd.Flags().StringVar(&syncStrategy, "sync-strategy", string(app.FixSyncStrategyRebase), "Sync strategy for sync-with-upstream and pre-push validation (rebase|merge).")
 	cmd.Flags().BoolVar(&noRefresh, "no-refresh", false, "Use current machine snapshot without running a refresh scan first.")
+	cmd.Flags().BoolVar(&all, "all", false, "Apply --action to every eligible repository in the selected catalogs.")
+	cmd.Flags().StringVar(&action, "action", "", "Fix action to apply with --all (for example pull-ff-only).")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "With --all, print the per-repository plan without applying it.")
 
 	return cmd
 }
@@ -579,6 +642,7 @@ func newOperateCommand(runtime *runtimeState) *cobra.Command {
 
 func newDoctorCommand(runtime *runtimeState) *cobra.Command {
 	var includeCatalogs []string
+	var jsonOut bool
 
 	cmd := &cobra.Command{
 		Use:   "doctor",
@@ -589,6 +653,11 @@ Report unsyncable repositories and reasons.
 When GitHub integration is configured (or selected repositories use GitHub remotes),
 doctor also checks GitHub CLI prerequisites and emits warnings when gh is missing
 or unauthenticated, including remediation guidance.
+
+With --json, doctor prints every finding as a versioned JSON document
+(schema_version 1, see docs/schema/bb-doctor.v1.schema.json). Repo findings list
+the bb fix actions that apply and the commands to run them. Exit codes are the
+same as for text output.
 `),
 		Args: cobra.NoArgs,
 		RunE: func(_ *cobra.Command, _ []string) error {
@@ -596,11 +665,15 @@ or unauthenticated, including remediation guidance.
 			if err != nil {
 				return withExitCode(2, err)
 			}
-			code, err := runner.RunDoctor(includeCatalogs)
+			code, err := runner.RunDoctor(app.DoctorOptions{
+				IncludeCatalogs: includeCatalogs,
+				JSON:            jsonOut,
+			})
 			return withExitCode(code, err)
 		},
 	}
 
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print findings and remediation hints as JSON (schema_version 1).")
 	cmd.Flags().StringArrayVar(&includeCatalogs, "include-catalog", nil, "Limit scope to selected catalogs (repeatable).")
 
 	return cmd
@@ -642,6 +715,7 @@ func newSchedulerCommand(runtime *runtimeState) *cobra.Command {
 		},
 	}
 
+	var installBackend string
 	var notifyBackend string
 	installCmd := &cobra.Command{
 		Use:   "install",
@@ -653,13 +727,16 @@ func newSchedulerCommand(runtime *runtimeState) *cobra.Command {
 				return withExitCode(2, err)
 			}
 			code, err := runner.RunSchedulerInstall(app.SchedulerInstallOptions{
+				Backend:       installBackend,
 				NotifyBackend: notifyBackend,
 			})
 			return withExitCode(code, err)
 		},
 	}
-	installCmd.Flags().StringVar(&notifyBackend, "notify-backend", "", "Notification backend for scheduled runs (stdout|osascript).")
+	installCmd.Flags().StringVar(&installBackend, "backend", "", "Scheduler backend (launchd|systemd|cron); defaults to launchd on macOS and systemd on Linux.")
+	installCmd.Flags().StringVar(&notifyBackend, "notify-backend", "", "Notification backend for scheduled runs (stdout|osascript|notify-send|dbus|webhook).")
 
+	var statusBackend string
 	statusCmd := &cobra.Command{
 		Use:   "status",
 		Short: "Show scheduler installation status.",
@@ -669,11 +746,13 @@ func newSchedulerCommand(runtime *runtimeState) *cobra.Command {
 			if err != nil {
 				return withExitCode(2, err)
 			}
-			code, err := runner.RunSchedulerStatus()
+			code, err := runner.RunSchedulerStatus(statusBackend)
 			return withExitCode(code, err)
 		},
 	}
+	statusCmd.Flags().StringVar(&statusBackend, "backend", "", "Scheduler backend to inspect (launchd|systemd|cron); defaults to the installed one.")
 
+	var removeBackend string
 	removeCmd := &cobra.Command{
 		Use:   "remove",
 		Short: "Remove scheduler integration.",
@@ -683,10 +762,11 @@ func newSchedulerCommand(runtime *runtimeState) *cobra.Command {
 			if err != nil {
 				return withExitCode(2, err)
 			}
-			code, err := runner.RunSchedulerRemove()
+			code, err := runner.RunSchedulerRemove(removeBackend)
 			return withExitCode(code, err)
 		},
 	}
+	removeCmd.Flags().StringVar(&removeBackend, "backend", "", "Scheduler backend to remove (launchd|systemd|cron); defaults to the installed one.")
 
 	schedulerCmd.AddCommand(installCmd, statusCmd, removeCmd)
 	return schedulerCmd
@@ -747,6 +827,53 @@ func newRepoCommand(runtime *runtimeState) *cobra.Command {
 	remoteCmd.Flags().StringVar(&preferredRemote, "preferred-remote", "", "Preferred remote name for this repository (for example origin or upstream).")
 	_ = remoteCmd.MarkFlagRequired("preferred-remote")
 
+	var primaryMachine string
+	var primaryClear bool
+	primaryCmd := &cobra.Command{
+		Use:   "primary <repo>",
+		Short: "Set the machine whose state wins reconcile for a repository.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			runner, err := runtime.appRunner()
+			if err != nil {
+				return withExitCode(2, err)
+			}
+			code, err := runner.RunRepoPrimary(app.RepoPrimaryOptions{
+				Selector:  args[0],
+				MachineID: primaryMachine,
+				Clear:     primaryClear,
+			})
+			return withExitCode(code, err)
+		},
+	}
+	primaryCmd.Flags().StringVar(&primaryMachine, "machine", "", "Primary machine ID (defaults to this machine).")
+	primaryCmd.Flags().BoolVar(&primaryClear, "clear", false, "Remove the primary machine and select winners by history again.")
+	primaryCmd.MarkFlagsMutuallyExclusive("machine", "clear")
+
+	var branchPin string
+	var branchFollow bool
+	branchCmd := &cobra.Command{
+		Use:   "branch <repo>",
+		Short: "Pin a repository to a branch or let it follow the winner's branch.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			runner, err := runtime.appRunner()
+			if err != nil {
+				return withExitCode(2, err)
+			}
+			code, err := runner.RunRepoBranch(app.RepoBranchOptions{
+				Selector: args[0],
+				Pin:      branchPin,
+				Follow:   branchFollow,
+			})
+			return withExitCode(code, err)
+		},
+	}
+	branchCmd.Flags().StringVar(&branchPin, "pin", "", "Stop following the winner's branch and keep every machine on this branch.")
+	branchCmd.Flags().BoolVar(&branchFollow, "follow", false, "Follow the winner's branch again (clears any pin).")
+	branchCmd.MarkFlagsMutuallyExclusive("pin", "follow")
+	branchCmd.MarkFlagsOneRequired("pin", "follow")
+
 	var pushAccess string
 	accessSetCmd := &cobra.Command{
 		Use:   "access-set <repo>",
@@ -807,7 +934,7 @@ func newRepoCommand(runtime *runtimeState) *cobra.Command {
 	moveCmd.Flags().BoolVar(&moveNoHooks, "no-hooks", false, "Skip configured post-move hooks.")
 	_ = moveCmd.MarkFlagRequired("catalog")
 
-	repoCmd.AddCommand(policyCmd, remoteCmd, accessSetCmd, accessRefreshCmd, moveCmd)
+	repoCmd.AddCommand(policyCmd, remoteCmd, primaryCmd, branchCmd, accessSetCmd, accessRefreshCmd, moveCmd)
 	return repoCmd
 }
 
@@ -825,6 +952,7 @@ func newCatalogCommand(runtime *runtimeState) *cobra.Command {
 		},
 	}
 
+	var addLayout string
 	addCmd := &cobra.Command{
 		Use:   "add <name> <root>",
 		Short: "Add catalog root to current machine.",
@@ -834,10 +962,11 @@ func newCatalogCommand(runtime *runtimeState) *cobra.Command {
 			if err != nil {
 				return withExitCode(2, err)
 			}
-			code, err := runner.RunCatalogAdd(args[0], args[1])
+			code, err := runner.RunCatalogAdd(args[0], args[1], addLayout)
 			return withExitCode(code, err)
 		},
 	}
+	addCmd.Flags().StringVar(&addLayout, "layout", "", "Repository layout below the root: a pattern such as {host}/{owner}/{repo}, or depth:MIN-MAX.")
 
 	rmCmd := &cobra.Command{
 		Use:   "rm <name>",
@@ -885,6 +1014,72 @@ func newCatalogCommand(runtime *runtimeState) *cobra.Command {
 	return catalogCmd
 }
 
+func newStateCommand(runtime *runtimeState) *cobra.Command {
+	stateCmd := &cobra.Command{
+		Use:           "state",
+		Short:         "Maintain bb state files.",
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := cmd.Help(); err != nil {
+				return withExitCode(2, err)
+			}
+			return withExitCode(2, errors.New("state subcommand is required"))
+		},
+	}
+
+	var dryRun bool
+	migrateCmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Upgrade state files to the schema versions this bb writes.",
+		Long: strings.TrimSpace(`
+Upgrade config, machine, repo metadata, and notify-cache files to the schema
+versions this bb writes.
+
+bb already upgrades older files in memory when it loads them; this command
+rewrites them on disk. Files written by a newer bb are reported and nothing is
+changed (exit code 1).
+`),
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			runner, err := runtime.appRunner()
+			if err != nil {
+				return withExitCode(2, err)
+			}
+			code, err := runner.RunStateMigrate(app.StateMigrateOptions{DryRun: dryRun})
+			return withExitCode(code, err)
+		},
+	}
+	migrateCmd.Flags().BoolVar(&dryRun, "dry-run", false, "List the migrations without writing any file.")
+
+	var backupID string
+	restoreCmd := &cobra.Command{
+		Use:   "restore <file>",
+		Short: "List or restore backups of a state file.",
+		Long: strings.TrimSpace(`
+List or restore backups of a state file.
+
+<file> is config.yaml, notify-cache.yaml, machines/<id>.yaml, or
+repos/<name>.yaml. bb keeps the last 5 versions of each state file and falls
+back to the newest valid backup when a file does not parse. Without --backup
+the backups are listed; --backup latest restores the newest valid one.
+`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			runner, err := runtime.appRunner()
+			if err != nil {
+				return withExitCode(2, err)
+			}
+			code, err := runner.RunStateRestore(app.StateRestoreOptions{File: args[0], Backup: backupID})
+			return withExitCode(code, err)
+		},
+	}
+	restoreCmd.Flags().StringVar(&backupID, "backup", "", "Backup ID to restore, or \"latest\" for the newest valid backup.")
+
+	stateCmd.AddCommand(migrateCmd, restoreCmd)
+	return stateCmd
+}
+
 func newConfigCommand(runtime *runtimeState) *cobra.Command {
 	return &cobra.Command{
 		Use:   "config",
@@ -24,8 +24,11 @@ type fakeApp struct {
 	linkOpts    app.LinkOptions
 	infoOpts    app.InfoOptions
 	statusJSON  bool
+	statusMeta  bool
+	statusAll   bool
 	statusIncl  []string
 	doctorIncl  []string
+	doctorJSON  bool
 	ensureIncl  []string
 	diffProj    string
 	diffArgs    []string
@@ -40,16 +43,27 @@ type fakeApp struct {
 	repoAccessValue     string
 	repoRefreshSelector string
 	repoMoveOpts        app.RepoMoveOptions
+	repoPrimaryOpts     app.RepoPrimaryOptions
+	repoBranchOpts      app.RepoBranchOptions
 
-	catalogAddName string
-	catalogAddRoot string
-	catalogRMName  string
-	catalogDefName string
+	catalogAddName   string
+	catalogAddRoot   string
+	catalogAddLayout string
+	catalogRMName    string
+	catalogDefName   string
 
 	schedulerInstallOpts  app.SchedulerInstallOptions
 	schedulerInstallCalls int
 	schedulerStatusCalls  int
+	schedulerStatusBack   string
 	schedulerRemoveCalls  int
+	schedulerRemoveBack   string
+
+	stateMigrateOpts  app.StateMigrateOptions
+	stateMigrateCalls int
+
+	stateRestoreOpts  app.StateRestoreOptions
+	stateRestoreCalls int
 
 	initErr         error
 	scanCode        int
@@ -80,6 +94,10 @@ type fakeApp struct {
 	repoRefreshErr  error
 	repoMoveCode    int
 	repoMoveErr     error
+	repoPrimaryCode int
+	repoPrimaryErr  error
+	repoBranchCode  int
+	repoBranchErr   error
 	catalogAddCode  int
 	catalogAddErr   error
 	catalogRMCode   int
@@ -100,6 +118,11 @@ type fakeApp struct {
 	schedulerStatusErr   error
 	schedulerRemoveCode  int
 	schedulerRemoveErr   error
+
+	stateMigrateCode int
+	stateMigrateErr  error
+	stateRestoreCode int
+	stateRestoreErr  error
 }
 
 func (f *fakeApp) SetVerbose(verbose bool) {
@@ -141,14 +164,17 @@ func (f *fakeApp) RunInfo(opts app.InfoOptions) (int, error) {
 	return f.infoCode, f.infoErr
 }
 
-func (f *fakeApp) RunStatus(jsonOut bool, include []string) (int, error) {
-	f.statusJSON = jsonOut
-	f.statusIncl = append([]string(nil), include...)
+func (f *fakeApp) RunStatus(opts app.StatusOptions) (int, error) {
+	f.statusJSON = opts.JSON
+	f.statusMeta = opts.WithMetadata
+	f.statusAll = opts.AllMachines
+	f.statusIncl = append([]string(nil), opts.IncludeCatalogs...)
 	return f.statusCode, f.statusErr
 }
 
-func (f *fakeApp) RunDoctor(include []string) (int, error) {
-	f.doctorIncl = append([]string(nil), include...)
+func (f *fakeApp) RunDoctor(opts app.DoctorOptions) (int, error) {
+	f.doctorIncl = append([]string(nil), opts.IncludeCatalogs...)
+	f.doctorJSON = opts.JSON
 	return f.doctorCode, f.doctorErr
 }
 
@@ -181,6 +207,16 @@ func (f *fakeApp) RunRepoPreferredRemote(repoSelector string, preferredRemote st
 	return f.repoRemoteCode, f.repoRemoteErr
 }
 
+func (f *fakeApp) RunRepoPrimary(opts app.RepoPrimaryOptions) (int, error) {
+	f.repoPrimaryOpts = opts
+	return f.repoPrimaryCode, f.repoPrimaryErr
+}
+
+func (f *fakeApp) RunRepoBranch(opts app.RepoBranchOptions) (int, error) {
+	f.repoBranchOpts = opts
+	return f.repoBranchCode, f.repoBranchErr
+}
+
 func (f *fakeApp) RunRepoPushAccessSet(repoSelector string, pushAccess string) (int, error) {
 	f.repoAccessSelector = repoSelector
 	f.repoAccessValue = pushAccess
@@ -197,9 +233,10 @@ func (f *fakeApp) RunRepoMove(opts app.RepoMoveOptions) (int, error) {
 	return f.repoMoveCode, f.repoMoveErr
 }
 
-func (f *fakeApp) RunCatalogAdd(name, root string) (int, error) {
+func (f *fakeApp) RunCatalogAdd(name, root, layout string) (int, error) {
 	f.catalogAddName = name
 	f.catalogAddRoot = root
+	f.catalogAddLayout = layout
 	return f.catalogAddCode, f.catalogAddErr
 }
 
@@ -217,6 +254,18 @@ func (f *fakeApp) RunCatalogList() (int, error) {
 	return f.catalogListCode, f.catalogListErr
 }
 
+func (f *fakeApp) RunStateMigrate(opts app.StateMigrateOptions) (int, error) {
+	f.stateMigrateCalls++
+	f.stateMigrateOpts = opts
+	return f.stateMigrateCode, f.stateMigrateErr
+}
+
+func (f *fakeApp) RunStateRestore(opts app.StateRestoreOptions) (int, error) {
+	f.stateRestoreCalls++
+	f.stateRestoreOpts = opts
+	return f.stateRestoreCode, f.stateRestoreErr
+}
+
 func (f *fakeApp) RunConfig() error {
 	return f.configErr
 }
@@ -227,13 +276,15 @@ func (f *fakeApp) RunSchedulerInstall(opts app.SchedulerInstallOptions) (int, er
 	return f.schedulerInstallCode, f.schedulerInstallErr
 }
 
-func (f *fakeApp) RunSchedulerStatus() (int, error) {
+func (f *fakeApp) RunSchedulerStatus(backend string) (int, error) {
 	f.schedulerStatusCalls++
+	f.schedulerStatusBack = backend
 	return f.schedulerStatusCode, f.schedulerStatusErr
 }
 
-func (f *fakeApp) RunSchedulerRemove() (int, error) {
+func (f *fakeApp) RunSchedulerRemove(backend string) (int, error) {
 	f.schedulerRemoveCalls++
+	f.schedulerRemoveBack = backend
 	return f.schedulerRemoveCode, f.schedulerRemoveErr
 }
 
@@ -427,6 +478,20 @@ func TestRunScanAndSyncForwardOptions(t *testing.T) {
 			t.Fatalf("stderr = %q, want empty", stderr)
 		}
 		mustEqualSlices(t, fake.scanOpts.IncludeCatalogs, []string{"software", "references"})
+		if fake.scanOpts.Full {
+			t.Fatal("scan full = true, want false by default")
+		}
+	})
+
+	t.Run("scan full", func(t *testing.T) {
+		fake := &fakeApp{}
+		code, _, _, _, _ := runCLI(t, fake, []string{"scan", "--full"})
+		if code != 0 {
+			t.Fatalf("exit code = %d, want 0", code)
+		}
+		if !fake.scanOpts.Full {
+			t.Fatal("scan full = false, want true")
+		}
 	})
 
 	t.Run("sync flags", func(t *testing.T) {
@@ -446,6 +511,26 @@ func TestRunScanAndSyncForwardOptions(t *testing.T) {
 		}
 		mustEqualSlices(t, fake.syncOpts.IncludeCatalogs, []string{"software"})
 	})
+
+	t.Run("sync json requires dry-run", func(t *testing.T) {
+		fake := &fakeApp{}
+		code, _, stderr, calls, _ := runCLI(t, fake, []string{"sync", "--json"})
+		if code != 2 {
+			t.Fatalf("exit code = %d, want 2", code)
+		}
+		if calls != 0 {
+			t.Fatalf("app factory calls = %d, want 0", calls)
+		}
+		mustContain(t, stderr, "--json requires --dry-run")
+
+		code, _, _, _, _ = runCLI(t, fake, []string{"sync", "--dry-run", "--json"})
+		if code != 0 {
+			t.Fatalf("exit code = %d, want 0", code)
+		}
+		if !fake.syncOpts.DryRun || !fake.syncOpts.JSON {
+			t.Fatalf("sync flags not forwarded: %#v", fake.syncOpts)
+		}
+	})
 }
 
 func TestRunStatusDoctorEnsureForwardOptions(t *testing.T) {
@@ -463,6 +548,36 @@ func TestRunStatusDoctorEnsureForwardOptions(t *testing.T) {
 		t.Fatal("expected json output flag to be forwarded")
 	}
 	mustEqualSlices(t, fake.statusIncl, []string{"software", "references"})
+	if fake.statusMeta {
+		t.Fatal("did not expect metadata flag without --metadata")
+	}
+
+	fake = &fakeApp{}
+	code, _, _, _, _ = runCLI(t, fake, []string{"status", "--json", "--metadata"})
+	if code != 0 {
+		t.Fatalf("status --metadata exit code = %d, want 0", code)
+	}
+	if !fake.statusMeta {
+		t.Fatal("expected metadata flag to be forwarded")
+	}
+
+	fake = &fakeApp{}
+	code, _, _, _, _ = runCLI(t, fake, []string{"status", "--all-machines", "--json"})
+	if code != 0 {
+		t.Fatalf("status --all-machines exit code = %d, want 0", code)
+	}
+	if !fake.statusAll || !fake.statusJSON {
+		t.Fatal("expected all-machines and json flags to be forwarded")
+	}
+
+	fake = &fakeApp{}
+	code, _, stderr, _, _ = runCLI(t, fake, []string{"status", "--metadata"})
+	if code != 2 {
+		t.Fatalf("status --metadata without --json exit code = %d, want 2", code)
+	}
+	if !strings.Contains(stderr, "--metadata requires --json") {
+		t.Fatalf("stderr = %q", stderr)
+	}
 
 	fake = &fakeApp{}
 	code, _, stderr, _, _ = runCLI(t, fake, []string{"doctor", "--include-catalog", "software"})
@@ -473,6 +588,18 @@ func TestRunStatusDoctorEnsureForwardOptions(t *testing.T) {
 		t.Fatalf("stderr = %q, want empty", stderr)
 	}
 	mustEqualSlices(t, fake.doctorIncl, []string{"software"})
+	if fake.doctorJSON {
+		t.Fatal("did not expect doctor json flag without --json")
+	}
+
+	fake = &fakeApp{}
+	code, _, _, _, _ = runCLI(t, fake, []string{"doctor", "--json"})
+	if code != 0 {
+		t.Fatalf("doctor --json exit code = %d, want 0", code)
+	}
+	if !fake.doctorJSON {
+		t.Fatal("expected doctor json flag to be forwarded")
+	}
 
 	fake = &fakeApp{}
 	code, _, stderr, _, _ = runCLI(t, fake, []string{"ensure", "--include-catalog", "software"})
@@ -620,6 +747,43 @@ func TestRunFixForwardsOptions(t *testing.T) {
 		}
 	})
 
+	t.Run("forwards batch mode", func(t *testing.T) {
+		fake := &fakeApp{}
+		code, _, stderr, _, _ := runCLI(t, fake, []string{"fix", "--all", "--action", "pull-ff-only", "--dry-run", "--include-catalog", "software"})
+		if code != 0 {
+			t.Fatalf("exit code = %d, want 0", code)
+		}
+		if stderr != "" {
+			t.Fatalf("stderr = %q, want empty", stderr)
+		}
+		if !fake.fixOpts.All || !fake.fixOpts.DryRun || fake.fixOpts.Action != "pull-ff-only" || fake.fixOpts.Project != "" {
+			t.Fatalf("unexpected fix opts: %#v", fake.fixOpts)
+		}
+		mustEqualSlices(t, fake.fixOpts.IncludeCatalogs, []string{"software"})
+	})
+
+	t.Run("rejects invalid batch flag combinations", func(t *testing.T) {
+		for _, tc := range []struct {
+			args []string
+			want string
+		}{
+			{args: []string{"fix", "--all"}, want: "--all requires --action"},
+			{args: []string{"fix", "--all", "--action", "push", "api"}, want: "--all does not accept"},
+			{args: []string{"fix", "--action", "push"}, want: "--action requires --all"},
+			{args: []string{"fix", "api", "push", "--dry-run"}, want: "--dry-run requires --all"},
+		} {
+			fake := &fakeApp{}
+			code, _, stderr, calls, _ := runCLI(t, fake, tc.args)
+			if code != 2 {
+				t.Fatalf("%v: exit code = %d, want 2", tc.args, code)
+			}
+			if calls != 0 {
+				t.Fatalf("%v: app factory calls = %d, want 0", tc.args, calls)
+			}
+			mustContain(t, stderr, tc.want)
+		}
+	})
+
 	t.Run("invalid sync strategy returns usage error", func(t *testing.T) {
 		fake := &fakeApp{}
 		code, _, stderr, calls, _ := runCLI(t, fake, []string{"fix", "--sync-strategy=invalid"})
@@ -753,6 +917,61 @@ func TestRunRepoPolicyValidationAndForwarding(t *testing.T) {
 		}
 	})
 
+	t.Run("primary forwards values", func(t *testing.T) {
+		fake := &fakeApp{}
+		code, _, stderr, _, _ := runCLI(t, fake, []string{"repo", "primary", "demo", "--machine", "desktop"})
+		if code != 0 {
+			t.Fatalf("exit code = %d, want 0", code)
+		}
+		if stderr != "" {
+			t.Fatalf("stderr = %q, want empty", stderr)
+		}
+		want := app.RepoPrimaryOptions{Selector: "demo", MachineID: "desktop"}
+		if fake.repoPrimaryOpts != want {
+			t.Fatalf("primary opts = %+v, want %+v", fake.repoPrimaryOpts, want)
+		}
+	})
+
+	t.Run("primary rejects machine with clear", func(t *testing.T) {
+		fake := &fakeApp{}
+		code, _, stderr, calls, _ := runCLI(t, fake, []string{"repo", "primary", "demo", "--machine", "desktop", "--clear"})
+		if code != 2 {
+			t.Fatalf("exit code = %d, want 2", code)
+		}
+		if calls != 0 {
+			t.Fatalf("app factory calls = %d, want 0", calls)
+		}
+		mustContain(t, stderr, "clear")
+	})
+
+	t.Run("branch requires pin or follow", func(t *testing.T) {
+		fake := &fakeApp{}
+		code, _, stderr, calls, _ := runCLI(t, fake, []string{"repo", "branch", "demo"})
+		if code != 2 {
+			t.Fatalf("exit code = %d, want 2", code)
+		}
+		if calls != 0 {
+			t.Fatalf("app factory calls = %d, want 0", calls)
+		}
+		mustContain(t, stderr, "pin")
+		mustContain(t, stderr, "follow")
+	})
+
+	t.Run("branch forwards pin", func(t *testing.T) {
+		fake := &fakeApp{}
+		code, _, stderr, _, _ := runCLI(t, fake, []string{"repo", "branch", "demo", "--pin", "main"})
+		if code != 0 {
+			t.Fatalf("exit code = %d, want 0", code)
+		}
+		if stderr != "" {
+			t.Fatalf("stderr = %q, want empty", stderr)
+		}
+		want := app.RepoBranchOptions{Selector: "demo", Pin: "main"}
+		if fake.repoBranchOpts != want {
+			t.Fatalf("branch opts = %+v, want %+v", fake.repoBranchOpts, want)
+		}
+	})
+
 	t.Run("access-set requires flag", func(t *testing.T) {
 		fake := &fakeApp{}
 		code, _, stderr, calls, _ := runCLI(t, fake, []string{"repo", "access-set", "demo"})
@@ -857,6 +1076,17 @@ func TestRunCatalogAndConfigCommands(t *testing.T) {
 		}
 	})
 
+	t.Run("catalog add forwards layout", func(t *testing.T) {
+		fake := &fakeApp{}
+		code, _, stderr, _, _ := runCLI(t, fake, []string{"catalog", "add", "src", "/tmp/src", "--layout", "{host}/{owner}/{repo}"})
+		if code != 0 {
+			t.Fatalf("exit code = %d, want 0 (stderr=%q)", code, stderr)
+		}
+		if fake.catalogAddLayout != "{host}/{owner}/{repo}" {
+			t.Fatalf("catalog add layout = %q, want {host}/{owner}/{repo}", fake.catalogAddLayout)
+		}
+	})
+
 	t.Run("scheduler install forwards backend", func(t *testing.T) {
 		fake := &fakeApp{}
 		code, _, stderr, _, _ := runCLI(t, fake, []string{"scheduler", "install", "--notify-backend", "osascript"})
@@ -874,9 +1104,23 @@ func TestRunCatalogAndConfigCommands(t *testing.T) {
 		}
 	})
 
+	t.Run("scheduler install forwards scheduler backend", func(t *testing.T) {
+		fake := &fakeApp{}
+		code, _, stderr, _, _ := runCLI(t, fake, []string{"scheduler", "install", "--backend", "cron"})
+		if code != 0 {
+			t.Fatalf("exit code = %d, want 0", code)
+		}
+		if stderr != "" {
+			t.Fatalf("stderr = %q, want empty", stderr)
+		}
+		if fake.schedulerInstallOpts.Backend != "cron" {
+			t.Fatalf("scheduler backend = %q, want %q", fake.schedulerInstallOpts.Backend, "cron")
+		}
+	})
+
 	t.Run("scheduler status", func(t *testing.T) {
 		fake := &fakeApp{}
-		code, _, stderr, _, _ := runCLI(t, fake, []string{"scheduler", "status"})
+		code, _, stderr, _, _ := runCLI(t, fake, []string{"scheduler", "status", "--backend", "cron"})
 		if code != 0 {
 			t.Fatalf("exit code = %d, want 0", code)
 		}
@@ -886,6 +1130,9 @@ func TestRunCatalogAndConfigCommands(t *testing.T) {
 		if fake.schedulerStatusCalls != 1 {
 			t.Fatalf("status calls = %d, want 1", fake.schedulerStatusCalls)
 		}
+		if fake.schedulerStatusBack != "cron" {
+			t.Fatalf("status backend = %q, want %q", fake.schedulerStatusBack, "cron")
+		}
 	})
 
 	t.Run("scheduler remove", func(t *testing.T) {
@@ -902,6 +1149,47 @@ func TestRunCatalogAndConfigCommands(t *testing.T) {
 		}
 	})
 
+	t.Run("state migrate forwards dry-run", func(t *testing.T) {
+		fake := &fakeApp{stateMigrateCode: 1}
+		code, _, stderr, _, _ := runCLI(t, fake, []string{"state", "migrate", "--dry-run"})
+		if code != 1 {
+			t.Fatalf("exit code = %d, want 1", code)
+		}
+		if stderr != "" {
+			t.Fatalf("stderr = %q, want empty", stderr)
+		}
+		if fake.stateMigrateCalls != 1 || !fake.stateMigrateOpts.DryRun {
+			t.Fatalf("migrate calls = %d opts = %+v, want one dry-run call", fake.stateMigrateCalls, fake.stateMigrateOpts)
+		}
+	})
+
+	t.Run("state restore forwards file and backup", func(t *testing.T) {
+		fake := &fakeApp{}
+		code, _, stderr, _, _ := runCLI(t, fake, []string{"state", "restore", "machines/mac-a.yaml", "--backup", "latest"})
+		if code != 0 {
+			t.Fatalf("exit code = %d, want 0", code)
+		}
+		if stderr != "" {
+			t.Fatalf("stderr = %q, want empty", stderr)
+		}
+		want := app.StateRestoreOptions{File: "machines/mac-a.yaml", Backup: "latest"}
+		if fake.stateRestoreCalls != 1 || fake.stateRestoreOpts != want {
+			t.Fatalf("restore calls = %d opts = %+v, want one call with %+v", fake.stateRestoreCalls, fake.stateRestoreOpts, want)
+		}
+	})
+
+	t.Run("state requires subcommand", func(t *testing.T) {
+		fake := &fakeApp{}
+		code, _, stderr, calls, _ := runCLI(t, fake, []string{"state"})
+		if code != 2 {
+			t.Fatalf("exit code = %d, want 2", code)
+		}
+		if calls != 0 {
+			t.Fatalf("app factory calls = %d, want 0", calls)
+		}
+		mustContain(t, stderr, "state subcommand is required")
+	})
+
 	t.Run("config rejects args", func(t *testing.T) {
 		fake := &fakeApp{}
 		code, _, stderr, calls, _ := runCLI(t, fake, []string{"config", "extra"})
@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"path"
+	"strings"
+)
+
+// IgnoreFileName is the per-directory exclude file honored by discovery.
+const IgnoreFileName = ".bbignore"
+
+// ExcludeRules matches catalog-relative directory paths against exclude
+// patterns from the catalog config and .bbignore files.
+//
+// Patterns use path.Match syntax and gitignore-like anchoring: a pattern
+// without a slash matches a directory name at any depth below the directory
+// that declared it, a pattern with a slash matches the path relative to that
+// directory. A matching directory excludes everything beneath it.
+type ExcludeRules struct {
+	rules []excludeRule
+}
+
+type excludeRule struct {
+	base     string
+	pattern  string
+	anchored bool
+}
+
+// CatalogExcludeRules returns the rules from the catalog's exclude config.
+func CatalogExcludeRules(c Catalog) ExcludeRules {
+	var rules ExcludeRules
+	rules.Add("", c.Exclude...)
+	return rules
+}
+
+// Add registers patterns declared in the catalog-relative directory base
+// ("" for the catalog root). Blank patterns are ignored.
+func (r *ExcludeRules) Add(base string, patterns ...string) {
+	base = strings.Trim(path.Clean("/"+strings.ReplaceAll(base, "\\", "/")), "/")
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(strings.ReplaceAll(pattern, "\\", "/"))
+		pattern = strings.TrimSuffix(pattern, "/")
+		if pattern == "" {
+			continue
+		}
+		anchored := strings.Contains(pattern, "/")
+		r.rules = append(r.rules, excludeRule{
+			base:     base,
+			pattern:  strings.TrimPrefix(pattern, "/"),
+			anchored: anchored,
+		})
+	}
+}
+
+// Matches reports whether relativePath, or one of its parent directories
+// below the catalog root, is excluded.
+func (r ExcludeRules) Matches(relativePath string) bool {
+	relativePath = strings.Trim(path.Clean("/"+strings.ReplaceAll(relativePath, "\\", "/")), "/")
+	if relativePath == "" {
+		return false
+	}
+	for _, rule := range r.rules {
+		sub := relativePath
+		if rule.base != "" {
+			var ok bool
+			sub, ok = strings.CutPrefix(relativePath, rule.base+"/")
+			if !ok {
+				continue
+			}
+		}
+		parts := strings.Split(sub, "/")
+		for i := range parts {
+			candidate := parts[i]
+			if rule.anchored {
+				candidate = strings.Join(parts[:i+1], "/")
+			}
+			if matched, _ := path.Match(rule.pattern, candidate); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ParseIgnoreFile returns the patterns of a .bbignore file: one per line,
+// with blank lines and lines starting with '#' skipped.
+func ParseIgnoreFile(content string) []string {
+	var out []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		out = append(out, line)
+	}
+	return out
+}
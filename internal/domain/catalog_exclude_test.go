@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExcludeRulesMatches(t *testing.T) {
+	t.Parallel()
+
+	rules := CatalogExcludeRules(Catalog{Exclude: []string{"scratch", "vendor/*", "/tmp-*/"}})
+	rules.Add("work", "old-*")
+	rules.Add("work/team", "/legacy")
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "scratch", want: true},
+		{path: "you/scratch", want: true},
+		{path: "scratch/api", want: true},
+		{path: "vendor/lib", want: true},
+		{path: "you/vendor/lib", want: false},
+		{path: "tmp-1", want: true},
+		{path: "you/tmp-1", want: false},
+		{path: "work/old-api", want: true},
+		{path: "work/you/old-api", want: true},
+		{path: "old-api", want: false},
+		{path: "work/team/legacy", want: true},
+		{path: "work/team/you/legacy", want: false},
+		{path: "work/api", want: false},
+		{path: "", want: false},
+	}
+	for _, tt := range tests {
+		if got := rules.Matches(tt.path); got != tt.want {
+			t.Errorf("Matches(%q) = %t, want %t", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParseIgnoreFile(t *testing.T) {
+	t.Parallel()
+
+	got := ParseIgnoreFile("# experiments\nscratch\n\n  vendor/*  \n#old\n")
+	if want := []string{"scratch", "vendor/*"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseIgnoreFile() = %v, want %v", got, want)
+	}
+}
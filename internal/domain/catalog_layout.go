@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Layout placeholders usable in a catalog layout pattern.
+const (
+	LayoutHost  = "{host}"
+	LayoutOwner = "{owner}"
+	LayoutRepo  = "{repo}"
+)
+
+// CatalogLayout describes where repositories live below a catalog root:
+// either a pattern of placeholder and literal segments such as
+// {host}/{owner}/{repo}, or any depth in MinDepth..MaxDepth.
+type CatalogLayout struct {
+	// Segments is the pattern, one entry per path segment; empty for depth
+	// layouts.
+	Segments []string
+	MinDepth int
+	MaxDepth int
+}
+
+// ParseCatalogLayout parses a catalog `layout` value: a pattern made of
+// {host}, {owner}, {repo} and literal segments ending in {repo}, or a depth
+// range written "depth:N" or "depth:MIN-MAX".
+func ParseCatalogLayout(raw string) (CatalogLayout, error) {
+	raw = strings.TrimSpace(raw)
+	if rng, ok := strings.CutPrefix(raw, "depth:"); ok {
+		minRaw, maxRaw, isRange := strings.Cut(rng, "-")
+		if !isRange {
+			maxRaw = minRaw
+		}
+		minDepth, minErr := strconv.Atoi(strings.TrimSpace(minRaw))
+		maxDepth, maxErr := strconv.Atoi(strings.TrimSpace(maxRaw))
+		if minErr != nil || maxErr != nil || minDepth < 1 || maxDepth < minDepth {
+			return CatalogLayout{}, fmt.Errorf("layout %q: depth must be N or MIN-MAX with 1 <= MIN <= MAX", raw)
+		}
+		return CatalogLayout{MinDepth: minDepth, MaxDepth: maxDepth}, nil
+	}
+
+	segments := strings.Split(strings.Trim(raw, "/"), "/")
+	seen := map[string]bool{}
+	for _, segment := range segments {
+		switch {
+		case segment == "" || segment == "." || segment == "..":
+			return CatalogLayout{}, fmt.Errorf("layout %q: invalid path segment %q", raw, segment)
+		case segment == LayoutHost || segment == LayoutOwner || segment == LayoutRepo:
+			if seen[segment] {
+				return CatalogLayout{}, fmt.Errorf("layout %q: %s appears more than once", raw, segment)
+			}
+			seen[segment] = true
+		case strings.ContainsAny(segment, "{}"):
+			return CatalogLayout{}, fmt.Errorf("layout %q: unknown placeholder %q (use {host}, {owner} or {repo})", raw, segment)
+		}
+	}
+	if segments[len(segments)-1] != LayoutRepo {
+		return CatalogLayout{}, fmt.Errorf("layout %q must end with %s", raw, LayoutRepo)
+	}
+	return CatalogLayout{Segments: segments, MinDepth: len(segments), MaxDepth: len(segments)}, nil
+}
+
+// EffectiveCatalogLayout returns the catalog's layout, falling back to its
+// repo_path_depth when layout is unset or invalid.
+func EffectiveCatalogLayout(c Catalog) CatalogLayout {
+	if strings.TrimSpace(c.Layout) != "" {
+		if layout, err := ParseCatalogLayout(c.Layout); err == nil {
+			return layout
+		}
+	}
+	depth := EffectiveRepoPathDepth(c)
+	return CatalogLayout{MinDepth: depth, MaxDepth: depth}
+}
+
+// Matches reports whether a catalog-relative path, split into segments, is
+// a repository location under the layout.
+func (l CatalogLayout) Matches(parts []string) bool {
+	if len(parts) < l.MinDepth || len(parts) > l.MaxDepth {
+		return false
+	}
+	for i, segment := range l.Segments {
+		if !strings.HasPrefix(segment, "{") && parts[i] != segment {
+			return false
+		}
+	}
+	return true
+}
+
+// Expand builds the catalog-relative path for a repository from its host,
+// owner and name. Depth layouts use {repo}, {owner}/{repo} or
+// {host}/{owner}/{repo}, preferring {owner}/{repo} when the range allows
+// several. ok is false when a value the layout needs is empty.
+func (l CatalogLayout) Expand(host string, owner string, repo string) (relativePath string, ok bool) {
+	segments := l.Segments
+	if len(segments) == 0 {
+		byDepth := map[int][]string{
+			1: {LayoutRepo},
+			2: {LayoutOwner, LayoutRepo},
+			3: {LayoutHost, LayoutOwner, LayoutRepo},
+		}
+		for _, depth := range []int{2, 1, 3} {
+			if depth >= l.MinDepth && depth <= l.MaxDepth {
+				segments = byDepth[depth]
+				break
+			}
+		}
+		if len(segments) == 0 {
+			return "", false
+		}
+	}
+	values := map[string]string{
+		LayoutHost:  strings.TrimSpace(host),
+		LayoutOwner: strings.TrimSpace(owner),
+		LayoutRepo:  strings.TrimSpace(repo),
+	}
+	out := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		if value, placeholder := values[segment]; placeholder {
+			if value == "" {
+				return "", false
+			}
+			segment = value
+		}
+		out = append(out, segment)
+	}
+	return strings.Join(out, "/"), true
+}
+
+// String renders the layout for messages: the pattern, or "depth N" /
+// "depth MIN-MAX".
+func (l CatalogLayout) String() string {
+	if len(l.Segments) > 0 {
+		return strings.Join(l.Segments, "/")
+	}
+	if l.MinDepth == l.MaxDepth {
+		return fmt.Sprintf("depth %d", l.MinDepth)
+	}
+	return fmt.Sprintf("depth %d-%d", l.MinDepth, l.MaxDepth)
+}
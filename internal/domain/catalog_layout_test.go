@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCatalogLayout(t *testing.T) {
+	t.Parallel()
+
+	valid := map[string]CatalogLayout{
+		"{host}/{owner}/{repo}": {Segments: []string{"{host}", "{owner}", "{repo}"}, MinDepth: 3, MaxDepth: 3},
+		"/work/{repo}/":         {Segments: []string{"work", "{repo}"}, MinDepth: 2, MaxDepth: 2},
+		"depth:3":               {MinDepth: 3, MaxDepth: 3},
+		"depth:1-3":             {MinDepth: 1, MaxDepth: 3},
+	}
+	for raw, want := range valid {
+		got, err := ParseCatalogLayout(raw)
+		if err != nil {
+			t.Fatalf("ParseCatalogLayout(%q) error = %v", raw, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("ParseCatalogLayout(%q) = %+v, want %+v", raw, got, want)
+		}
+	}
+
+	for _, raw := range []string{"", "{owner}", "{repo}/{owner}", "{repo}/{repo}", "{org}/{repo}", "a//{repo}", "depth:0", "depth:3-1", "depth:x"} {
+		if _, err := ParseCatalogLayout(raw); err == nil {
+			t.Fatalf("ParseCatalogLayout(%q) succeeded, want error", raw)
+		}
+	}
+}
+
+func TestCatalogLayoutMatchesAndExpands(t *testing.T) {
+	t.Parallel()
+
+	ghq := EffectiveCatalogLayout(Catalog{Layout: "{host}/{owner}/{repo}"})
+	if !ghq.Matches([]string{"github.com", "you", "api"}) || ghq.Matches([]string{"you", "api"}) {
+		t.Fatalf("unexpected matches for %s", ghq)
+	}
+	if got, ok := ghq.Expand("github.com", "you", "api"); !ok || got != "github.com/you/api" {
+		t.Fatalf("Expand() = %q, %t", got, ok)
+	}
+	if _, ok := ghq.Expand("", "you", "api"); ok {
+		t.Fatal("Expand() without host succeeded")
+	}
+
+	literal := EffectiveCatalogLayout(Catalog{Layout: "work/{repo}"})
+	if !literal.Matches([]string{"work", "api"}) || literal.Matches([]string{"play", "api"}) {
+		t.Fatalf("unexpected literal matches for %s", literal)
+	}
+
+	mixed := EffectiveCatalogLayout(Catalog{Layout: "depth:1-3"})
+	for _, parts := range [][]string{{"api"}, {"you", "api"}, {"github.com", "you", "api"}} {
+		if !mixed.Matches(parts) {
+			t.Fatalf("%s does not match %v", mixed, parts)
+		}
+	}
+	if mixed.Matches([]string{"a", "b", "c", "d"}) || mixed.String() != "depth 1-3" {
+		t.Fatalf("unexpected range layout %s", mixed)
+	}
+	if got, ok := mixed.Expand("github.com", "you", "api"); !ok || got != "you/api" {
+		t.Fatalf("range Expand() = %q, %t, want you/api", got, ok)
+	}
+
+	legacy := EffectiveCatalogLayout(Catalog{RepoPathDepth: 2})
+	if legacy.String() != "depth 2" || !legacy.Matches([]string{"you", "api"}) {
+		t.Fatalf("unexpected legacy layout %s", legacy)
+	}
+	if fallback := EffectiveCatalogLayout(Catalog{Layout: "{bad}", RepoPathDepth: 2}); fallback.String() != "depth 2" {
+		t.Fatalf("invalid layout fallback = %s, want depth 2", fallback)
+	}
+}
+
+func TestDeriveRepoKeyFromRelativeWithLayout(t *testing.T) {
+	t.Parallel()
+
+	catalog := Catalog{Name: "src", Layout: "{host}/{owner}/{repo}"}
+	key, rel, name, ok := DeriveRepoKeyFromRelative(catalog, "github.com/you/api")
+	if !ok || key != "src/github.com/you/api" || rel != "github.com/you/api" || name != "api" {
+		t.Fatalf("DeriveRepoKeyFromRelative() = %q, %q, %q, %t", key, rel, name, ok)
+	}
+	if _, _, _, ok := DeriveRepoKeyFromRelative(catalog, "you/api"); ok {
+		t.Fatal("expected depth-2 path to be rejected by a 3-segment layout")
+	}
+	if err := ValidateCatalogLayout(Catalog{Layout: "{owner}"}); err == nil {
+		t.Fatal("expected ValidateCatalogLayout to reject a layout without {repo}")
+	}
+}
@@ -22,6 +22,18 @@ func ValidateRepoPathDepth(depth int) error {
 	return fmt.Errorf("repo_path_depth must be 1 or 2")
 }
 
+// ValidateCatalogLayout checks the catalog's layout and repo_path_depth.
+func ValidateCatalogLayout(c Catalog) error {
+	if err := ValidateRepoPathDepth(c.RepoPathDepth); err != nil {
+		return err
+	}
+	if strings.TrimSpace(c.Layout) == "" {
+		return nil
+	}
+	_, err := ParseCatalogLayout(c.Layout)
+	return err
+}
+
 func DeriveRepoKey(catalog Catalog, repoPath string) (repoKey string, relativePath string, repoName string, ok bool) {
 	relativePath, ok = relativePathUnderRoot(repoPath, catalog.Root)
 	if !ok {
@@ -36,7 +48,7 @@ func DeriveRepoKeyFromRelative(catalog Catalog, relativePath string) (repoKey st
 		return "", "", "", false
 	}
 	parts := splitPathSegments(relativePath)
-	if len(parts) != EffectiveRepoPathDepth(catalog) {
+	if len(parts) == 0 || !EffectiveCatalogLayout(catalog).Matches(parts) {
 		return "", "", "", false
 	}
 	normalizedRelativePath = strings.Join(parts, "/")
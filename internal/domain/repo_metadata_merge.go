@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+)
+
+// MergeRepoMetadata three-way merges two edits of the same repo metadata file.
+// Each field takes the local value when local changed it relative to base and
+// the remote value otherwise, so concurrent edits to different fields both
+// survive. The push-access fields move together because they describe a single
+// probe result; when both sides changed them, a manual override beats a probe.
+// PreviousRepoKeys is the union of both sides.
+//
+// With a zero base this becomes a two-way merge that fills fields unset in
+// local from remote, which is how conflict copies without history are merged.
+func MergeRepoMetadata(base, local, remote RepoMetadataFile) RepoMetadataFile {
+	out := RepoMetadataFile{
+		Version:                  max(local.Version, remote.Version),
+		RepoKey:                  mergeMetadataField(base.RepoKey, local.RepoKey, remote.RepoKey),
+		Name:                     mergeMetadataField(base.Name, local.Name, remote.Name),
+		OriginURL:                mergeMetadataField(base.OriginURL, local.OriginURL, remote.OriginURL),
+		Visibility:               mergeMetadataField(base.Visibility, local.Visibility, remote.Visibility),
+		PreferredCatalog:         mergeMetadataField(base.PreferredCatalog, local.PreferredCatalog, remote.PreferredCatalog),
+		PreferredRemote:          mergeMetadataField(base.PreferredRemote, local.PreferredRemote, remote.PreferredRemote),
+		AutoPush:                 mergeMetadataField(base.AutoPush, local.AutoPush, remote.AutoPush),
+		BranchFollowEnabled:      mergeMetadataField(base.BranchFollowEnabled, local.BranchFollowEnabled, remote.BranchFollowEnabled),
+		PinnedBranch:             mergeMetadataField(base.PinnedBranch, local.PinnedBranch, remote.PinnedBranch),
+		PrimaryMachineID:         mergeMetadataField(base.PrimaryMachineID, local.PrimaryMachineID, remote.PrimaryMachineID),
+		PushAccess:               remote.PushAccess,
+		PushAccessCheckedRemote:  remote.PushAccessCheckedRemote,
+		PushAccessCheckedAt:      remote.PushAccessCheckedAt,
+		PushAccessManualOverride: remote.PushAccessManualOverride,
+	}
+	useLocal := pushAccessChanged(base, local)
+	if useLocal && pushAccessChanged(base, remote) && local.PushAccessManualOverride != remote.PushAccessManualOverride {
+		useLocal = local.PushAccessManualOverride
+	}
+	if useLocal {
+		out.PushAccess = local.PushAccess
+		out.PushAccessCheckedRemote = local.PushAccessCheckedRemote
+		out.PushAccessCheckedAt = local.PushAccessCheckedAt
+		out.PushAccessManualOverride = local.PushAccessManualOverride
+	}
+	out.PreviousRepoKeys = unionPreviousRepoKeys(out.RepoKey, local.PreviousRepoKeys, remote.PreviousRepoKeys)
+	return out
+}
+
+func mergeMetadataField[T comparable](base, local, remote T) T {
+	if local != base {
+		return local
+	}
+	return remote
+}
+
+func pushAccessChanged(base, local RepoMetadataFile) bool {
+	return local.PushAccess != base.PushAccess ||
+		local.PushAccessCheckedRemote != base.PushAccessCheckedRemote ||
+		!local.PushAccessCheckedAt.Equal(base.PushAccessCheckedAt) ||
+		local.PushAccessManualOverride != base.PushAccessManualOverride
+}
+
+func unionPreviousRepoKeys(current string, sets ...[]string) []string {
+	seen := map[string]struct{}{}
+	var out []string
+	for _, keys := range sets {
+		for _, key := range keys {
+			key = strings.TrimSpace(key)
+			if key == "" || key == current {
+				continue
+			}
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			out = append(out, key)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
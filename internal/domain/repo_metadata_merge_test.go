@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMergeRepoMetadataKeepsBothSidesEdits(t *testing.T) {
+	t.Parallel()
+
+	checkedAt := time.Date(2026, 2, 15, 12, 0, 0, 0, time.UTC)
+	base := RepoMetadataFile{
+		Version:          1,
+		RepoKey:          "software/api",
+		Name:             "api",
+		OriginURL:        "git@github.com:you/api.git",
+		Visibility:       VisibilityPrivate,
+		PreferredCatalog: "software",
+		AutoPush:         AutoPushModeDisabled,
+		PushAccess:       PushAccessUnknown,
+		PreviousRepoKeys: []string{"old/api"},
+	}
+	local := base
+	local.AutoPush = AutoPushModeEnabled
+	local.PushAccess = PushAccessReadWrite
+	local.PushAccessCheckedRemote = "origin"
+	local.PushAccessCheckedAt = checkedAt
+	local.PreviousRepoKeys = []string{"old/api", "legacy/api"}
+
+	remote := base
+	remote.Visibility = VisibilityPublic
+	remote.PreferredRemote = "upstream"
+	remote.PreviousRepoKeys = []string{"old/api", "moved/api"}
+
+	got := MergeRepoMetadata(base, local, remote)
+	if got.AutoPush != AutoPushModeEnabled {
+		t.Fatalf("auto_push = %q, want local edit", got.AutoPush)
+	}
+	if got.Visibility != VisibilityPublic || got.PreferredRemote != "upstream" {
+		t.Fatalf("remote edits lost: %#v", got)
+	}
+	if got.PushAccess != PushAccessReadWrite || got.PushAccessCheckedRemote != "origin" || !got.PushAccessCheckedAt.Equal(checkedAt) {
+		t.Fatalf("push access group = %q/%q/%v, want local probe", got.PushAccess, got.PushAccessCheckedRemote, got.PushAccessCheckedAt)
+	}
+	if want := []string{"legacy/api", "moved/api", "old/api"}; !reflect.DeepEqual(got.PreviousRepoKeys, want) {
+		t.Fatalf("previous_repo_keys = %v, want %v", got.PreviousRepoKeys, want)
+	}
+}
+
+func TestMergeRepoMetadataWithoutBaseTakesNonZeroLocalFields(t *testing.T) {
+	t.Parallel()
+
+	local := RepoMetadataFile{Version: 1, RepoKey: "software/api", Name: "api", AutoPush: AutoPushModeEnabled}
+	remote := RepoMetadataFile{Version: 1, RepoKey: "software/api", Name: "api", Visibility: VisibilityPrivate, PreviousRepoKeys: []string{"software/api"}}
+
+	got := MergeRepoMetadata(RepoMetadataFile{}, local, remote)
+	if got.AutoPush != AutoPushModeEnabled || got.Visibility != VisibilityPrivate {
+		t.Fatalf("unexpected merge: %#v", got)
+	}
+	if len(got.PreviousRepoKeys) != 0 {
+		t.Fatalf("previous_repo_keys = %v, want current key dropped", got.PreviousRepoKeys)
+	}
+}
+
+func TestMergeRepoMetadataPrefersManualPushAccessOverride(t *testing.T) {
+	t.Parallel()
+
+	probed := RepoMetadataFile{RepoKey: "software/api", PushAccess: PushAccessReadOnly, PushAccessCheckedRemote: "origin"}
+	manual := RepoMetadataFile{RepoKey: "software/api", PushAccess: PushAccessReadWrite, PushAccessManualOverride: true}
+
+	for name, got := range map[string]RepoMetadataFile{
+		"manual local":  MergeRepoMetadata(RepoMetadataFile{}, manual, probed),
+		"manual remote": MergeRepoMetadata(RepoMetadataFile{}, probed, manual),
+	} {
+		if got.PushAccess != PushAccessReadWrite || !got.PushAccessManualOverride {
+			t.Fatalf("%s: push access = %q override=%t, want manual read_write", name, got.PushAccess, got.PushAccessManualOverride)
+		}
+	}
+}
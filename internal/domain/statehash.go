@@ -24,6 +24,7 @@ func ComputeStateHash(record MachineRepoRecord) string {
 		OperationInProgress Operation          `json:"operation_in_progress"`
 		Syncable            bool               `json:"syncable"`
 		UnsyncableReasons   []UnsyncableReason `json:"unsyncable_reasons"`
+		Worktrees           []WorktreeRecord   `json:"worktrees,omitempty"`
 	}{
 		ExpectedRepoKey:     record.ExpectedRepoKey,
 		ExpectedCatalog:     record.ExpectedCatalog,
@@ -40,6 +41,7 @@ func ComputeStateHash(record MachineRepoRecord) string {
 		OperationInProgress: record.OperationInProgress,
 		Syncable:            record.Syncable,
 		UnsyncableReasons:   record.UnsyncableReasons,
+		Worktrees:           record.Worktrees,
 	}
 
 	buf, _ := json.Marshal(payload)
@@ -62,13 +62,19 @@ const (
 	ReasonRemoteFormatMismatch   UnsyncableReason = "remote_format_mismatch"
 )
 
+// Catalog is a root directory holding repositories. Layout, when set,
+// overrides RepoPathDepth (see ParseCatalogLayout); Exclude lists glob
+// patterns of catalog-relative directories discovery skips (see
+// ExcludeRules).
 type Catalog struct {
-	Name                              string `yaml:"name"`
-	Root                              string `yaml:"root"`
-	RepoPathDepth                     int    `yaml:"repo_path_depth,omitempty"`
-	AllowAutoPushDefaultBranchPrivate *bool  `yaml:"allow_auto_push_default_branch_private,omitempty"`
-	AllowAutoPushDefaultBranchPublic  *bool  `yaml:"allow_auto_push_default_branch_public,omitempty"`
-	AutoCloneOnSync                   *bool  `yaml:"auto_clone_on_sync,omitempty"`
+	Name                              string   `yaml:"name"`
+	Root                              string   `yaml:"root"`
+	RepoPathDepth                     int      `yaml:"repo_path_depth,omitempty"`
+	Layout                            string   `yaml:"layout,omitempty"`
+	AllowAutoPushDefaultBranchPrivate *bool    `yaml:"allow_auto_push_default_branch_private,omitempty"`
+	AllowAutoPushDefaultBranchPublic  *bool    `yaml:"allow_auto_push_default_branch_public,omitempty"`
+	AutoCloneOnSync                   *bool    `yaml:"auto_clone_on_sync,omitempty"`
+	Exclude                           []string `yaml:"exclude,omitempty"`
 }
 
 type ConfigFile struct {
@@ -82,17 +88,58 @@ type ConfigFile struct {
 	Scheduler      SchedulerConfig `yaml:"scheduler"`
 	Notify         NotifyConfig    `yaml:"notify"`
 	Integrations   Integrations    `yaml:"integrations"`
+	// Forges selects the hosting API per git host, keyed by host name.
+	// github.com uses GitHub without an entry.
+	Forges map[string]ForgeConfig `yaml:"forges,omitempty"`
 }
 
+const (
+	StateTransportExternal = "external"
+	StateTransportGit      = "git"
+)
+
 type StateTransport struct {
-	Mode string `yaml:"mode"`
+	Mode string                `yaml:"mode"`
+	Git  StateTransportGitRepo `yaml:"git,omitempty"`
+}
+
+// StateTransportGitRepo configures `state_transport.mode: git`: machines/ and
+// repos/ under the config root are committed to Branch and exchanged with
+// Remote.
+type StateTransportGitRepo struct {
+	Remote string `yaml:"remote,omitempty"`
+	Branch string `yaml:"branch,omitempty"`
 }
 
+// GitHubConfig holds the account and URL preferences used when bb creates,
+// forks or clones repositories. Host is the forge host new repositories are
+// created on and owner/repo shorthands resolve to; empty means github.com.
 type GitHubConfig struct {
 	Owner                      string `yaml:"owner"`
 	DefaultVisibility          string `yaml:"default_visibility"`
 	RemoteProtocol             string `yaml:"remote_protocol"`
 	PreferredRemoteURLTemplate string `yaml:"preferred_remote_url_template,omitempty"`
+	Host                       string `yaml:"host,omitempty"`
+}
+
+// Forge API types accepted in ForgeConfig.Type. Forgejo speaks the Gitea API.
+const (
+	ForgeTypeGitHub  = "github"
+	ForgeTypeGitLab  = "gitlab"
+	ForgeTypeGitea   = "gitea"
+	ForgeTypeForgejo = "forgejo"
+)
+
+// ForgeConfig selects the hosting API for one git host. APIURL defaults to
+// https://<host> (for GitHub, the REST root: https://api.github.com or
+// https://<host>/api/v3). TokenEnv names the environment variable holding the
+// API token and defaults to GITHUB_TOKEN, GITLAB_TOKEN or GITEA_TOKEN;
+// TokenFile is read when that variable is unset.
+type ForgeConfig struct {
+	Type      string `yaml:"type"`
+	APIURL    string `yaml:"api_url,omitempty"`
+	TokenEnv  string `yaml:"token_env,omitempty"`
+	TokenFile string `yaml:"token_file,omitempty"`
 }
 
 type CloneConfig struct {
@@ -132,9 +179,27 @@ type SchedulerConfig struct {
 }
 
 type NotifyConfig struct {
-	Enabled         bool `yaml:"enabled"`
-	Dedupe          bool `yaml:"dedupe"`
-	ThrottleMinutes int  `yaml:"throttle_minutes"`
+	Enabled         bool                  `yaml:"enabled"`
+	Dedupe          bool                  `yaml:"dedupe"`
+	Recovered       bool                  `yaml:"recovered"`
+	ThrottleMinutes int                   `yaml:"throttle_minutes"`
+	Backends        []NotifyBackendConfig `yaml:"backends,omitempty"`
+	Webhook         NotifyWebhookConfig   `yaml:"webhook,omitempty"`
+}
+
+// NotifyBackendConfig routes notifications to one backend. Reasons limits the
+// backend to records carrying at least one of the listed reasons;
+// ExcludeReasons drops the listed reasons before routing.
+type NotifyBackendConfig struct {
+	Name           string             `yaml:"name"`
+	Reasons        []UnsyncableReason `yaml:"reasons,omitempty"`
+	ExcludeReasons []UnsyncableReason `yaml:"exclude_reasons,omitempty"`
+}
+
+type NotifyWebhookConfig struct {
+	URL            string            `yaml:"url,omitempty"`
+	Headers        map[string]string `yaml:"headers,omitempty"`
+	TimeoutSeconds int               `yaml:"timeout_seconds,omitempty"`
 }
 
 type Integrations struct {
@@ -162,6 +227,8 @@ type RepoMetadataFile struct {
 	PushAccessCheckedAt      time.Time    `yaml:"push_access_checked_at,omitempty"`
 	PushAccessManualOverride bool         `yaml:"push_access_manual_override,omitempty"`
 	BranchFollowEnabled      bool         `yaml:"branch_follow_enabled"`
+	PinnedBranch             string       `yaml:"pinned_branch,omitempty"`
+	PrimaryMachineID         string       `yaml:"primary_machine_id,omitempty"`
 }
 
 type MachineFile struct {
@@ -197,10 +264,22 @@ type MachineRepoRecord struct {
 	OperationInProgress Operation          `yaml:"operation_in_progress"`
 	Syncable            bool               `yaml:"syncable"`
 	UnsyncableReasons   []UnsyncableReason `yaml:"unsyncable_reasons"`
+	Worktrees           []WorktreeRecord   `yaml:"worktrees,omitempty"`
 	StateHash           string             `yaml:"state_hash"`
 	ObservedAt          time.Time          `yaml:"observed_at"`
 }
 
+// WorktreeRecord is the local state of a linked worktree. Worktrees are
+// recorded under the repository that owns them, never as repos of their own.
+type WorktreeRecord struct {
+	Path                string    `yaml:"path"`
+	Branch              string    `yaml:"branch"`
+	HeadSHA             string    `yaml:"head_sha"`
+	HasDirtyTracked     bool      `yaml:"has_dirty_tracked"`
+	HasUntracked        bool      `yaml:"has_untracked"`
+	OperationInProgress Operation `yaml:"operation_in_progress"`
+}
+
 type MachineRepoRecordWithMachine struct {
 	MachineID string
 	Record    MachineRepoRecord
@@ -228,6 +307,21 @@ type NotifyCacheFile struct {
 	DeliveryFailures map[string]NotifyDeliveryFailure `yaml:"delivery_failures,omitempty"`
 }
 
+// ScanCacheFile is the local, never-synced record of each repo's on-disk
+// fingerprint when it was last fully observed by a scan.
+type ScanCacheFile struct {
+	Version int `yaml:"version"`
+	// Context fingerprints the config and shared metadata the observations
+	// depended on; a different context invalidates every entry.
+	Context string                    `yaml:"context"`
+	Repos   map[string]ScanCacheEntry `yaml:"repos"`
+}
+
+type ScanCacheEntry struct {
+	Fingerprint string    `yaml:"fingerprint"`
+	ObservedAt  time.Time `yaml:"observed_at"`
+}
+
 type NotifyCacheEntry struct {
 	Fingerprint string    `yaml:"fingerprint"`
 	SentAt      time.Time `yaml:"sent_at"`
@@ -242,3 +336,29 @@ type NotifyDeliveryFailure struct {
 	Error       string    `yaml:"error"`
 	FailedAt    time.Time `yaml:"failed_at"`
 }
+
+// StateConflictLogFile records how sync-tool conflict copies of shared state
+// files were resolved, so `bb doctor` can report it later.
+type StateConflictLogFile struct {
+	Version     int                       `yaml:"version"`
+	Resolutions []StateConflictResolution `yaml:"resolutions"`
+}
+
+type StateConflictResolution struct {
+	ResolvedAt   time.Time `yaml:"resolved_at"`
+	Kind         string    `yaml:"kind"`
+	Target       string    `yaml:"target"`
+	ConflictCopy string    `yaml:"conflict_copy"`
+	Action       string    `yaml:"action"`
+	ArchivedTo   string    `yaml:"archived_to"`
+}
+
+const (
+	StateConflictKindMachine = "machine"
+	StateConflictKindRepo    = "repo"
+
+	StateConflictKeptOriginal = "kept_original"
+	StateConflictUsedCopy     = "used_conflict_copy"
+	StateConflictMerged       = "merged"
+	StateConflictUnreadable   = "discarded_unreadable"
+)
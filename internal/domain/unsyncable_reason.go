@@ -1,5 +1,32 @@
 package domain
 
+var knownUnsyncableReasons = map[UnsyncableReason]struct{}{
+	ReasonMissingOrigin:          {},
+	ReasonOperationInProgress:    {},
+	ReasonDirtyTracked:           {},
+	ReasonDirtyUntracked:         {},
+	ReasonMissingUpstream:        {},
+	ReasonDiverged:               {},
+	ReasonPushPolicyBlocked:      {},
+	ReasonPushAccessBlocked:      {},
+	ReasonPushFailed:             {},
+	ReasonPullFailed:             {},
+	ReasonSyncConflict:           {},
+	ReasonSyncProbeFailed:        {},
+	ReasonCheckoutFailed:         {},
+	ReasonTargetPathNonRepo:      {},
+	ReasonTargetPathRepoMismatch: {},
+	ReasonCloneRequired:          {},
+	ReasonCatalogNotMapped:       {},
+	ReasonCatalogMismatch:        {},
+	ReasonRemoteFormatMismatch:   {},
+}
+
+func IsKnownUnsyncableReason(reason UnsyncableReason) bool {
+	_, ok := knownUnsyncableReasons[reason]
+	return ok
+}
+
 func IsBlockingUnsyncableReason(reason UnsyncableReason) bool {
 	switch reason {
 	case ReasonCloneRequired, ReasonCatalogNotMapped, ReasonCatalogMismatch, ReasonRemoteFormatMismatch:
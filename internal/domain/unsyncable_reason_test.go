@@ -21,3 +21,14 @@ func TestHasBlockingUnsyncableReason(t *testing.T) {
 		t.Fatal("expected dirty_tracked to be blocking")
 	}
 }
+
+func TestIsKnownUnsyncableReason(t *testing.T) {
+	t.Parallel()
+
+	if !IsKnownUnsyncableReason(ReasonDiverged) {
+		t.Fatal("expected diverged to be known")
+	}
+	if IsKnownUnsyncableReason(UnsyncableReason("dirty")) {
+		t.Fatal("did not expect dirty to be known")
+	}
+}
@@ -1,31 +1,155 @@
 package domain
 
-import "time"
+import (
+	"strings"
+	"time"
+)
+
+// WinnerReason explains why a record won winner selection.
+type WinnerReason string
+
+const (
+	WinnerReasonOnlyCandidate WinnerReason = "only_candidate"
+	// WinnerReasonDescendant means the winner's head descends from the head of
+	// a more recently observed record on the same branch.
+	WinnerReasonDescendant        WinnerReason = "descendant"
+	WinnerReasonNewestObservedAt  WinnerReason = "newest_observed_at"
+	WinnerReasonMachineIDTieBreak WinnerReason = "machine_id_tie_break"
+	// WinnerReasonPrimaryMachine means the repo's primary_machine_id policy
+	// picked the winner.
+	WinnerReasonPrimaryMachine WinnerReason = "primary_machine"
+)
+
+// AncestryFunc reports whether ancestor is an ancestor of descendant. known is
+// false when the relation cannot be determined, e.g. because a commit is not
+// available locally.
+type AncestryFunc func(ancestor, descendant string) (isAncestor bool, known bool)
+
+// WinnerSelection is the result of SelectWinnerWithAncestry.
+type WinnerSelection struct {
+	Winner MachineRepoRecordWithMachine
+	Reason WinnerReason
+	// Superseded lists machines whose record was dropped because its head is
+	// an ancestor of another candidate's head on the same branch.
+	Superseded []string
+}
+
+// WinnerPolicy tunes winner selection for one repo.
+type WinnerPolicy struct {
+	// PrimaryMachineID, when set, wins outright while it has a syncable record.
+	PrimaryMachineID string
+	// IsAncestor, when non-nil, lets descendants beat newer observations.
+	IsAncestor AncestryFunc
+}
 
 func SelectWinner(records []MachineRepoRecordWithMachine) (MachineRepoRecordWithMachine, bool) {
-	var winner MachineRepoRecordWithMachine
-	var found bool
+	selection, ok := SelectWinnerWithAncestry(records, nil)
+	return selection.Winner, ok
+}
 
+// SelectWinnerWithPolicy picks the winner under a repo's policy: the primary
+// machine's syncable record if there is one, otherwise the result of
+// SelectWinnerWithAncestry.
+func SelectWinnerWithPolicy(records []MachineRepoRecordWithMachine, policy WinnerPolicy) (WinnerSelection, bool) {
+	if primary := strings.TrimSpace(policy.PrimaryMachineID); primary != "" {
+		for _, rec := range records {
+			if rec.MachineID == primary && rec.Record.Syncable {
+				return WinnerSelection{Winner: rec, Reason: WinnerReasonPrimaryMachine}, true
+			}
+		}
+	}
+	return SelectWinnerWithAncestry(records, policy.IsAncestor)
+}
+
+// SelectWinnerWithAncestry picks the syncable record to converge on. Records
+// whose head is a strict ancestor of another candidate's head on the same
+// branch are dropped first, so a machine with a skewed clock cannot win with
+// older commits. The newest ObservedAt (then lowest machine ID) wins among the
+// rest. With a nil isAncestor, or when ancestry is unknown or the histories
+// are unrelated, selection falls back to timestamps alone.
+func SelectWinnerWithAncestry(records []MachineRepoRecordWithMachine, isAncestor AncestryFunc) (WinnerSelection, bool) {
+	candidates := make([]MachineRepoRecordWithMachine, 0, len(records))
 	for _, rec := range records {
-		if !rec.Record.Syncable {
+		if rec.Record.Syncable {
+			candidates = append(candidates, rec)
+		}
+	}
+	if len(candidates) == 0 {
+		return WinnerSelection{}, false
+	}
+	if len(candidates) == 1 {
+		return WinnerSelection{Winner: candidates[0], Reason: WinnerReasonOnlyCandidate}, true
+	}
+
+	byTime, _ := newestRecord(candidates)
+	remaining := candidates
+	var superseded []string
+	if isAncestor != nil {
+		remaining = make([]MachineRepoRecordWithMachine, 0, len(candidates))
+		for i, rec := range candidates {
+			if supersededOnBranch(rec, candidates, i, isAncestor) {
+				superseded = append(superseded, rec.MachineID)
+				continue
+			}
+			remaining = append(remaining, rec)
+		}
+	}
+
+	if len(remaining) == 0 {
+		// Only a contradictory ancestry answer can drop every candidate.
+		remaining, superseded = candidates, nil
+	}
+	winner, remainingTied := newestRecord(remaining)
+	selection := WinnerSelection{Winner: winner, Superseded: superseded}
+	switch {
+	case winner.MachineID != byTime.MachineID:
+		selection.Reason = WinnerReasonDescendant
+	case remainingTied:
+		selection.Reason = WinnerReasonMachineIDTieBreak
+	default:
+		selection.Reason = WinnerReasonNewestObservedAt
+	}
+	return selection, true
+}
+
+// supersededOnBranch reports whether candidates[idx] has a head that is a
+// strict ancestor of another candidate's head on the same branch.
+func supersededOnBranch(rec MachineRepoRecordWithMachine, candidates []MachineRepoRecordWithMachine, idx int, isAncestor AncestryFunc) bool {
+	if rec.Record.HeadSHA == "" || rec.Record.Branch == "" {
+		return false
+	}
+	for j, other := range candidates {
+		if j == idx || other.Record.Branch != rec.Record.Branch {
 			continue
 		}
-		if !found {
-			winner = rec
-			found = true
+		if other.Record.HeadSHA == "" || other.Record.HeadSHA == rec.Record.HeadSHA {
 			continue
 		}
+		if ancestor, known := isAncestor(rec.Record.HeadSHA, other.Record.HeadSHA); known && ancestor {
+			return true
+		}
+	}
+	return false
+}
 
-		if rec.Record.ObservedAt.After(winner.Record.ObservedAt) {
-			winner = rec
+// newestRecord returns the record with the newest ObservedAt, breaking ties
+// by lowest machine ID, and whether a tie had to be broken for the winner.
+func newestRecord(records []MachineRepoRecordWithMachine) (MachineRepoRecordWithMachine, bool) {
+	winner := records[0]
+	tied := false
+	for _, rec := range records[1:] {
+		if newerObservedAt(rec.Record.ObservedAt, winner.Record.ObservedAt) {
+			winner, tied = rec, false
 			continue
 		}
-		if rec.Record.ObservedAt.Equal(winner.Record.ObservedAt) && rec.MachineID < winner.MachineID {
-			winner = rec
+		if rec.Record.ObservedAt.Equal(winner.Record.ObservedAt) {
+			tied = true
+			if rec.MachineID < winner.MachineID {
+				winner = rec
+			}
 		}
 	}
-
-	return winner, found
+	return winner, tied
 }
 
 func newerObservedAt(a, b time.Time) bool {
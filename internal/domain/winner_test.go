@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"reflect"
 	"testing"
 	"time"
 )
@@ -46,3 +47,144 @@ func TestSelectWinnerTieBreakByMachineID(t *testing.T) {
 		t.Fatalf("winner machine = %q, want %q", winner.MachineID, "a-machine")
 	}
 }
+
+func TestSelectWinnerWithAncestry(t *testing.T) {
+	t.Parallel()
+
+	t0 := time.Date(2026, 2, 13, 20, 31, 0, 0, time.UTC)
+	// history: a <- b <- c on main; x is unrelated.
+	parents := map[string]string{"b": "a", "c": "b"}
+	ancestry := func(ancestor, descendant string) (bool, bool) {
+		if ancestor == "x" || descendant == "x" {
+			return false, false
+		}
+		for sha := descendant; sha != ""; sha = parents[sha] {
+			if sha == ancestor {
+				return true, true
+			}
+		}
+		return false, true
+	}
+	rec := func(machine, branch, head string, observed time.Time) MachineRepoRecordWithMachine {
+		return MachineRepoRecordWithMachine{
+			MachineID: machine,
+			Record:    MachineRepoRecord{Syncable: true, Branch: branch, HeadSHA: head, ObservedAt: observed},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		records        []MachineRepoRecordWithMachine
+		ancestry       AncestryFunc
+		wantMachine    string
+		wantReason     WinnerReason
+		wantSuperseded []string
+	}{
+		{
+			name:        "single candidate",
+			records:     []MachineRepoRecordWithMachine{rec("m1", "main", "a", t0)},
+			ancestry:    ancestry,
+			wantMachine: "m1",
+			wantReason:  WinnerReasonOnlyCandidate,
+		},
+		{
+			name: "descendant beats skewed newer clock",
+			records: []MachineRepoRecordWithMachine{
+				rec("skewed", "main", "a", t0.Add(time.Hour)),
+				rec("ahead", "main", "c", t0),
+			},
+			ancestry:       ancestry,
+			wantMachine:    "ahead",
+			wantReason:     WinnerReasonDescendant,
+			wantSuperseded: []string{"skewed"},
+		},
+		{
+			name: "newest is also descendant",
+			records: []MachineRepoRecordWithMachine{
+				rec("m1", "main", "a", t0),
+				rec("m2", "main", "b", t0.Add(time.Minute)),
+			},
+			ancestry:       ancestry,
+			wantMachine:    "m2",
+			wantReason:     WinnerReasonNewestObservedAt,
+			wantSuperseded: []string{"m1"},
+		},
+		{
+			name: "unknown ancestry falls back to timestamps",
+			records: []MachineRepoRecordWithMachine{
+				rec("m1", "main", "c", t0),
+				rec("m2", "main", "x", t0.Add(time.Minute)),
+			},
+			ancestry:    ancestry,
+			wantMachine: "m2",
+			wantReason:  WinnerReasonNewestObservedAt,
+		},
+		{
+			name: "different branches ignore ancestry",
+			records: []MachineRepoRecordWithMachine{
+				rec("m1", "feature", "a", t0.Add(time.Minute)),
+				rec("m2", "main", "c", t0),
+			},
+			ancestry:    ancestry,
+			wantMachine: "m1",
+			wantReason:  WinnerReasonNewestObservedAt,
+		},
+		{
+			name: "nil ancestry uses timestamps",
+			records: []MachineRepoRecordWithMachine{
+				rec("m1", "main", "a", t0.Add(time.Minute)),
+				rec("m2", "main", "c", t0),
+			},
+			wantMachine: "m1",
+			wantReason:  WinnerReasonNewestObservedAt,
+		},
+		{
+			name: "tie broken by machine id",
+			records: []MachineRepoRecordWithMachine{
+				rec("m2", "main", "c", t0),
+				rec("m1", "main", "c", t0),
+			},
+			ancestry:    ancestry,
+			wantMachine: "m1",
+			wantReason:  WinnerReasonMachineIDTieBreak,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := SelectWinnerWithAncestry(tt.records, tt.ancestry)
+			if !ok {
+				t.Fatal("expected winner")
+			}
+			if got.Winner.MachineID != tt.wantMachine || got.Reason != tt.wantReason {
+				t.Fatalf("winner = %s (%s), want %s (%s)", got.Winner.MachineID, got.Reason, tt.wantMachine, tt.wantReason)
+			}
+			if !reflect.DeepEqual(got.Superseded, tt.wantSuperseded) {
+				t.Fatalf("superseded = %v, want %v", got.Superseded, tt.wantSuperseded)
+			}
+		})
+	}
+}
+
+func TestSelectWinnerWithPolicyPrefersSyncablePrimary(t *testing.T) {
+	t.Parallel()
+
+	t0 := time.Date(2026, 2, 13, 20, 31, 0, 0, time.UTC)
+	recs := []MachineRepoRecordWithMachine{
+		{MachineID: "laptop", Record: MachineRepoRecord{Syncable: true, ObservedAt: t0.Add(time.Hour), Branch: "feature"}},
+		{MachineID: "desktop", Record: MachineRepoRecord{Syncable: true, ObservedAt: t0, Branch: "main"}},
+	}
+
+	got, ok := SelectWinnerWithPolicy(recs, WinnerPolicy{PrimaryMachineID: "desktop"})
+	if !ok || got.Winner.MachineID != "desktop" || got.Reason != WinnerReasonPrimaryMachine {
+		t.Fatalf("winner = %s (%s), want desktop (primary_machine)", got.Winner.MachineID, got.Reason)
+	}
+
+	recs[1].Record.Syncable = false
+	got, ok = SelectWinnerWithPolicy(recs, WinnerPolicy{PrimaryMachineID: "desktop"})
+	if !ok || got.Winner.MachineID != "laptop" || got.Reason != WinnerReasonOnlyCandidate {
+		t.Fatalf("winner = %s (%s), want laptop fallback", got.Winner.MachineID, got.Reason)
+	}
+}
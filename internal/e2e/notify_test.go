@@ -133,4 +133,30 @@ func TestNotifyCases(t *testing.T) {
 			t.Fatalf("expected notification when throttle disabled, got: %s", out)
 		}
 	})
+
+	t.Run("TC-NOTIFY-008", func(t *testing.T) {
+		t.Parallel()
+		_, m, catalogRoot := setupSingleMachine(t)
+		repoPath, _ := createRepoWithOrigin(t, m, catalogRoot, "api", now)
+		m.MustWriteFile(filepath.Join(repoPath, "README.md"), "dirty\n")
+
+		_, _ = m.RunBB(now.Add(1*time.Minute), "sync", "--notify")
+		m.MustRunGit(now, repoPath, "checkout", "--", "README.md")
+		// A scan records the recovery before the next sync sees it.
+		if out, err := m.RunBB(now.Add(2*time.Minute), "scan"); err != nil {
+			t.Fatalf("scan failed: %v\n%s", err, out)
+		}
+		if out, err := m.RunBB(now.Add(3*time.Minute), "sync", "--notify"); err != nil {
+			t.Fatalf("expected syncable run, got err=%v output=%s", err, out)
+		}
+		m.MustWriteFile(filepath.Join(repoPath, "README.md"), "dirty again\n")
+
+		out, err := m.RunBB(now.Add(4*time.Minute), "sync", "--notify")
+		if err == nil {
+			t.Fatalf("expected unsyncable sync, output=%s", out)
+		}
+		if !strings.Contains(out, "notify api") {
+			t.Fatalf("expected the regression to notify after a scan recorded the recovery, got: %s", out)
+		}
+	})
 }
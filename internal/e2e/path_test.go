@@ -1,6 +1,7 @@
 package e2e
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
 	"strings"
@@ -8,6 +9,7 @@ import (
 	"time"
 
 	"bb-project/internal/domain"
+	"bb-project/internal/state"
 	"bb-project/internal/testharness"
 )
 
@@ -180,4 +182,32 @@ func TestPathCases(t *testing.T) {
 			t.Fatalf("expected clone after fix action, stat .git: %v", err)
 		}
 	})
+
+	t.Run("TC-PATH-008", func(t *testing.T) {
+		t.Parallel()
+		_, _, mB, _, targetPath, now := setupSourceRepoForClone(t)
+		machinePath := state.NewPaths(mB.Home).MachinePath(mB.ID)
+		before, err := os.ReadFile(machinePath)
+		if err != nil {
+			t.Fatalf("read machine file: %v", err)
+		}
+
+		out, err := mB.RunBB(now.Add(1*time.Minute), "sync", "--dry-run")
+		if err != nil {
+			t.Fatalf("sync --dry-run failed: %v\n%s", err, out)
+		}
+		if !strings.Contains(out, "clone_required") {
+			t.Fatalf("expected clone_required skip in plan, got: %s", out)
+		}
+		after, err := os.ReadFile(machinePath)
+		if err != nil {
+			t.Fatalf("read machine file: %v", err)
+		}
+		if !bytes.Equal(before, after) {
+			t.Fatalf("sync --dry-run rewrote the machine file:\nbefore:\n%s\nafter:\n%s", before, after)
+		}
+		if _, err := os.Stat(targetPath); !os.IsNotExist(err) {
+			t.Fatalf("sync --dry-run touched %s: %v", targetPath, err)
+		}
+	})
 }
@@ -80,15 +80,12 @@ func TestScanCases(t *testing.T) {
 		}
 
 		mf := loadMachineFile(t, m)
-		found := false
-		for _, rec := range mf.Repos {
-			if rec.Path == worktreePath {
-				found = true
-				break
-			}
-		}
-		if !found {
-			t.Fatalf("expected worktree repo at %q to be discovered, got repos=%+v", worktreePath, mf.Repos)
+		if len(mf.Repos) != 1 || mf.Repos[0].Path != repoPath {
+			t.Fatalf("expected worktree to be recorded under its repository only, got repos=%+v", mf.Repos)
+		}
+		worktrees := mf.Repos[0].Worktrees
+		if len(worktrees) != 1 || worktrees[0].Path != worktreePath || worktrees[0].Branch != "worktree-branch" {
+			t.Fatalf("expected worktree %q on worktree-branch under the repo, got %+v", worktreePath, worktrees)
 		}
 	})
 
@@ -364,20 +364,8 @@ func (r Runner) Dirty(path string) (tracked bool, untracked bool, err error) {
 }
 
 func (r Runner) Operation(path string) domain.Operation {
-	gitDir := filepath.Join(path, ".git")
-	if hasFile(filepath.Join(gitDir, "MERGE_HEAD")) {
-		return domain.OperationMerge
-	}
-	if hasDir(filepath.Join(gitDir, "rebase-apply")) || hasDir(filepath.Join(gitDir, "rebase-merge")) {
-		return domain.OperationRebase
-	}
-	if hasFile(filepath.Join(gitDir, "CHERRY_PICK_HEAD")) {
-		return domain.OperationCherryPick
-	}
-	if hasDir(filepath.Join(gitDir, "BISECT_LOG")) || hasFile(filepath.Join(gitDir, "BISECT_LOG")) {
-		return domain.OperationBisect
-	}
-	return domain.OperationNone
+	gitDir, _ := resolveGitDirs(path)
+	return operationInGitDir(gitDir)
 }
 
 func hasFile(path string) bool {
@@ -647,6 +635,21 @@ func (r Runner) HasStagedChanges(path string) (bool, error) {
 	return false, err
 }
 
+// IsAncestor reports whether ancestor is an ancestor of (or equal to)
+// descendant. It returns an error when either commit is not in the local
+// object store.
+func (r Runner) IsAncestor(path, ancestor, descendant string) (bool, error) {
+	_, err := r.run(path, "git", "merge-base", "--is-ancestor", ancestor, descendant)
+	if err == nil {
+		return true, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	return false, err
+}
+
 func (r Runner) StashStaged(path string, message string) error {
 	args := []string{"stash", "push", "--staged"}
 	if strings.TrimSpace(message) != "" {
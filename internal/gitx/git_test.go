@@ -242,3 +242,42 @@ func TestRenameCurrentBranch(t *testing.T) {
 		t.Fatalf("current branch = %q, want %q", branch, "feature/rename-check")
 	}
 }
+
+func TestIsAncestor(t *testing.T) {
+	t.Parallel()
+
+	repoPath := t.TempDir()
+	r := Runner{}
+	if err := r.InitRepo(repoPath); err != nil {
+		t.Fatalf("init repo failed: %v", err)
+	}
+	commit := func(content string) string {
+		t.Helper()
+		if err := os.WriteFile(repoPath+"/README.md", []byte(content), 0o644); err != nil {
+			t.Fatalf("write file failed: %v", err)
+		}
+		if err := r.AddAll(repoPath); err != nil {
+			t.Fatalf("git add failed: %v", err)
+		}
+		if err := r.Commit(repoPath, content); err != nil {
+			t.Fatalf("git commit failed: %v", err)
+		}
+		sha, err := r.HeadSHA(repoPath)
+		if err != nil {
+			t.Fatalf("head sha failed: %v", err)
+		}
+		return sha
+	}
+	first := commit("one\n")
+	second := commit("two\n")
+
+	if ok, err := r.IsAncestor(repoPath, first, second); err != nil || !ok {
+		t.Fatalf("IsAncestor(first, second) = %t, %v; want true", ok, err)
+	}
+	if ok, err := r.IsAncestor(repoPath, second, first); err != nil || ok {
+		t.Fatalf("IsAncestor(second, first) = %t, %v; want false", ok, err)
+	}
+	if _, err := r.IsAncestor(repoPath, first, "1234567890123456789012345678901234567890"); err == nil {
+		t.Fatal("IsAncestor() with unknown commit returned no error")
+	}
+}
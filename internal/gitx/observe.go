@@ -0,0 +1,367 @@
+package gitx
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"bb-project/internal/domain"
+)
+
+// Observation is the local state of a repository as read by Observe. Field
+// values match what the per-field helpers (CurrentBranch, HeadSHA, Upstream,
+// RemoteHeadSHA, AheadBehind, Dirty, Operation) return.
+type Observation struct {
+	Branch         string
+	HeadSHA        string
+	Upstream       string
+	RemoteHeadSHA  string
+	Ahead          int
+	Behind         int
+	Diverged       bool
+	DirtyTracked   bool
+	DirtyUntracked bool
+	Operation      domain.Operation
+	// RemoteDefaultBranches maps remote names to the branch their
+	// refs/remotes/<remote>/HEAD points at.
+	RemoteDefaultBranches map[string]string
+}
+
+// Observe reads a repository's branch, upstream, divergence, dirtiness and
+// in-progress operation with a single `git status --porcelain=v2 --branch`
+// call. Everything else comes from files in the git directory, so even when
+// the status call fails the returned Observation carries the operation.
+func (r Runner) Observe(path string) (Observation, error) {
+	gitDir, commonDir := resolveGitDirs(path)
+	fromFiles := Observation{Operation: operationInGitDir(gitDir)}
+
+	out, err := r.RunGit(path, "status", "--porcelain=v2", "--branch")
+	if err != nil {
+		return fromFiles, err
+	}
+	obs, err := parsePorcelainV2Status(out)
+	if err != nil {
+		return fromFiles, err
+	}
+	obs.Operation = fromFiles.Operation
+	obs.RemoteDefaultBranches = readRemoteDefaultBranches(commonDir)
+	if obs.Upstream != "" {
+		obs.RemoteHeadSHA = resolveUpstreamSHA(commonDir, obs.Upstream)
+		if obs.RemoteHeadSHA == "" {
+			// Refs stored in a format we do not read (e.g. reftable).
+			obs.RemoteHeadSHA, _ = r.RemoteHeadSHA(path)
+		}
+	}
+	return obs, nil
+}
+
+// DefaultBranch returns the default branch of preferredRemote, or of the
+// upstream's remote (then origin, then the first remote with a HEAD) when
+// preferredRemote is empty, mirroring Runner.DefaultBranch.
+func (o Observation) DefaultBranch(preferredRemote string) string {
+	if remote := strings.TrimSpace(preferredRemote); remote != "" {
+		return o.RemoteDefaultBranches[remote]
+	}
+	if remote := remoteFromUpstream(o.Upstream); remote != "" {
+		return o.RemoteDefaultBranches[remote]
+	}
+	if branch, ok := o.RemoteDefaultBranches["origin"]; ok {
+		return branch
+	}
+	remotes := make([]string, 0, len(o.RemoteDefaultBranches))
+	for remote := range o.RemoteDefaultBranches {
+		remotes = append(remotes, remote)
+	}
+	if len(remotes) == 0 {
+		return ""
+	}
+	sort.Strings(remotes)
+	return o.RemoteDefaultBranches[remotes[0]]
+}
+
+func parsePorcelainV2Status(out string) (Observation, error) {
+	var obs Observation
+	initial := false
+	hasAheadBehind := false
+	for _, line := range strings.Split(out, "\n") {
+		if line == "" {
+			continue
+		}
+		if header, ok := strings.CutPrefix(line, "# "); ok {
+			key, value, _ := strings.Cut(header, " ")
+			switch key {
+			case "branch.oid":
+				if value == "(initial)" {
+					initial = true
+				} else {
+					obs.HeadSHA = value
+				}
+			case "branch.head":
+				if value == "(detached)" {
+					obs.Branch = "HEAD"
+				} else {
+					obs.Branch = value
+				}
+			case "branch.upstream":
+				obs.Upstream = value
+			case "branch.ab":
+				fields := strings.Fields(value)
+				if len(fields) != 2 {
+					return Observation{}, fmt.Errorf("unexpected branch.ab header %q", line)
+				}
+				ahead, aheadErr := strconv.Atoi(strings.TrimPrefix(fields[0], "+"))
+				behind, behindErr := strconv.Atoi(strings.TrimPrefix(fields[1], "-"))
+				if aheadErr != nil || behindErr != nil {
+					return Observation{}, fmt.Errorf("unexpected branch.ab header %q", line)
+				}
+				obs.Ahead, obs.Behind = ahead, behind
+				hasAheadBehind = true
+			}
+			continue
+		}
+		switch line[0] {
+		case '?':
+			obs.DirtyUntracked = true
+		case '1', '2', 'u':
+			obs.DirtyTracked = true
+		}
+	}
+	if initial {
+		// An unborn branch has no HEAD to name, as with rev-parse.
+		obs.Branch = ""
+	}
+	if !hasAheadBehind {
+		// The upstream is configured but its ref is gone; @{u} does not
+		// resolve, so report no upstream.
+		obs.Upstream = ""
+	}
+	obs.Diverged = obs.Ahead > 0 && obs.Behind > 0
+	return obs, nil
+}
+
+// resolveGitDirs returns the repository's git directory and the common
+// directory holding its refs. They differ for linked worktrees, whose `.git`
+// is a file pointing into the main repository.
+func resolveGitDirs(path string) (gitDir string, commonDir string) {
+	gitDir = filepath.Join(path, ".git")
+	if raw, err := os.ReadFile(gitDir); err == nil {
+		if target, ok := strings.CutPrefix(strings.TrimSpace(string(raw)), "gitdir:"); ok {
+			gitDir = strings.TrimSpace(target)
+			if !filepath.IsAbs(gitDir) {
+				gitDir = filepath.Join(path, gitDir)
+			}
+		}
+	}
+	commonDir = gitDir
+	if raw, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = strings.TrimSpace(string(raw))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+	}
+	return filepath.Clean(gitDir), filepath.Clean(commonDir)
+}
+
+// MainWorktree reports whether path is a linked worktree (created by `git
+// worktree add`) and, if so, returns the main worktree of the repository it
+// belongs to. Worktrees of bare repositories have no main worktree, so main
+// is "" for them.
+func MainWorktree(path string) (main string, linked bool) {
+	gitDir, commonDir := resolveGitDirs(path)
+	if gitDir == commonDir {
+		return "", false
+	}
+	if filepath.Base(commonDir) != ".git" {
+		return "", true
+	}
+	return filepath.Dir(commonDir), true
+}
+
+// CommonGitDir returns the git directory shared by every worktree of the
+// repository at path: .git of the main worktree, or the bare repository.
+func CommonGitDir(path string) string {
+	_, commonDir := resolveGitDirs(path)
+	return commonDir
+}
+
+// LinkedWorktrees returns the linked worktrees registered in the repository
+// at path, sorted by path. Registrations whose worktree no longer exists
+// (what `git worktree prune` would remove) are left out.
+func LinkedWorktrees(path string) []string {
+	_, commonDir := resolveGitDirs(path)
+	out := []string{}
+	for _, adminDir := range worktreeAdminDirs(commonDir) {
+		raw, err := os.ReadFile(filepath.Join(adminDir, "gitdir"))
+		if err != nil {
+			continue
+		}
+		dotGit := strings.TrimSpace(string(raw))
+		if !filepath.IsAbs(dotGit) {
+			dotGit = filepath.Join(adminDir, dotGit)
+		}
+		worktree := filepath.Dir(filepath.Clean(dotGit))
+		if _, err := os.Stat(dotGit); err != nil {
+			continue
+		}
+		out = append(out, worktree)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// worktreeAdminDirs returns the per-worktree git directories under
+// <commonDir>/worktrees.
+func worktreeAdminDirs(commonDir string) []string {
+	root := filepath.Join(commonDir, "worktrees")
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+	out := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() {
+			out = append(out, filepath.Join(root, e.Name()))
+		}
+	}
+	return out
+}
+
+func operationInGitDir(gitDir string) domain.Operation {
+	if hasFile(filepath.Join(gitDir, "MERGE_HEAD")) {
+		return domain.OperationMerge
+	}
+	if hasDir(filepath.Join(gitDir, "rebase-apply")) || hasDir(filepath.Join(gitDir, "rebase-merge")) {
+		return domain.OperationRebase
+	}
+	if hasFile(filepath.Join(gitDir, "CHERRY_PICK_HEAD")) {
+		return domain.OperationCherryPick
+	}
+	if hasDir(filepath.Join(gitDir, "BISECT_LOG")) || hasFile(filepath.Join(gitDir, "BISECT_LOG")) {
+		return domain.OperationBisect
+	}
+	return domain.OperationNone
+}
+
+func readRemoteDefaultBranches(commonDir string) map[string]string {
+	out := map[string]string{}
+	remotesDir := filepath.Join(commonDir, "refs", "remotes")
+	entries, err := os.ReadDir(remotesDir)
+	if err != nil {
+		return out
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		raw, err := os.ReadFile(filepath.Join(remotesDir, e.Name(), "HEAD"))
+		if err != nil {
+			continue
+		}
+		target, ok := strings.CutPrefix(strings.TrimSpace(string(raw)), "ref: refs/remotes/"+e.Name()+"/")
+		if !ok || target == "" {
+			continue
+		}
+		out[e.Name()] = target
+	}
+	return out
+}
+
+// resolveUpstreamSHA resolves a short upstream name such as "origin/main"
+// from loose refs or packed-refs.
+func resolveUpstreamSHA(commonDir string, upstream string) string {
+	for _, ref := range []string{"refs/remotes/" + upstream, "refs/heads/" + upstream} {
+		if sha := resolveRef(commonDir, ref, 0); sha != "" {
+			return sha
+		}
+	}
+	return ""
+}
+
+func resolveRef(commonDir string, ref string, depth int) string {
+	if depth > 5 {
+		return ""
+	}
+	if raw, err := os.ReadFile(filepath.Join(commonDir, filepath.FromSlash(ref))); err == nil {
+		value := strings.TrimSpace(string(raw))
+		if target, ok := strings.CutPrefix(value, "ref: "); ok {
+			return resolveRef(commonDir, strings.TrimSpace(target), depth+1)
+		}
+		return value
+	}
+	f, err := os.Open(filepath.Join(commonDir, "packed-refs"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || line[0] == '#' || line[0] == '^' {
+			continue
+		}
+		sha, name, ok := strings.Cut(line, " ")
+		if ok && name == ref {
+			return sha
+		}
+	}
+	return ""
+}
+
+// Fingerprint summarizes the modification times and sizes of what git
+// rewrites when a repository's state moves: HEAD, the index, packed-refs,
+// every loose ref, the git directory itself (operation marker files), the
+// worktree root and each of its top-level entries, so an edit to a top-level
+// file or a file added to a top-level directory moves it even when the index
+// does not. The HEAD, index and git directory of every linked worktree are included
+// too, so worktree changes move the fingerprint of the repository owning
+// them. It returns "" when the git directory cannot be read.
+func Fingerprint(path string) string {
+	gitDir, commonDir := resolveGitDirs(path)
+	if _, err := os.Stat(gitDir); err != nil {
+		return ""
+	}
+	h := sha256.New()
+	stamp := func(name string) {
+		info, err := os.Stat(name)
+		if err != nil {
+			fmt.Fprintf(h, "%s:-\n", name)
+			return
+		}
+		fmt.Fprintf(h, "%s:%d:%d\n", name, info.ModTime().UnixNano(), info.Size())
+	}
+	stamp(path)
+	if entries, err := os.ReadDir(path); err == nil {
+		for _, e := range entries {
+			if e.Name() != ".git" {
+				stamp(filepath.Join(path, e.Name()))
+			}
+		}
+	}
+	stamp(gitDir)
+	stamp(filepath.Join(gitDir, "HEAD"))
+	stamp(filepath.Join(gitDir, "index"))
+	stamp(filepath.Join(commonDir, "packed-refs"))
+	for _, adminDir := range worktreeAdminDirs(commonDir) {
+		stamp(adminDir)
+		stamp(filepath.Join(adminDir, "HEAD"))
+		stamp(filepath.Join(adminDir, "index"))
+	}
+	for _, worktree := range LinkedWorktrees(path) {
+		stamp(worktree)
+	}
+	_ = filepath.WalkDir(filepath.Join(commonDir, "refs"), func(name string, _ fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		stamp(name)
+		return nil
+	})
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,282 @@
+package gitx
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"bb-project/internal/domain"
+)
+
+func TestParsePorcelainV2Status(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		out  string
+		want Observation
+	}{
+		{
+			name: "tracking branch ahead and behind with changes",
+			out: "# branch.oid 1111111111111111111111111111111111111111\n" +
+				"# branch.head main\n" +
+				"# branch.upstream origin/main\n" +
+				"# branch.ab +2 -3\n" +
+				"1 .M N... 100644 100644 100644 aaa bbb file.txt\n" +
+				"? new.txt\n",
+			want: Observation{
+				Branch:         "main",
+				HeadSHA:        "1111111111111111111111111111111111111111",
+				Upstream:       "origin/main",
+				Ahead:          2,
+				Behind:         3,
+				Diverged:       true,
+				DirtyTracked:   true,
+				DirtyUntracked: true,
+			},
+		},
+		{
+			name: "detached head",
+			out:  "# branch.oid 2222222222222222222222222222222222222222\n# branch.head (detached)\n",
+			want: Observation{Branch: "HEAD", HeadSHA: "2222222222222222222222222222222222222222"},
+		},
+		{
+			name: "unborn branch",
+			out:  "# branch.oid (initial)\n# branch.head main\n? README.md\n",
+			want: Observation{DirtyUntracked: true},
+		},
+		{
+			name: "upstream ref gone",
+			out:  "# branch.oid 3333333333333333333333333333333333333333\n# branch.head topic\n# branch.upstream origin/topic\n",
+			want: Observation{Branch: "topic", HeadSHA: "3333333333333333333333333333333333333333"},
+		},
+		{
+			name: "unmerged entry",
+			out:  "# branch.oid 4444444444444444444444444444444444444444\n# branch.head main\nu UU N... 100644 100644 100644 100644 a b c file.txt\n",
+			want: Observation{Branch: "main", HeadSHA: "4444444444444444444444444444444444444444", DirtyTracked: true},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := parsePorcelainV2Status(tt.out)
+			if err != nil {
+				t.Fatalf("parsePorcelainV2Status() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("parsePorcelainV2Status() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestObserveMatchesPerFieldHelpers(t *testing.T) {
+	t.Parallel()
+
+	runner := Runner{}
+	fixture := newGitProbeFixture(t, runner)
+	repoPath := fixture.repoPath
+
+	mustWriteFile(t, filepath.Join(repoPath, "ahead.txt"), "ahead\n")
+	if err := runner.AddAll(repoPath); err != nil {
+		t.Fatalf("stage: %v", err)
+	}
+	if err := runner.Commit(repoPath, "ahead"); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if _, err := runner.RunGit(repoPath, "remote", "set-head", "origin", "main"); err != nil {
+		t.Fatalf("set-head: %v", err)
+	}
+	if _, err := runner.RunGit(repoPath, "pack-refs", "--all"); err != nil {
+		t.Fatalf("pack-refs: %v", err)
+	}
+	mustWriteFile(t, filepath.Join(repoPath, "shared.txt"), "changed\n")
+	mustWriteFile(t, filepath.Join(repoPath, "untracked.txt"), "new\n")
+	mustWriteFile(t, filepath.Join(repoPath, ".git", "MERGE_HEAD"), "deadbeef\n")
+
+	obs, err := runner.Observe(repoPath)
+	if err != nil {
+		t.Fatalf("Observe() error = %v", err)
+	}
+
+	branch, _ := runner.CurrentBranch(repoPath)
+	head, _ := runner.HeadSHA(repoPath)
+	upstream, _ := runner.Upstream(repoPath)
+	remoteHead, _ := runner.RemoteHeadSHA(repoPath)
+	ahead, behind, diverged, _ := runner.AheadBehind(repoPath)
+	tracked, untracked, _ := runner.Dirty(repoPath)
+	defaultBranch, _ := runner.DefaultBranch(repoPath, "")
+	want := Observation{
+		Branch:                branch,
+		HeadSHA:               head,
+		Upstream:              upstream,
+		RemoteHeadSHA:         remoteHead,
+		Ahead:                 ahead,
+		Behind:                behind,
+		Diverged:              diverged,
+		DirtyTracked:          tracked,
+		DirtyUntracked:        untracked,
+		Operation:             runner.Operation(repoPath),
+		RemoteDefaultBranches: map[string]string{"origin": "main"},
+	}
+	if !reflect.DeepEqual(obs, want) {
+		t.Fatalf("Observe() = %+v, want %+v", obs, want)
+	}
+	if obs.Ahead != 1 || obs.RemoteHeadSHA == "" || obs.Operation != domain.OperationMerge {
+		t.Fatalf("fixture not exercised: %+v", obs)
+	}
+	if got := obs.DefaultBranch(""); got != defaultBranch {
+		t.Fatalf("DefaultBranch() = %q, want %q", got, defaultBranch)
+	}
+}
+
+// benchmarkRepoCount approximates a large catalog.
+const benchmarkRepoCount = 300
+
+func BenchmarkObserveSinglePass(b *testing.B) {
+	runner := Runner{}
+	repos := newBenchmarkCatalog(b, runner)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, repo := range repos {
+			if _, err := runner.Observe(repo); err != nil {
+				b.Fatalf("Observe(%s): %v", repo, err)
+			}
+		}
+	}
+}
+
+func BenchmarkObservePerFieldHelpers(b *testing.B) {
+	runner := Runner{}
+	repos := newBenchmarkCatalog(b, runner)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, repo := range repos {
+			_, _ = runner.CurrentBranch(repo)
+			_, _ = runner.HeadSHA(repo)
+			_, _ = runner.Upstream(repo)
+			_, _ = runner.RemoteHeadSHA(repo)
+			_, _, _, _ = runner.AheadBehind(repo)
+			_, _, _ = runner.Dirty(repo)
+			_ = runner.Operation(repo)
+			_, _ = runner.DefaultBranch(repo, "")
+		}
+	}
+}
+
+// newBenchmarkCatalog builds one repo tracking a bare remote and copies it
+// benchmarkRepoCount times.
+func newBenchmarkCatalog(b *testing.B, runner Runner) []string {
+	b.Helper()
+
+	root := b.TempDir()
+	remotePath := filepath.Join(root, "remote.git")
+	template := filepath.Join(root, "template")
+	steps := [][]string{
+		{"init", "--bare", "--initial-branch=main", remotePath},
+		{"clone", remotePath, template},
+	}
+	for _, args := range steps {
+		if _, err := runner.RunGit(root, args...); err != nil {
+			b.Fatalf("git %v: %v", args, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(template, "README.md"), []byte("bench\n"), 0o644); err != nil {
+		b.Fatalf("write README: %v", err)
+	}
+	for _, args := range [][]string{
+		{"checkout", "-B", "main"},
+		{"add", "-A"},
+		{"commit", "-m", "init"},
+		{"push", "-u", "origin", "main"},
+		{"remote", "set-head", "origin", "main"},
+	} {
+		if _, err := runner.RunGit(template, args...); err != nil {
+			b.Fatalf("git %v: %v", args, err)
+		}
+	}
+
+	repos := make([]string, 0, benchmarkRepoCount)
+	for i := 0; i < benchmarkRepoCount; i++ {
+		repo := filepath.Join(root, "catalog", fmt.Sprintf("repo-%03d", i))
+		if err := os.CopyFS(repo, os.DirFS(template)); err != nil {
+			b.Fatalf("copy template: %v", err)
+		}
+		repos = append(repos, repo)
+	}
+	return repos
+}
+
+func TestFingerprintMovesWithRefs(t *testing.T) {
+	t.Parallel()
+
+	runner := Runner{}
+	fixture := newGitProbeFixture(t, runner)
+	repoPath := fixture.repoPath
+
+	if got := Fingerprint(t.TempDir()); got != "" {
+		t.Fatalf("Fingerprint(non-repo) = %q, want empty", got)
+	}
+	before := Fingerprint(repoPath)
+	if before == "" || Fingerprint(repoPath) != before {
+		t.Fatalf("Fingerprint() not stable: %q", before)
+	}
+
+	mustWriteFile(t, filepath.Join(repoPath, "next.txt"), "next\n")
+	if err := runner.AddAll(repoPath); err != nil {
+		t.Fatalf("stage: %v", err)
+	}
+	if err := runner.Commit(repoPath, "next"); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if got := Fingerprint(repoPath); got == before {
+		t.Fatal("Fingerprint() unchanged after commit")
+	}
+
+	before = Fingerprint(repoPath)
+	mustWriteFile(t, filepath.Join(repoPath, "shared.txt"), "edited in place\n")
+	if got := Fingerprint(repoPath); got == before {
+		t.Fatal("Fingerprint() unchanged after editing a tracked file")
+	}
+}
+
+func TestWorktreesResolveToTheirMainRepository(t *testing.T) {
+	t.Parallel()
+
+	runner := Runner{}
+	fixture := newGitProbeFixture(t, runner)
+	repoPath := fixture.repoPath
+	worktreePath := filepath.Join(fixture.root, "feature-wt")
+	if _, err := runner.RunGit(repoPath, "worktree", "add", "-b", "feature", worktreePath); err != nil {
+		t.Fatalf("worktree add: %v", err)
+	}
+
+	if main, linked := MainWorktree(repoPath); linked || main != "" {
+		t.Fatalf("MainWorktree(main) = %q, %t, want not linked", main, linked)
+	}
+	if main, linked := MainWorktree(worktreePath); !linked || main != repoPath {
+		t.Fatalf("MainWorktree(worktree) = %q, %t, want %q, true", main, linked, repoPath)
+	}
+	if got := LinkedWorktrees(repoPath); !reflect.DeepEqual(got, []string{worktreePath}) {
+		t.Fatalf("LinkedWorktrees() = %v, want [%s]", got, worktreePath)
+	}
+
+	before := Fingerprint(repoPath)
+	if _, err := runner.RunGit(worktreePath, "checkout", "-b", "feature-2"); err != nil {
+		t.Fatalf("checkout in worktree: %v", err)
+	}
+	if got := Fingerprint(repoPath); got == before {
+		t.Fatal("Fingerprint() unchanged after the worktree switched branch")
+	}
+
+	if err := os.RemoveAll(worktreePath); err != nil {
+		t.Fatalf("remove worktree: %v", err)
+	}
+	if got := LinkedWorktrees(repoPath); len(got) != 0 {
+		t.Fatalf("LinkedWorktrees() after removal = %v, want none", got)
+	}
+}
@@ -0,0 +1,413 @@
+package state
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"bb-project/internal/domain"
+)
+
+const (
+	BackupDirName = "backups"
+	// BackupRetention is how many previous versions of each state file are
+	// kept.
+	BackupRetention = 5
+
+	backupIDLayout = "20060102T150405.000000000Z"
+)
+
+// backupMu serializes snapshot and prune passes; sync and scan load state
+// files from several goroutines at once.
+var backupMu sync.Mutex
+
+// BackupRoot holds rolling copies of state files. It lives under the local
+// state root so sync tools never replicate it.
+func (p Paths) BackupRoot() string {
+	return filepath.Join(p.LocalStateRoot(), BackupDirName)
+}
+
+// Backup is one saved previous version of a state file.
+type Backup struct {
+	ID        string
+	Path      string
+	CreatedAt time.Time
+	// Valid reports whether the backup parses as a state file of its kind.
+	Valid bool
+}
+
+// StateFileKind maps a path to the kind of versioned state file it holds.
+func StateFileKind(paths Paths, path string) (FileKind, bool) {
+	path = filepath.Clean(path)
+	switch {
+	case path == paths.ConfigPath():
+		return FileKindConfig, true
+	case path == paths.NotifyCachePath():
+		return FileKindNotifyCache, true
+	case filepath.Dir(path) == paths.MachineDir() && strings.HasSuffix(path, ".yaml"):
+		return FileKindMachine, true
+	case filepath.Dir(path) == paths.RepoDir() && strings.HasSuffix(path, ".yaml"):
+		return FileKindRepo, true
+	default:
+		return "", false
+	}
+}
+
+// ResolveStateFile turns a user-supplied name such as "machines/mac-a.yaml",
+// "config.yaml" or "notify-cache.yaml" into the state file's absolute path.
+func ResolveStateFile(paths Paths, name string) (string, FileKind, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", "", errors.New("state file is required")
+	}
+	candidates := []string{filepath.Clean(name)}
+	if !filepath.IsAbs(name) {
+		candidates = []string{
+			filepath.Join(paths.ConfigRoot(), name),
+			filepath.Join(paths.LocalStateRoot(), name),
+		}
+	}
+	for _, candidate := range candidates {
+		if kind, ok := StateFileKind(paths, candidate); ok {
+			return candidate, kind, nil
+		}
+	}
+	return "", "", fmt.Errorf("%s is not a bb state file (want config.yaml, notify-cache.yaml, machines/<id>.yaml, or repos/<name>.yaml)", name)
+}
+
+func backupDir(paths Paths, path string) string {
+	for _, root := range []string{paths.ConfigRoot(), paths.LocalStateRoot()} {
+		if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.Join(paths.BackupRoot(), rel)
+		}
+	}
+	return filepath.Join(paths.BackupRoot(), filepath.Base(path))
+}
+
+// ListBackups returns the backups of a state file, newest first.
+func ListBackups(paths Paths, path string) ([]Backup, error) {
+	kind, ok := StateFileKind(paths, path)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a bb state file", path)
+	}
+	backups, err := listBackupFiles(paths, path)
+	if err != nil {
+		return nil, err
+	}
+	for i := range backups {
+		raw, err := os.ReadFile(backups[i].Path)
+		backups[i].Valid = err == nil && stateFileValid(kind, raw)
+	}
+	return backups, nil
+}
+
+// listBackupFiles lists the backups of path, newest first, from their names
+// alone; Valid is left unset.
+func listBackupFiles(paths Paths, path string) ([]Backup, error) {
+	dir := backupDir(paths, path)
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out []Backup
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".yaml")
+		if e.IsDir() || !ok {
+			continue
+		}
+		createdAt, err := time.Parse(backupIDLayout, id)
+		if err != nil {
+			continue
+		}
+		out = append(out, Backup{
+			ID:        id,
+			Path:      filepath.Join(dir, e.Name()),
+			CreatedAt: createdAt,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID > out[j].ID })
+	return out, nil
+}
+
+// RestoreBackup replaces a state file with one of its backups; id "latest"
+// picks the newest valid backup. The current file is backed up first when it
+// is still valid, so a restore can itself be undone.
+func RestoreBackup(paths Paths, path, id string) (Backup, error) {
+	backups, err := ListBackups(paths, path)
+	if err != nil {
+		return Backup{}, err
+	}
+	var chosen *Backup
+	for i := range backups {
+		if (id == "latest" && backups[i].Valid) || backups[i].ID == id {
+			chosen = &backups[i]
+			break
+		}
+	}
+	if chosen == nil {
+		return Backup{}, fmt.Errorf("no backup %q for %s", id, path)
+	}
+	if !chosen.Valid {
+		return Backup{}, fmt.Errorf("backup %s of %s does not parse", chosen.ID, path)
+	}
+	raw, err := os.ReadFile(chosen.Path)
+	if err != nil {
+		return Backup{}, err
+	}
+	if err := writeStateFileBytes(paths, path, raw); err != nil {
+		return Backup{}, err
+	}
+	return *chosen, nil
+}
+
+// saveStateYAML writes a versioned state file atomically after keeping a
+// backup of the version it replaces.
+func saveStateYAML(paths Paths, path string, in any) error {
+	b, err := yaml.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return writeStateFileBytes(paths, path, b)
+}
+
+// WriteStateFile replaces a state file with b atomically, keeping the version
+// it replaces as a backup when that version parses.
+func WriteStateFile(paths Paths, path string, b []byte) error {
+	return writeStateFileBytes(paths, path, b)
+}
+
+func writeStateFileBytes(paths Paths, path string, b []byte) error {
+	if current, err := os.ReadFile(path); err == nil && !bytes.Equal(current, b) {
+		if err := backupStateFile(paths, path, current); err != nil {
+			return fmt.Errorf("back up %s: %w", path, err)
+		}
+	}
+	return writeFileBytes(path, b)
+}
+
+// loadStateYAML loads a versioned state file, falling back to the newest
+// backup that parses when the file itself is corrupt, truncated, or empty;
+// BackupFallbacks reports such files.
+func loadStateYAML(paths Paths, kind FileKind, path string, out any) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	loadErr := loadVersionedYAML(kind, path, out)
+	if loadErr == nil && stateFileValid(kind, raw) {
+		return nil
+	}
+	if _, ok := loadNewestValidBackup(paths, kind, path, out); ok {
+		return nil
+	}
+	// Without a usable backup, a file that parses but lacks its identity is
+	// left to callers, which already skip or reject such files.
+	return loadErr
+}
+
+// loadNewestValidBackup decodes the newest backup of path that parses into
+// out and returns it.
+func loadNewestValidBackup(paths Paths, kind FileKind, path string, out any) (Backup, bool) {
+	backups, err := ListBackups(paths, path)
+	if err != nil {
+		return Backup{}, false
+	}
+	for _, backup := range backups {
+		if !backup.Valid {
+			continue
+		}
+		if err := loadVersionedYAML(kind, backup.Path, out); err == nil {
+			return backup, true
+		}
+	}
+	return Backup{}, false
+}
+
+// SnapshotStateFiles backs up every state file that differs from its newest
+// backup. Saves back up the version they replace, but files written by other
+// machines arrive without one; a command calls this once, under the lock,
+// after such files may have changed. Files that cannot be backed up are
+// skipped and reported in the returned error.
+func SnapshotStateFiles(paths Paths) error {
+	files, err := versionedStateFiles(paths)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, file := range files {
+		raw, err := os.ReadFile(file.path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := backupStateFile(paths, file.path, raw); err != nil {
+			errs = append(errs, fmt.Errorf("back up %s: %w", file.path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// BackupFallback is a state file that does not parse and is read from one of
+// its backups instead. The file is replaced on the next save of its kind.
+type BackupFallback struct {
+	Path   string
+	Kind   FileKind
+	Backup Backup
+}
+
+// BackupFallbacks lists the state files that loads currently serve from a
+// backup because the file itself is corrupt, truncated, or empty.
+func BackupFallbacks(paths Paths) ([]BackupFallback, error) {
+	files, err := versionedStateFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+	var out []BackupFallback
+	for _, file := range files {
+		raw, err := os.ReadFile(file.path)
+		if err != nil {
+			return nil, err
+		}
+		if loadVersionedYAML(file.kind, file.path, stateDocument(file.kind)) == nil && stateFileValid(file.kind, raw) {
+			continue
+		}
+		if backup, ok := loadNewestValidBackup(paths, file.kind, file.path, stateDocument(file.kind)); ok {
+			out = append(out, BackupFallback{Path: file.path, Kind: file.kind, Backup: backup})
+		}
+	}
+	return out, nil
+}
+
+// stateDocument returns a pointer to the struct files of kind decode into.
+func stateDocument(kind FileKind) any {
+	switch kind {
+	case FileKindConfig:
+		return &domain.ConfigFile{}
+	case FileKindMachine:
+		return &domain.MachineFile{}
+	case FileKindRepo:
+		return &domain.RepoMetadataFile{}
+	case FileKindNotifyCache:
+		return &domain.NotifyCacheFile{}
+	default:
+		return &map[string]any{}
+	}
+}
+
+// backupStateFile stores raw as the newest backup of path unless it matches
+// the newest backup already, then prunes to BackupRetention entries. Backup
+// IDs follow paths.Now and always sort after the newest existing backup.
+func backupStateFile(paths Paths, path string, raw []byte) error {
+	kind, ok := StateFileKind(paths, path)
+	if !ok {
+		return nil
+	}
+	backupMu.Lock()
+	defer backupMu.Unlock()
+	backups, err := listBackupFiles(paths, path)
+	if err != nil {
+		return err
+	}
+	createdAt := paths.now().UTC()
+	if len(backups) > 0 {
+		if newest, err := os.ReadFile(backups[0].Path); err == nil && bytes.Equal(newest, raw) {
+			return nil
+		}
+		if !createdAt.After(backups[0].CreatedAt) {
+			createdAt = backups[0].CreatedAt.Add(time.Nanosecond)
+		}
+	}
+	if !stateFileValid(kind, raw) {
+		return nil
+	}
+	backup := Backup{ID: createdAt.Format(backupIDLayout), CreatedAt: createdAt}
+	backup.Path = filepath.Join(backupDir(paths, path), backup.ID+".yaml")
+	if err := writeFileBytes(backup.Path, raw); err != nil {
+		return err
+	}
+	backups = append([]Backup{backup}, backups...)
+	for _, stale := range backups[min(len(backups), BackupRetention):] {
+		if err := os.Remove(stale.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// stateFileValid reports whether raw is a non-empty YAML mapping that carries
+// the identity field its kind requires.
+func stateFileValid(kind FileKind, raw []byte) bool {
+	doc := map[string]any{}
+	if err := yaml.Unmarshal(raw, &doc); err != nil || len(doc) == 0 {
+		return false
+	}
+	switch kind {
+	case FileKindMachine:
+		id, _ := doc["machine_id"].(string)
+		return strings.TrimSpace(id) != ""
+	case FileKindRepo:
+		key, _ := doc["repo_key"].(string)
+		return strings.TrimSpace(key) != ""
+	default:
+		return true
+	}
+}
+
+// writeFileBytes replaces path atomically: the data is written and synced to
+// a temporary file in the same directory, which is then renamed over path.
+func writeFileBytes(path string, b []byte) error {
+	dir := filepath.Dir(path)
+	if err := EnsureDir(dir); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer func() {
+		_ = os.Remove(tmpPath)
+	}()
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	syncDir(dir)
+	return nil
+}
+
+// syncDir flushes the directory entry created by a rename. Some platforms
+// cannot fsync directories, so failures are ignored.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	_ = d.Close()
+}
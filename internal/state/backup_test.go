@@ -0,0 +1,230 @@
+package state
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"bb-project/internal/domain"
+)
+
+func TestWriteFileBytesReplacesAtomically(t *testing.T) {
+	t.Parallel()
+
+	paths := NewPaths(t.TempDir())
+	path := paths.MachinePath("mac-a")
+	if err := writeFileBytes(path, []byte("machine_id: mac-a\n")); err != nil {
+		t.Fatalf("writeFileBytes: %v", err)
+	}
+	if err := writeFileBytes(path, []byte("machine_id: mac-a\nhostname: host-a\n")); err != nil {
+		t.Fatalf("writeFileBytes: %v", err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil || string(raw) != "machine_id: mac-a\nhostname: host-a\n" {
+		t.Fatalf("file = %q, %v", raw, err)
+	}
+	entries, err := os.ReadDir(paths.MachineDir())
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("machine dir has %d entries, want only the target (temp files leaked)", len(entries))
+	}
+}
+
+func TestSaveMachineKeepsRollingBackups(t *testing.T) {
+	t.Parallel()
+
+	paths := NewPaths(t.TempDir())
+	for i := 0; i < BackupRetention+3; i++ {
+		m := BootstrapMachine("mac-a", "host-a", time.Date(2026, 1, 1, i, 0, 0, 0, time.UTC))
+		if err := SaveMachine(paths, m); err != nil {
+			t.Fatalf("SaveMachine %d: %v", i, err)
+		}
+	}
+
+	backups, err := ListBackups(paths, paths.MachinePath("mac-a"))
+	if err != nil {
+		t.Fatalf("ListBackups: %v", err)
+	}
+	if len(backups) != BackupRetention {
+		t.Fatalf("backups = %d, want %d", len(backups), BackupRetention)
+	}
+	for i, backup := range backups {
+		if !backup.Valid {
+			t.Fatalf("backup %d invalid: %+v", i, backup)
+		}
+		if i > 0 && backups[i-1].ID <= backup.ID {
+			t.Fatalf("backups not newest first: %s before %s", backups[i-1].ID, backup.ID)
+		}
+	}
+	newest, err := os.ReadFile(backups[0].Path)
+	if err != nil {
+		t.Fatalf("read newest backup: %v", err)
+	}
+	if !strings.Contains(string(newest), "2026-01-01T06:00:00Z") {
+		t.Fatalf("newest backup = %s, want the version before the last save", newest)
+	}
+}
+
+func TestLoadMachineFallsBackToLastGoodBackup(t *testing.T) {
+	t.Parallel()
+
+	for name, corrupt := range map[string]string{
+		"unparseable": "machine_id: mac-a\nrepos: [\n",
+		"truncated":   "",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			paths := NewPaths(t.TempDir())
+			good := BootstrapMachine("mac-a", "host-a", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+			good.Repos = []domain.MachineRepoRecord{{RepoKey: "software/api", Name: "api"}}
+			if err := SaveMachine(paths, good); err != nil {
+				t.Fatalf("SaveMachine: %v", err)
+			}
+			if err := SnapshotStateFiles(paths); err != nil {
+				t.Fatalf("SnapshotStateFiles: %v", err)
+			}
+			if err := os.WriteFile(paths.MachinePath("mac-a"), []byte(corrupt), 0o644); err != nil {
+				t.Fatalf("corrupt machine: %v", err)
+			}
+
+			machines, err := LoadAllMachineFiles(paths)
+			if err != nil {
+				t.Fatalf("LoadAllMachineFiles: %v", err)
+			}
+			if len(machines) != 1 || machines[0].MachineID != "mac-a" || len(machines[0].Repos) != 1 {
+				t.Fatalf("machines = %+v, want backup contents", machines)
+			}
+			fallbacks, err := BackupFallbacks(paths)
+			if err != nil {
+				t.Fatalf("BackupFallbacks: %v", err)
+			}
+			if len(fallbacks) != 1 || fallbacks[0].Path != paths.MachinePath("mac-a") || fallbacks[0].Backup.ID == "" {
+				t.Fatalf("fallbacks = %+v, want the corrupt machine file", fallbacks)
+			}
+		})
+	}
+}
+
+func TestLoadMachineDoesNotWriteBackups(t *testing.T) {
+	t.Parallel()
+
+	paths := NewPaths(t.TempDir())
+	if err := SaveMachine(paths, BootstrapMachine("mac-a", "host-a", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))); err != nil {
+		t.Fatalf("SaveMachine: %v", err)
+	}
+	if _, err := LoadMachine(paths, "mac-a"); err != nil {
+		t.Fatalf("LoadMachine: %v", err)
+	}
+	if _, err := os.Stat(paths.BackupRoot()); !os.IsNotExist(err) {
+		t.Fatalf("load wrote backups, stat err = %v", err)
+	}
+	fallbacks, err := BackupFallbacks(paths)
+	if err != nil || len(fallbacks) != 0 {
+		t.Fatalf("BackupFallbacks = (%+v, %v), want none for a valid file", fallbacks, err)
+	}
+}
+
+func TestSnapshotStateFilesStampsBackupsWithPathsClock(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 14, 10, 0, 0, 0, time.UTC)
+	paths := NewPaths(t.TempDir())
+	paths.Now = func() time.Time { return now }
+	path := paths.MachinePath("mac-a")
+	if err := SaveMachine(paths, BootstrapMachine("mac-a", "host-a", now)); err != nil {
+		t.Fatalf("SaveMachine: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := SnapshotStateFiles(paths); err != nil {
+			t.Fatalf("SnapshotStateFiles: %v", err)
+		}
+	}
+	backups, err := ListBackups(paths, path)
+	if err != nil {
+		t.Fatalf("ListBackups: %v", err)
+	}
+	if len(backups) != 1 || !backups[0].CreatedAt.Equal(now) {
+		t.Fatalf("backups = %+v, want one stamped %s", backups, now)
+	}
+
+	// Saves at the same instant still get distinct, ordered IDs.
+	if err := SaveMachine(paths, BootstrapMachine("mac-a", "host-b", now)); err != nil {
+		t.Fatalf("SaveMachine: %v", err)
+	}
+	if err := SaveMachine(paths, BootstrapMachine("mac-a", "host-c", now)); err != nil {
+		t.Fatalf("SaveMachine: %v", err)
+	}
+	backups, err = ListBackups(paths, path)
+	if err != nil {
+		t.Fatalf("ListBackups: %v", err)
+	}
+	if len(backups) != 2 || !backups[0].CreatedAt.Equal(now.Add(time.Nanosecond)) {
+		t.Fatalf("backups = %+v, want host-b snapshot after the first", backups)
+	}
+	newest, err := os.ReadFile(backups[0].Path)
+	if err != nil || !strings.Contains(string(newest), "host-b") {
+		t.Fatalf("newest backup = %q, %v; want host-b", newest, err)
+	}
+}
+
+func TestRestoreBackupLatestAndByID(t *testing.T) {
+	t.Parallel()
+
+	paths := NewPaths(t.TempDir())
+	if err := EnsureDir(paths.RepoDir()); err != nil {
+		t.Fatalf("EnsureDir: %v", err)
+	}
+	path := RepoMetaPath(paths, "software/api")
+	for _, name := range []string{"first", "second"} {
+		if err := SaveRepoMetadata(paths, domain.RepoMetadataFile{RepoKey: "software/api", Name: name}); err != nil {
+			t.Fatalf("SaveRepoMetadata %s: %v", name, err)
+		}
+	}
+	if err := os.WriteFile(path, []byte("{{{"), 0o644); err != nil {
+		t.Fatalf("corrupt repo: %v", err)
+	}
+
+	restored, err := RestoreBackup(paths, path, "latest")
+	if err != nil {
+		t.Fatalf("RestoreBackup latest: %v", err)
+	}
+	meta, err := LoadRepoMetadata(paths, "software/api")
+	if err != nil || meta.Name != "first" {
+		t.Fatalf("after latest restore = %+v, %v; want name first", meta, err)
+	}
+
+	backups, err := ListBackups(paths, path)
+	if err != nil {
+		t.Fatalf("ListBackups: %v", err)
+	}
+	if len(backups) != 1 || backups[0].ID != restored.ID {
+		t.Fatalf("backups = %+v, corrupt file must not be backed up", backups)
+	}
+	if _, err := RestoreBackup(paths, path, "no-such-id"); err == nil {
+		t.Fatal("RestoreBackup unknown id succeeded")
+	}
+}
+
+func TestResolveStateFile(t *testing.T) {
+	t.Parallel()
+
+	paths := NewPaths(t.TempDir())
+	for name, want := range map[string]FileKind{
+		"config.yaml":            FileKindConfig,
+		"notify-cache.yaml":      FileKindNotifyCache,
+		"machines/mac-a.yaml":    FileKindMachine,
+		"repos/software__x.yaml": FileKindRepo,
+	} {
+		_, kind, err := ResolveStateFile(paths, name)
+		if err != nil || kind != want {
+			t.Fatalf("ResolveStateFile(%q) = %q, %v; want %q", name, kind, err, want)
+		}
+	}
+	if _, _, err := ResolveStateFile(paths, "lock"); err == nil {
+		t.Fatal("ResolveStateFile(lock) succeeded")
+	}
+}
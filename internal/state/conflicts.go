@@ -0,0 +1,292 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"bb-project/internal/domain"
+)
+
+const (
+	ConflictLogName     = "state-conflicts.yaml"
+	ConflictArchiveDir  = "conflicts"
+	conflictLogMaxItems = 100
+)
+
+var (
+	// Syncthing: name.sync-conflict-20260101-123456-ABCDEFG.yaml
+	syncthingConflictPattern = regexp.MustCompile(`^(.*)\.sync-conflict-\d{8}-\d{6}(?:-[A-Za-z0-9]+)?(\.[^.]*)?$`)
+	// Dropbox: name (conflicted copy).yaml, name (host's conflicted copy 2026-01-01).yaml
+	dropboxConflictPattern = regexp.MustCompile(`^(.*) \([^()]*conflicted copy[^()]*\)(\.[^.]*)?$`)
+)
+
+func (p Paths) ConflictLogPath() string {
+	return filepath.Join(p.LocalStateRoot(), ConflictLogName)
+}
+
+func (p Paths) ConflictArchiveRoot() string {
+	return filepath.Join(p.LocalStateRoot(), ConflictArchiveDir)
+}
+
+// ConflictCopyOriginal reports whether name is a sync-tool conflict copy and,
+// if so, the file name it is a copy of.
+func ConflictCopyOriginal(name string) (string, bool) {
+	for _, pattern := range []*regexp.Regexp{syncthingConflictPattern, dropboxConflictPattern} {
+		if m := pattern.FindStringSubmatch(name); m != nil && m[1] != "" {
+			return m[1] + m[2], true
+		}
+	}
+	return "", false
+}
+
+// ResolveConflictCopies folds sync-tool conflict copies in machines/ and
+// repos/ back into the file they shadow and moves the copies to a
+// timestamped archive under the local state root. Machine files keep the
+// newest UpdatedAt; repo metadata is merged field by field. Groups where the
+// original or a copy was written by a newer bb are left in place for that bb
+// to resolve.
+func ResolveConflictCopies(paths Paths, now time.Time) ([]domain.StateConflictResolution, error) {
+	archiveDir := filepath.Join(paths.ConflictArchiveRoot(), now.UTC().Format("20060102T150405Z"))
+	var out []domain.StateConflictResolution
+	for _, dir := range []struct {
+		kind     string
+		fileKind FileKind
+		path     string
+		resolve  func(paths Paths, originalPath string, copies []string) ([]string, error)
+	}{
+		{kind: domain.StateConflictKindMachine, fileKind: FileKindMachine, path: paths.MachineDir(), resolve: resolveMachineConflict},
+		{kind: domain.StateConflictKindRepo, fileKind: FileKindRepo, path: paths.RepoDir(), resolve: resolveRepoConflict},
+	} {
+		groups, err := conflictCopyGroups(dir.path)
+		if err != nil {
+			return out, err
+		}
+		originals := make([]string, 0, len(groups))
+		for original := range groups {
+			originals = append(originals, original)
+		}
+		sort.Strings(originals)
+
+		for _, original := range originals {
+			copies := groups[original]
+			tooNew, err := conflictGroupTooNew(dir.fileKind, dir.path, original, copies)
+			if err != nil {
+				return out, err
+			}
+			if tooNew {
+				continue
+			}
+			actions, err := dir.resolve(paths, filepath.Join(dir.path, original), copies)
+			if err != nil {
+				return out, err
+			}
+			for i, copyName := range copies {
+				archived := filepath.Join(archiveDir, filepath.Base(dir.path), copyName)
+				if err := moveFile(filepath.Join(dir.path, copyName), archived); err != nil {
+					return out, err
+				}
+				out = append(out, domain.StateConflictResolution{
+					ResolvedAt:   now.UTC(),
+					Kind:         dir.kind,
+					Target:       filepath.Join(filepath.Base(dir.path), original),
+					ConflictCopy: copyName,
+					Action:       actions[i],
+					ArchivedTo:   archived,
+				})
+			}
+		}
+	}
+	return out, nil
+}
+
+func conflictCopyGroups(dir string) (map[string][]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	groups := map[string][]string{}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		original, ok := ConflictCopyOriginal(e.Name())
+		if !ok || !strings.HasSuffix(original, ".yaml") {
+			continue
+		}
+		groups[original] = append(groups[original], e.Name())
+	}
+	for original := range groups {
+		sort.Strings(groups[original])
+	}
+	return groups, nil
+}
+
+// conflictGroupTooNew reports whether the original or any copy carries a
+// schema version newer than this bb writes. Decoding such a file into the
+// current structs and saving it would drop the fields this bb does not know.
+func conflictGroupTooNew(kind FileKind, dir string, original string, copies []string) (bool, error) {
+	for _, name := range append([]string{original}, copies...) {
+		err := checkWritableVersion(kind, filepath.Join(dir, name))
+		var tooNew *VersionTooNewError
+		if errors.As(err, &tooNew) {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// resolveMachineConflict keeps whichever of the original and its copies was
+// updated last. It returns one action per copy. The replaced original is kept
+// as a rolling backup.
+func resolveMachineConflict(paths Paths, originalPath string, copies []string) ([]string, error) {
+	dir := filepath.Dir(originalPath)
+	var winner *domain.MachineFile
+	winnerIndex := -1
+	if _, err := os.Stat(originalPath); err == nil {
+		var m domain.MachineFile
+		if err := loadVersionedYAML(FileKindMachine, originalPath, &m); err == nil {
+			winner = &m
+		}
+	}
+	actions := make([]string, len(copies))
+	for i, name := range copies {
+		var m domain.MachineFile
+		if err := loadVersionedYAML(FileKindMachine, filepath.Join(dir, name), &m); err != nil || m.MachineID == "" {
+			actions[i] = domain.StateConflictUnreadable
+			continue
+		}
+		actions[i] = domain.StateConflictKeptOriginal
+		if winner == nil || m.UpdatedAt.After(winner.UpdatedAt) {
+			winner = &m
+			winnerIndex = i
+		}
+	}
+	if winnerIndex < 0 {
+		return actions, nil
+	}
+	actions[winnerIndex] = domain.StateConflictUsedCopy
+	if err := checkWritableVersion(FileKindMachine, originalPath); err != nil {
+		return nil, err
+	}
+	winner.Version = MachineSchemaVersion
+	return actions, saveStateYAML(paths, originalPath, winner)
+}
+
+// resolveRepoConflict merges every readable copy into the original, keeping
+// the replaced original as a rolling backup.
+func resolveRepoConflict(paths Paths, originalPath string, copies []string) ([]string, error) {
+	dir := filepath.Dir(originalPath)
+	var merged domain.RepoMetadataFile
+	if _, err := os.Stat(originalPath); err == nil {
+		if err := loadVersionedYAML(FileKindRepo, originalPath, &merged); err != nil {
+			merged = domain.RepoMetadataFile{}
+		}
+	}
+	before := merged
+	actions := make([]string, len(copies))
+	for i, name := range copies {
+		var repo domain.RepoMetadataFile
+		if err := loadVersionedYAML(FileKindRepo, filepath.Join(dir, name), &repo); err != nil || strings.TrimSpace(repo.RepoKey) == "" {
+			actions[i] = domain.StateConflictUnreadable
+			continue
+		}
+		if merged.RepoKey != "" && repo.RepoKey != merged.RepoKey {
+			return nil, fmt.Errorf("conflict copy %s has repo_key %q, want %q", name, repo.RepoKey, merged.RepoKey)
+		}
+		// No common ancestor is available, so the original wins for fields
+		// both sides set and the copy fills in the rest.
+		merged = domain.MergeRepoMetadata(domain.RepoMetadataFile{}, merged, repo)
+		actions[i] = domain.StateConflictMerged
+	}
+	if reflect.DeepEqual(before, merged) {
+		for i := range actions {
+			if actions[i] == domain.StateConflictMerged {
+				actions[i] = domain.StateConflictKeptOriginal
+			}
+		}
+		return actions, nil
+	}
+	if err := checkWritableVersion(FileKindRepo, originalPath); err != nil {
+		return nil, err
+	}
+	merged.Version = RepoSchemaVersion
+	return actions, saveStateYAML(paths, originalPath, merged)
+}
+
+func moveFile(src, dst string) error {
+	if err := EnsureDir(filepath.Dir(dst)); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	// Rename fails across filesystems; fall back to copy and remove.
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+func LoadConflictLog(paths Paths) (domain.StateConflictLogFile, error) {
+	logPath := paths.ConflictLogPath()
+	if _, err := os.Stat(logPath); errors.Is(err, os.ErrNotExist) {
+		return domain.StateConflictLogFile{Version: 1}, nil
+	}
+	var log domain.StateConflictLogFile
+	if err := LoadYAML(logPath, &log); err != nil {
+		return domain.StateConflictLogFile{}, fmt.Errorf("parse %s: %w", logPath, err)
+	}
+	if log.Version == 0 {
+		log.Version = 1
+	}
+	return log, nil
+}
+
+// AppendConflictLog records resolutions, dropping entries older than
+// retention and keeping at most conflictLogMaxItems.
+func AppendConflictLog(paths Paths, resolutions []domain.StateConflictResolution, now time.Time, retention time.Duration) error {
+	log, err := LoadConflictLog(paths)
+	if err != nil {
+		return err
+	}
+	kept := make([]domain.StateConflictResolution, 0, len(log.Resolutions)+len(resolutions))
+	for _, r := range append(log.Resolutions, resolutions...) {
+		if now.Sub(r.ResolvedAt) > retention {
+			continue
+		}
+		kept = append(kept, r)
+	}
+	if len(kept) > conflictLogMaxItems {
+		kept = kept[len(kept)-conflictLogMaxItems:]
+	}
+	log.Version = 1
+	log.Resolutions = kept
+	return SaveYAML(paths.ConflictLogPath(), log)
+}
@@ -0,0 +1,216 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"bb-project/internal/domain"
+)
+
+func TestConflictCopyOriginal(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		want   string
+		isCopy bool
+	}{
+		{name: "mac-a.sync-conflict-20260101-123456-ABCDEFG.yaml", want: "mac-a.yaml", isCopy: true},
+		{name: "software__api.sync-conflict-20260101-123456.yaml", want: "software__api.yaml", isCopy: true},
+		{name: "mac-a (conflicted copy).yaml", want: "mac-a.yaml", isCopy: true},
+		{name: "software__api (laptop's conflicted copy 2026-01-01).yaml", want: "software__api.yaml", isCopy: true},
+		{name: "software__api.yaml"},
+		{name: "notes (copy).yaml"},
+	}
+	for _, tt := range tests {
+		got, ok := ConflictCopyOriginal(tt.name)
+		if ok != tt.isCopy || got != tt.want {
+			t.Fatalf("ConflictCopyOriginal(%q) = (%q, %t), want (%q, %t)", tt.name, got, ok, tt.want, tt.isCopy)
+		}
+	}
+}
+
+func TestResolveConflictCopiesKeepsNewestMachineFile(t *testing.T) {
+	t.Parallel()
+
+	paths := NewPaths(t.TempDir())
+	older := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	if err := SaveMachine(paths, domain.MachineFile{Version: 1, MachineID: "mac-a", Hostname: "old", UpdatedAt: older}); err != nil {
+		t.Fatalf("save machine: %v", err)
+	}
+	copyName := "mac-a.sync-conflict-20260101-110000-ABCDEFG.yaml"
+	if err := SaveYAML(filepath.Join(paths.MachineDir(), copyName), domain.MachineFile{Version: 1, MachineID: "mac-a", Hostname: "new", UpdatedAt: newer}); err != nil {
+		t.Fatalf("save conflict copy: %v", err)
+	}
+
+	now := newer.Add(time.Minute)
+	resolutions, err := ResolveConflictCopies(paths, now)
+	if err != nil {
+		t.Fatalf("ResolveConflictCopies: %v", err)
+	}
+	if len(resolutions) != 1 || resolutions[0].Action != domain.StateConflictUsedCopy || resolutions[0].Target != "machines/mac-a.yaml" {
+		t.Fatalf("resolutions = %+v", resolutions)
+	}
+	machine, err := LoadMachine(paths, "mac-a")
+	if err != nil {
+		t.Fatalf("load machine: %v", err)
+	}
+	if machine.Hostname != "new" {
+		t.Fatalf("hostname = %q, want newest copy", machine.Hostname)
+	}
+	if _, err := os.Stat(filepath.Join(paths.MachineDir(), copyName)); !os.IsNotExist(err) {
+		t.Fatalf("conflict copy still present, stat err = %v", err)
+	}
+	if _, err := os.Stat(resolutions[0].ArchivedTo); err != nil {
+		t.Fatalf("archived copy missing: %v", err)
+	}
+	backups, err := ListBackups(paths, paths.MachinePath("mac-a"))
+	if err != nil {
+		t.Fatalf("ListBackups: %v", err)
+	}
+	backedUp := false
+	for _, backup := range backups {
+		var previous domain.MachineFile
+		if err := LoadYAML(backup.Path, &previous); err == nil && previous.Hostname == "old" {
+			backedUp = true
+		}
+	}
+	if !backedUp {
+		t.Fatalf("replaced original was not backed up: %+v", backups)
+	}
+}
+
+func TestResolveConflictCopiesMergesRepoMetadata(t *testing.T) {
+	t.Parallel()
+
+	paths := NewPaths(t.TempDir())
+	checkedAt := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	if err := SaveRepoMetadata(paths, domain.RepoMetadataFile{
+		Version:             1,
+		RepoKey:             "software/api",
+		Name:                "api",
+		PreviousRepoKeys:    []string{"old/api"},
+		PushAccess:          domain.PushAccessReadOnly,
+		PushAccessCheckedAt: checkedAt,
+	}); err != nil {
+		t.Fatalf("save repo: %v", err)
+	}
+	copyName := "software__api (laptop's conflicted copy 2026-01-01).yaml"
+	if err := SaveYAML(filepath.Join(paths.RepoDir(), copyName), domain.RepoMetadataFile{
+		Version:                  1,
+		RepoKey:                  "software/api",
+		Name:                     "api",
+		PreferredRemote:          "upstream",
+		PreviousRepoKeys:         []string{"legacy/api"},
+		PushAccess:               domain.PushAccessReadWrite,
+		PushAccessManualOverride: true,
+	}); err != nil {
+		t.Fatalf("save conflict copy: %v", err)
+	}
+
+	resolutions, err := ResolveConflictCopies(paths, checkedAt.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("ResolveConflictCopies: %v", err)
+	}
+	if len(resolutions) != 1 || resolutions[0].Action != domain.StateConflictMerged {
+		t.Fatalf("resolutions = %+v", resolutions)
+	}
+	metas, err := LoadAllRepoMetadata(paths)
+	if err != nil {
+		t.Fatalf("load metadata: %v", err)
+	}
+	if len(metas) != 1 {
+		t.Fatalf("metadata count = %d, want 1", len(metas))
+	}
+	got := metas[0]
+	if got.PreferredRemote != "upstream" {
+		t.Fatalf("preferred_remote = %q, want upstream", got.PreferredRemote)
+	}
+	if !reflect.DeepEqual(got.PreviousRepoKeys, []string{"legacy/api", "old/api"}) {
+		t.Fatalf("previous_repo_keys = %v", got.PreviousRepoKeys)
+	}
+	if got.PushAccess != domain.PushAccessReadWrite || !got.PushAccessManualOverride {
+		t.Fatalf("push access = %q manual=%t, want manual read_write", got.PushAccess, got.PushAccessManualOverride)
+	}
+}
+
+func TestLoadAllMachineFilesSkipsConflictCopies(t *testing.T) {
+	t.Parallel()
+
+	paths := NewPaths(t.TempDir())
+	if err := SaveMachine(paths, domain.MachineFile{Version: 1, MachineID: "mac-a"}); err != nil {
+		t.Fatalf("save machine: %v", err)
+	}
+	if err := SaveYAML(filepath.Join(paths.MachineDir(), "mac-a (conflicted copy).yaml"), domain.MachineFile{Version: 1, MachineID: "mac-a"}); err != nil {
+		t.Fatalf("save conflict copy: %v", err)
+	}
+	machines, err := LoadAllMachineFiles(paths)
+	if err != nil {
+		t.Fatalf("LoadAllMachineFiles: %v", err)
+	}
+	if len(machines) != 1 {
+		t.Fatalf("machine count = %d, want 1", len(machines))
+	}
+}
+
+func TestAppendConflictLogDropsExpiredEntries(t *testing.T) {
+	t.Parallel()
+
+	paths := NewPaths(t.TempDir())
+	now := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	old := domain.StateConflictResolution{ResolvedAt: now.Add(-48 * time.Hour), ConflictCopy: "old"}
+	fresh := domain.StateConflictResolution{ResolvedAt: now, ConflictCopy: "fresh"}
+	if err := AppendConflictLog(paths, []domain.StateConflictResolution{old}, now.Add(-48*time.Hour), 24*time.Hour); err != nil {
+		t.Fatalf("append old: %v", err)
+	}
+	if err := AppendConflictLog(paths, []domain.StateConflictResolution{fresh}, now, 24*time.Hour); err != nil {
+		t.Fatalf("append fresh: %v", err)
+	}
+	log, err := LoadConflictLog(paths)
+	if err != nil {
+		t.Fatalf("LoadConflictLog: %v", err)
+	}
+	if len(log.Resolutions) != 1 || log.Resolutions[0].ConflictCopy != "fresh" {
+		t.Fatalf("resolutions = %+v", log.Resolutions)
+	}
+}
+
+func TestResolveConflictCopiesLeavesNewerVersionFilesUntouched(t *testing.T) {
+	t.Parallel()
+
+	paths := NewPaths(t.TempDir())
+	if err := EnsureDir(paths.MachineDir()); err != nil {
+		t.Fatalf("ensure machine dir: %v", err)
+	}
+	original := []byte("version: 99\nmachine_id: mac-a\nhostname: future\nupdated_at: 2026-01-01T10:00:00Z\nfuture_field: keep\n")
+	originalPath := paths.MachinePath("mac-a")
+	if err := os.WriteFile(originalPath, original, 0o644); err != nil {
+		t.Fatalf("write original: %v", err)
+	}
+	copyName := "mac-a.sync-conflict-20260101-110000-ABCDEFG.yaml"
+	if err := SaveYAML(filepath.Join(paths.MachineDir(), copyName), domain.MachineFile{Version: 1, MachineID: "mac-a", Hostname: "new", UpdatedAt: time.Date(2026, 1, 1, 11, 0, 0, 0, time.UTC)}); err != nil {
+		t.Fatalf("save conflict copy: %v", err)
+	}
+
+	resolutions, err := ResolveConflictCopies(paths, time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("ResolveConflictCopies: %v", err)
+	}
+	if len(resolutions) != 0 {
+		t.Fatalf("resolutions = %+v, want none for a newer-version original", resolutions)
+	}
+	got, err := os.ReadFile(originalPath)
+	if err != nil {
+		t.Fatalf("read original: %v", err)
+	}
+	if string(got) != string(original) {
+		t.Fatalf("original rewritten:\n%s", got)
+	}
+	if _, err := os.Stat(filepath.Join(paths.MachineDir(), copyName)); err != nil {
+		t.Fatalf("conflict copy should stay for a newer bb: %v", err)
+	}
+}
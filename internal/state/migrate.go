@@ -0,0 +1,301 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileKind names one family of versioned state files. Each kind has its own
+// schema version so they can evolve independently.
+type FileKind string
+
+const (
+	FileKindConfig      FileKind = "config"
+	FileKindMachine     FileKind = "machine"
+	FileKindRepo        FileKind = "repo"
+	FileKindNotifyCache FileKind = "notify_cache"
+)
+
+// Schema versions this binary reads and writes.
+const (
+	ConfigSchemaVersion      = 1
+	MachineSchemaVersion     = 1
+	RepoSchemaVersion        = 1
+	NotifyCacheSchemaVersion = 1
+)
+
+func SchemaVersion(kind FileKind) int {
+	switch kind {
+	case FileKindConfig:
+		return ConfigSchemaVersion
+	case FileKindMachine:
+		return MachineSchemaVersion
+	case FileKindRepo:
+		return RepoSchemaVersion
+	case FileKindNotifyCache:
+		return NotifyCacheSchemaVersion
+	default:
+		return 0
+	}
+}
+
+// migrationStep upgrades a raw document from version From to From+1. Steps
+// work on the decoded YAML map so fields unknown to the current structs
+// survive the upgrade.
+type migrationStep struct {
+	From    int
+	Summary string
+	Apply   func(doc map[string]any) error
+}
+
+var migrations = map[FileKind][]migrationStep{
+	FileKindConfig:      {stampVersionStep},
+	FileKindMachine:     {stampVersionStep},
+	FileKindRepo:        {stampVersionStep},
+	FileKindNotifyCache: {stampVersionStep},
+}
+
+// stampVersionStep upgrades files written before the version field existed.
+var stampVersionStep = migrationStep{
+	From:    0,
+	Summary: "add schema version",
+	Apply:   func(map[string]any) error { return nil },
+}
+
+// VersionTooNewError reports a state file written by a newer bb.
+type VersionTooNewError struct {
+	Path      string
+	Kind      FileKind
+	Version   int
+	Supported int
+}
+
+func (e *VersionTooNewError) Error() string {
+	return fmt.Sprintf("%s has %s schema version %d, but this bb supports up to %d; upgrade bb", e.Path, e.Kind, e.Version, e.Supported)
+}
+
+// MigrationPlan describes the upgrade of one state file. TooNew marks files
+// that cannot be migrated because a newer bb wrote them.
+type MigrationPlan struct {
+	Path        string
+	Kind        FileKind
+	FromVersion int
+	ToVersion   int
+	Steps       []string
+	TooNew      bool
+}
+
+// PlanMigrations inspects every versioned state file and returns the ones that
+// are not at the current schema version, in a stable order.
+func PlanMigrations(paths Paths) ([]MigrationPlan, error) {
+	files, err := versionedStateFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+	var plans []MigrationPlan
+	for _, file := range files {
+		raw, err := os.ReadFile(file.path)
+		if err != nil {
+			return nil, err
+		}
+		version, err := peekSchemaVersion(raw)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", file.path, err)
+		}
+		supported := SchemaVersion(file.kind)
+		switch {
+		case version > supported:
+			plans = append(plans, MigrationPlan{Path: file.path, Kind: file.kind, FromVersion: version, ToVersion: supported, TooNew: true})
+		case version < supported:
+			steps, err := migrationSteps(file.kind, version)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", file.path, err)
+			}
+			summaries := make([]string, 0, len(steps))
+			for _, step := range steps {
+				summaries = append(summaries, fmt.Sprintf("v%d -> v%d: %s", step.From, step.From+1, step.Summary))
+			}
+			plans = append(plans, MigrationPlan{Path: file.path, Kind: file.kind, FromVersion: version, ToVersion: supported, Steps: summaries})
+		}
+	}
+	return plans, nil
+}
+
+// ApplyMigrations rewrites every planned file at the current schema version.
+// Nothing is written when any plan is TooNew.
+func ApplyMigrations(paths Paths, plans []MigrationPlan) error {
+	for _, plan := range plans {
+		if plan.TooNew {
+			return &VersionTooNewError{Path: plan.Path, Kind: plan.Kind, Version: plan.FromVersion, Supported: plan.ToVersion}
+		}
+	}
+	for _, plan := range plans {
+		raw, err := os.ReadFile(plan.Path)
+		if err != nil {
+			return err
+		}
+		migrated, _, err := migrateDocument(plan.Kind, raw)
+		if err != nil {
+			return fmt.Errorf("migrate %s: %w", plan.Path, err)
+		}
+		if err := writeStateFileBytes(paths, plan.Path, migrated); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadVersionedYAML decodes path into out after upgrading older documents in
+// memory. Files from a newer bb are decoded as-is; the Save functions refuse
+// to overwrite them.
+func loadVersionedYAML(kind FileKind, path string, out any) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	migrated, _, err := migrateDocument(kind, raw)
+	var tooNew *VersionTooNewError
+	if errors.As(err, &tooNew) {
+		migrated = raw
+	} else if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(migrated, out)
+}
+
+// migrateDocument upgrades raw to the current version of kind. It returns raw
+// unchanged when no step applies.
+func migrateDocument(kind FileKind, raw []byte) ([]byte, bool, error) {
+	version, err := peekSchemaVersion(raw)
+	if err != nil {
+		return nil, false, err
+	}
+	supported := SchemaVersion(kind)
+	if version > supported {
+		return nil, false, &VersionTooNewError{Kind: kind, Version: version, Supported: supported}
+	}
+	if version == supported {
+		return raw, false, nil
+	}
+	steps, err := migrationSteps(kind, version)
+	if err != nil {
+		return nil, false, err
+	}
+	doc := map[string]any{}
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, false, err
+	}
+	for _, step := range steps {
+		if err := step.Apply(doc); err != nil {
+			return nil, false, fmt.Errorf("%s v%d -> v%d: %w", kind, step.From, step.From+1, err)
+		}
+		doc["version"] = step.From + 1
+	}
+	migrated, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, false, err
+	}
+	return migrated, true, nil
+}
+
+func migrationSteps(kind FileKind, from int) ([]migrationStep, error) {
+	byFrom := map[int]migrationStep{}
+	for _, step := range migrations[kind] {
+		byFrom[step.From] = step
+	}
+	var steps []migrationStep
+	for v := from; v < SchemaVersion(kind); v++ {
+		step, ok := byFrom[v]
+		if !ok {
+			return nil, fmt.Errorf("no %s migration from version %d", kind, v)
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+func peekSchemaVersion(raw []byte) (int, error) {
+	var header struct {
+		Version int `yaml:"version"`
+	}
+	if err := yaml.Unmarshal(raw, &header); err != nil {
+		return 0, err
+	}
+	return header.Version, nil
+}
+
+// checkWritableVersion refuses to overwrite a state file that a newer bb
+// wrote, so an older binary cannot downgrade shared state.
+func checkWritableVersion(kind FileKind, path string) error {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	version, err := peekSchemaVersion(raw)
+	if err != nil {
+		// An unparsable file carries no version to protect.
+		return nil
+	}
+	if supported := SchemaVersion(kind); version > supported {
+		return &VersionTooNewError{Path: path, Kind: kind, Version: version, Supported: supported}
+	}
+	return nil
+}
+
+type versionedStateFile struct {
+	kind FileKind
+	path string
+}
+
+func versionedStateFiles(paths Paths) ([]versionedStateFile, error) {
+	var files []versionedStateFile
+	for _, single := range []versionedStateFile{
+		{kind: FileKindConfig, path: paths.ConfigPath()},
+		{kind: FileKindNotifyCache, path: paths.NotifyCachePath()},
+	} {
+		if _, err := os.Stat(single.path); err == nil {
+			files = append(files, single)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+	for _, dir := range []struct {
+		kind FileKind
+		path string
+	}{
+		{kind: FileKindMachine, path: paths.MachineDir()},
+		{kind: FileKindRepo, path: paths.RepoDir()},
+	} {
+		entries, err := os.ReadDir(dir.path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(entries))
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), ".yaml") {
+				continue
+			}
+			if _, isCopy := ConflictCopyOriginal(e.Name()); isCopy {
+				continue
+			}
+			names = append(names, e.Name())
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			files = append(files, versionedStateFile{kind: dir.kind, path: filepath.Join(dir.path, name)})
+		}
+	}
+	return files, nil
+}
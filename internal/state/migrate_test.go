@@ -0,0 +1,150 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bb-project/internal/domain"
+)
+
+func TestLoadMachineUpgradesUnversionedFile(t *testing.T) {
+	t.Parallel()
+
+	paths := NewPaths(t.TempDir())
+	if err := EnsureDir(paths.MachineDir()); err != nil {
+		t.Fatalf("EnsureDir: %v", err)
+	}
+	raw := "machine_id: mac-a\nhostname: host-a\n"
+	if err := os.WriteFile(paths.MachinePath("mac-a"), []byte(raw), 0o644); err != nil {
+		t.Fatalf("write machine: %v", err)
+	}
+
+	machine, err := LoadMachine(paths, "mac-a")
+	if err != nil {
+		t.Fatalf("LoadMachine: %v", err)
+	}
+	if machine.Version != MachineSchemaVersion || machine.Hostname != "host-a" {
+		t.Fatalf("machine = %+v, want upgraded v%d", machine, MachineSchemaVersion)
+	}
+}
+
+func TestSaveRefusesToOverwriteNewerSchemaVersion(t *testing.T) {
+	t.Parallel()
+
+	paths := NewPaths(t.TempDir())
+	if err := EnsureDir(paths.RepoDir()); err != nil {
+		t.Fatalf("EnsureDir: %v", err)
+	}
+	path := RepoMetaPath(paths, "software/api")
+	newer := "version: 99\nrepo_key: software/api\nname: api\nfuture_field: keep\n"
+	if err := os.WriteFile(path, []byte(newer), 0o644); err != nil {
+		t.Fatalf("write repo: %v", err)
+	}
+
+	meta, err := LoadRepoMetadata(paths, "software/api")
+	if err != nil {
+		t.Fatalf("LoadRepoMetadata: %v", err)
+	}
+	if meta.Name != "api" {
+		t.Fatalf("name = %q, want api", meta.Name)
+	}
+
+	err = SaveRepoMetadata(paths, meta)
+	var tooNew *VersionTooNewError
+	if !errors.As(err, &tooNew) {
+		t.Fatalf("SaveRepoMetadata err = %v, want VersionTooNewError", err)
+	}
+	if tooNew.Version != 99 || tooNew.Supported != RepoSchemaVersion {
+		t.Fatalf("tooNew = %+v", tooNew)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read repo: %v", err)
+	}
+	if string(got) != newer {
+		t.Fatalf("file rewritten:\n%s", got)
+	}
+}
+
+func TestPlanAndApplyMigrations(t *testing.T) {
+	t.Parallel()
+
+	paths := NewPaths(t.TempDir())
+	if err := SaveConfig(paths, DefaultConfig()); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+	if err := SaveMachine(paths, domain.MachineFile{MachineID: "mac-a"}); err != nil {
+		t.Fatalf("SaveMachine: %v", err)
+	}
+	if err := EnsureDir(paths.RepoDir()); err != nil {
+		t.Fatalf("EnsureDir: %v", err)
+	}
+	legacy := filepath.Join(paths.RepoDir(), "software__api.yaml")
+	if err := os.WriteFile(legacy, []byte("repo_key: software/api\nname: api\nfuture_field: keep\n"), 0o644); err != nil {
+		t.Fatalf("write repo: %v", err)
+	}
+
+	plans, err := PlanMigrations(paths)
+	if err != nil {
+		t.Fatalf("PlanMigrations: %v", err)
+	}
+	if len(plans) != 1 || plans[0].Path != legacy || plans[0].FromVersion != 0 || plans[0].ToVersion != RepoSchemaVersion || len(plans[0].Steps) != 1 {
+		t.Fatalf("plans = %+v", plans)
+	}
+
+	if err := ApplyMigrations(paths, plans); err != nil {
+		t.Fatalf("ApplyMigrations: %v", err)
+	}
+	raw, err := os.ReadFile(legacy)
+	if err != nil {
+		t.Fatalf("read repo: %v", err)
+	}
+	if !strings.Contains(string(raw), "version: 1") || !strings.Contains(string(raw), "future_field: keep") {
+		t.Fatalf("migrated file = %s", raw)
+	}
+	plans, err = PlanMigrations(paths)
+	if err != nil {
+		t.Fatalf("PlanMigrations after apply: %v", err)
+	}
+	if len(plans) != 0 {
+		t.Fatalf("plans after apply = %+v, want none", plans)
+	}
+}
+
+func TestApplyMigrationsWritesNothingWhenAnyFileIsTooNew(t *testing.T) {
+	t.Parallel()
+
+	paths := NewPaths(t.TempDir())
+	if err := EnsureDir(paths.MachineDir()); err != nil {
+		t.Fatalf("EnsureDir: %v", err)
+	}
+	old := paths.MachinePath("mac-a")
+	if err := os.WriteFile(old, []byte("machine_id: mac-a\n"), 0o644); err != nil {
+		t.Fatalf("write old: %v", err)
+	}
+	if err := os.WriteFile(paths.MachinePath("mac-b"), []byte("version: 7\nmachine_id: mac-b\n"), 0o644); err != nil {
+		t.Fatalf("write newer: %v", err)
+	}
+
+	plans, err := PlanMigrations(paths)
+	if err != nil {
+		t.Fatalf("PlanMigrations: %v", err)
+	}
+	if len(plans) != 2 || plans[0].TooNew || !plans[1].TooNew {
+		t.Fatalf("plans = %+v", plans)
+	}
+	var tooNew *VersionTooNewError
+	if err := ApplyMigrations(paths, plans); !errors.As(err, &tooNew) {
+		t.Fatalf("ApplyMigrations err = %v, want VersionTooNewError", err)
+	}
+	raw, err := os.ReadFile(old)
+	if err != nil {
+		t.Fatalf("read old: %v", err)
+	}
+	if string(raw) != "machine_id: mac-a\n" {
+		t.Fatalf("old machine rewritten: %s", raw)
+	}
+}
@@ -26,16 +26,30 @@ const (
 	MachineIDFile   = "machine-id"
 	LockFileName    = "lock"
 	NotifyCacheName = "notify-cache.yaml"
+	ScanCacheName   = "scan-cache.yaml"
+
+	// ScanCacheVersion is bumped whenever cached observations stop being
+	// comparable; caches with another version are discarded.
+	ScanCacheVersion = 1
 )
 
 type Paths struct {
 	Home string
+	// Now stamps backup IDs; time.Now when nil.
+	Now func() time.Time
 }
 
 func NewPaths(home string) Paths {
 	return Paths{Home: home}
 }
 
+func (p Paths) now() time.Time {
+	if p.Now != nil {
+		return p.Now()
+	}
+	return time.Now()
+}
+
 func (p Paths) ConfigRoot() string {
 	return filepath.Join(p.Home, ConfigDirName)
 }
@@ -72,6 +86,10 @@ func (p Paths) NotifyCachePath() string {
 	return filepath.Join(p.LocalStateRoot(), NotifyCacheName)
 }
 
+func (p Paths) ScanCachePath() string {
+	return filepath.Join(p.LocalStateRoot(), ScanCacheName)
+}
+
 func EnsureDir(path string) error {
 	return os.MkdirAll(path, 0o755)
 }
@@ -131,7 +149,7 @@ func LoadConfig(paths Paths) (domain.ConfigFile, error) {
 	cfgPath := paths.ConfigPath()
 	if _, err := os.Stat(cfgPath); errors.Is(err, os.ErrNotExist) {
 		cfg := DefaultConfig()
-		if err := SaveYAML(cfgPath, cfg); err != nil {
+		if err := saveStateYAML(paths, cfgPath, cfg); err != nil {
 			return domain.ConfigFile{}, err
 		}
 		return cfg, nil
@@ -142,12 +160,9 @@ func LoadConfig(paths Paths) (domain.ConfigFile, error) {
 	// instead of inheriting seeded defaults from the in-memory template.
 	cfg.Clone.Presets = nil
 	cfg.Clone.CatalogPreset = nil
-	if err := LoadYAML(cfgPath, &cfg); err != nil {
+	if err := loadStateYAML(paths, FileKindConfig, cfgPath, &cfg); err != nil {
 		return domain.ConfigFile{}, fmt.Errorf("parse %s: %w", cfgPath, err)
 	}
-	if cfg.Version == 0 {
-		cfg.Version = 1
-	}
 	if cfg.StateTransport.Mode == "" {
 		cfg.StateTransport.Mode = "external"
 	}
@@ -180,11 +195,14 @@ func LoadConfig(paths Paths) (domain.ConfigFile, error) {
 }
 
 func SaveConfig(paths Paths, cfg domain.ConfigFile) error {
-	cfg.Version = 1
+	if err := checkWritableVersion(FileKindConfig, paths.ConfigPath()); err != nil {
+		return err
+	}
+	cfg.Version = ConfigSchemaVersion
 	if strings.TrimSpace(cfg.StateTransport.Mode) == "" {
 		cfg.StateTransport.Mode = "external"
 	}
-	return SaveYAML(paths.ConfigPath(), cfg)
+	return saveStateYAML(paths, paths.ConfigPath(), cfg)
 }
 
 func LoadMachine(paths Paths, machineID string) (domain.MachineFile, error) {
@@ -193,21 +211,25 @@ func LoadMachine(paths Paths, machineID string) (domain.MachineFile, error) {
 		return domain.MachineFile{}, os.ErrNotExist
 	}
 	var mf domain.MachineFile
-	if err := LoadYAML(path, &mf); err != nil {
+	if err := loadStateYAML(paths, FileKindMachine, path, &mf); err != nil {
 		return domain.MachineFile{}, fmt.Errorf("parse %s: %w", path, err)
 	}
 	return mf, nil
 }
 
 func SaveMachine(paths Paths, m domain.MachineFile) error {
-	m.Version = 1
+	path := paths.MachinePath(m.MachineID)
+	if err := checkWritableVersion(FileKindMachine, path); err != nil {
+		return err
+	}
+	m.Version = MachineSchemaVersion
 	m.UpdatedAt = m.UpdatedAt.UTC()
-	return SaveYAML(paths.MachinePath(m.MachineID), m)
+	return saveStateYAML(paths, path, m)
 }
 
 func BootstrapMachine(machineID, hostname string, now time.Time) domain.MachineFile {
 	return domain.MachineFile{
-		Version:        1,
+		Version:        MachineSchemaVersion,
 		MachineID:      machineID,
 		Hostname:       hostname,
 		DefaultCatalog: "",
@@ -227,11 +249,15 @@ func RepoMetaPath(paths Paths, repoKey string) string {
 }
 
 func SaveRepoMetadata(paths Paths, repo domain.RepoMetadataFile) error {
-	repo.Version = 1
+	repo.Version = RepoSchemaVersion
 	if strings.TrimSpace(repo.RepoKey) == "" {
 		return fmt.Errorf("repo_key is required")
 	}
-	return SaveYAML(RepoMetaPath(paths, repo.RepoKey), repo)
+	path := RepoMetaPath(paths, repo.RepoKey)
+	if err := checkWritableVersion(FileKindRepo, path); err != nil {
+		return err
+	}
+	return saveStateYAML(paths, path, repo)
 }
 
 func LoadRepoMetadata(paths Paths, repoKey string) (domain.RepoMetadataFile, error) {
@@ -240,7 +266,7 @@ func LoadRepoMetadata(paths Paths, repoKey string) (domain.RepoMetadataFile, err
 		return domain.RepoMetadataFile{}, err
 	}
 	var repo domain.RepoMetadataFile
-	if err := LoadYAML(path, &repo); err != nil {
+	if err := loadStateYAML(paths, FileKindRepo, path, &repo); err != nil {
 		return domain.RepoMetadataFile{}, fmt.Errorf("parse %s: %w", path, err)
 	}
 	return repo, nil
@@ -260,8 +286,11 @@ func LoadAllRepoMetadata(paths Paths) ([]domain.RepoMetadataFile, error) {
 		if e.IsDir() || !strings.HasSuffix(e.Name(), ".yaml") {
 			continue
 		}
+		if _, isCopy := ConflictCopyOriginal(e.Name()); isCopy {
+			continue
+		}
 		var repo domain.RepoMetadataFile
-		if err := LoadYAML(filepath.Join(dir, e.Name()), &repo); err != nil {
+		if err := loadStateYAML(paths, FileKindRepo, filepath.Join(dir, e.Name()), &repo); err != nil {
 			return nil, fmt.Errorf("parse repo metadata %s: %w", e.Name(), err)
 		}
 		if strings.TrimSpace(repo.RepoKey) == "" {
@@ -287,8 +316,11 @@ func LoadAllMachineFiles(paths Paths) ([]domain.MachineFile, error) {
 		if e.IsDir() || !strings.HasSuffix(e.Name(), ".yaml") {
 			continue
 		}
+		if _, isCopy := ConflictCopyOriginal(e.Name()); isCopy {
+			continue
+		}
 		var m domain.MachineFile
-		if err := LoadYAML(filepath.Join(dir, e.Name()), &m); err != nil {
+		if err := loadStateYAML(paths, FileKindMachine, filepath.Join(dir, e.Name()), &m); err != nil {
 			return nil, fmt.Errorf("parse machine file %s: %w", e.Name(), err)
 		}
 		if m.MachineID == "" {
@@ -303,13 +335,13 @@ func LoadNotifyCache(paths Paths) (domain.NotifyCacheFile, error) {
 	cachePath := paths.NotifyCachePath()
 	if _, err := os.Stat(cachePath); errors.Is(err, os.ErrNotExist) {
 		return domain.NotifyCacheFile{
-			Version:          1,
+			Version:          NotifyCacheSchemaVersion,
 			LastSent:         map[string]domain.NotifyCacheEntry{},
 			DeliveryFailures: map[string]domain.NotifyDeliveryFailure{},
 		}, nil
 	}
 	var cache domain.NotifyCacheFile
-	if err := LoadYAML(cachePath, &cache); err != nil {
+	if err := loadStateYAML(paths, FileKindNotifyCache, cachePath, &cache); err != nil {
 		return domain.NotifyCacheFile{}, fmt.Errorf("parse %s: %w", cachePath, err)
 	}
 	if cache.LastSent == nil {
@@ -318,21 +350,40 @@ func LoadNotifyCache(paths Paths) (domain.NotifyCacheFile, error) {
 	if cache.DeliveryFailures == nil {
 		cache.DeliveryFailures = map[string]domain.NotifyDeliveryFailure{}
 	}
-	if cache.Version == 0 {
-		cache.Version = 1
-	}
 	return cache, nil
 }
 
 func SaveNotifyCache(paths Paths, cache domain.NotifyCacheFile) error {
-	cache.Version = 1
+	if err := checkWritableVersion(FileKindNotifyCache, paths.NotifyCachePath()); err != nil {
+		return err
+	}
+	cache.Version = NotifyCacheSchemaVersion
 	if cache.LastSent == nil {
 		cache.LastSent = map[string]domain.NotifyCacheEntry{}
 	}
 	if cache.DeliveryFailures == nil {
 		cache.DeliveryFailures = map[string]domain.NotifyDeliveryFailure{}
 	}
-	return SaveYAML(paths.NotifyCachePath(), cache)
+	return saveStateYAML(paths, paths.NotifyCachePath(), cache)
+}
+
+// LoadScanCache returns an empty cache when the file is missing, unreadable,
+// or from another cache version; the cache only ever saves work.
+func LoadScanCache(paths Paths) domain.ScanCacheFile {
+	var cache domain.ScanCacheFile
+	if err := LoadYAML(paths.ScanCachePath(), &cache); err != nil || cache.Version != ScanCacheVersion {
+		cache = domain.ScanCacheFile{}
+	}
+	cache.Version = ScanCacheVersion
+	if cache.Repos == nil {
+		cache.Repos = map[string]domain.ScanCacheEntry{}
+	}
+	return cache
+}
+
+func SaveScanCache(paths Paths, cache domain.ScanCacheFile) error {
+	cache.Version = ScanCacheVersion
+	return SaveYAML(paths.ScanCachePath(), cache)
 }
 
 func LoadYAML(path string, out any) error {
@@ -347,14 +398,11 @@ func LoadYAML(path string, out any) error {
 }
 
 func SaveYAML(path string, in any) error {
-	if err := EnsureDir(filepath.Dir(path)); err != nil {
-		return err
-	}
 	b, err := yaml.Marshal(in)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, b, 0o644)
+	return writeFileBytes(path, b)
 }
 
 type Lock struct {